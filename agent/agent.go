@@ -41,6 +41,10 @@ type Config struct {
 
 	// Timeout is the maximum execution time (0 = no timeout)
 	Timeout time.Duration
+
+	// Options holds agent-specific options from the agent spec
+	// (e.g. the script path in "fake:script.jsonl")
+	Options string
 }
 
 // Result represents the outcome of an agent execution
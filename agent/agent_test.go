@@ -2,7 +2,11 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -189,3 +193,137 @@ func (m *mockAgent) Stderr() io.Reader                              { return nil
 func (m *mockAgent) Wait() (int, error)                             { return 0, nil }
 func (m *mockAgent) Cancel() error                                  { return nil }
 func (m *mockAgent) IsRunning() bool                                { return m.running }
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		spec        string
+		wantName    string
+		wantOptions string
+	}{
+		{"claude", "claude", ""},
+		{"fake:script.jsonl", "fake", "script.jsonl"},
+		{" fake:/tmp/a:b.jsonl ", "fake", "/tmp/a:b.jsonl"},
+	}
+
+	for _, tt := range tests {
+		name, options := ParseSpec(tt.spec)
+		if name != tt.wantName || options != tt.wantOptions {
+			t.Errorf("ParseSpec(%q) = (%q, %q), want (%q, %q)", tt.spec, name, options, tt.wantName, tt.wantOptions)
+		}
+	}
+}
+
+func writeFakeScript(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func collectRun(t *testing.T, runner *Runner) ([]OutputLine, Result) {
+	t.Helper()
+	var lines []OutputLine
+	for line := range runner.Output() {
+		lines = append(lines, line)
+	}
+	select {
+	case result := <-runner.Done():
+		return lines, result
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for fake agent")
+	}
+	return nil, Result{}
+}
+
+func TestFakeAgentReplaysScript(t *testing.T) {
+	path := writeFakeScript(t,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"hi"}]}}`,
+		`{"fake":{"stderr":"warning"}}`,
+		`{"type":"result","result":"done"}`,
+		`{"fake":{"exit":3}}`,
+		`{"type":"never"}`,
+	)
+
+	ag, err := CreateAgent("fake", Config{Options: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ag.Name() != "Fake" {
+		t.Errorf("expected name 'Fake', got %q", ag.Name())
+	}
+
+	runner := NewRunner(ag)
+	if err := runner.Run(context.Background(), "prompt"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	lines, result := collectRun(t, runner)
+
+	if result.ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %d", result.ExitCode)
+	}
+
+	var stdout, stderr []string
+	for _, l := range lines {
+		if l.IsStderr {
+			stderr = append(stderr, l.Text)
+		} else {
+			stdout = append(stdout, l.Text)
+		}
+	}
+	if len(stdout) != 2 {
+		t.Errorf("expected 2 stdout lines, got %v", stdout)
+	}
+	if len(stderr) != 1 || stderr[0] != "warning" {
+		t.Errorf("expected stderr [warning], got %v", stderr)
+	}
+}
+
+func TestFakeAgentCancel(t *testing.T) {
+	path := writeFakeScript(t,
+		`{"type":"assistant"}`,
+		`{"fake":{"sleep":"10s"}}`,
+		`{"type":"result"}`,
+	)
+
+	runner := NewRunner(NewFake(Config{Options: path}))
+	if err := runner.Run(context.Background(), "prompt"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	runner.Cancel()
+
+	_, result := collectRun(t, runner)
+	if result.ExitCode != -1 {
+		t.Errorf("expected exit code -1 after cancel, got %d", result.ExitCode)
+	}
+}
+
+func TestFakeAgentTimeout(t *testing.T) {
+	path := writeFakeScript(t, `{"fake":{"sleep":"10s"}}`)
+
+	runner := NewRunner(NewFake(Config{Options: path, Timeout: 20 * time.Millisecond}))
+	if err := runner.Run(context.Background(), "prompt"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	_, result := collectRun(t, runner)
+	if result.ExitCode != -1 {
+		t.Errorf("expected exit code -1 after timeout, got %d", result.ExitCode)
+	}
+	if !errors.Is(result.Error, ErrAgentTimeout) {
+		t.Errorf("expected ErrAgentTimeout, got %v", result.Error)
+	}
+}
+
+func TestFakeAgentInvalidScript(t *testing.T) {
+	if err := NewFake(Config{}).Start(context.Background(), ""); err == nil {
+		t.Error("expected error without a script path")
+	}
+
+	path := writeFakeScript(t, `{"fake":{"delay":"soon"}}`)
+	if err := NewFake(Config{Options: path}).Start(context.Background(), ""); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+}
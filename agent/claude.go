@@ -121,6 +121,9 @@ func (c *ClaudeCode) Wait() (int, error) {
 	c.running = false
 	c.mu.Unlock()
 
+	if c.ctx.Err() == context.DeadlineExceeded {
+		return -1, ErrAgentTimeout
+	}
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return exitErr.ExitCode(), nil
@@ -0,0 +1,262 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Fake implements the Agent interface by replaying a scripted stream-json
+// file instead of running a real CLI. It is intended for deterministic
+// end-to-end tests and demos.
+//
+// Each non-empty line of the script is either written verbatim to stdout,
+// or, if it is a JSON object with a top-level "fake" key, interpreted as a
+// directive:
+//
+//	{"fake": {"delay": "50ms"}}     delay before every following line
+//	{"fake": {"sleep": "2s"}}       pause once
+//	{"fake": {"stderr": "oops"}}    write a line to stderr
+//	{"fake": {"exit": 1}}           stop replaying and exit with this code
+//
+// When the script ends without an exit directive the agent exits with 0.
+type Fake struct {
+	config     Config
+	scriptPath string
+
+	stdout *io.PipeReader
+	stderr *io.PipeReader
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	running  bool
+	exitCode int
+	timedOut bool
+}
+
+// fakeDirective is the payload of a {"fake": {...}} script line.
+type fakeDirective struct {
+	Delay  string `json:"delay,omitempty"`
+	Sleep  string `json:"sleep,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	Exit   *int   `json:"exit,omitempty"`
+}
+
+// fakeStep is a parsed script line.
+type fakeStep struct {
+	stdout    string
+	directive *fakeDirective
+}
+
+// NewFake creates a fake agent. The script path is taken from
+// config.Options (e.g. "fake:script.jsonl" on the command line).
+func NewFake(config Config) *Fake {
+	return &Fake{
+		config:     config,
+		scriptPath: config.Options,
+	}
+}
+
+// Name returns the agent's display name
+func (f *Fake) Name() string {
+	return "Fake"
+}
+
+// Start begins replaying the script
+func (f *Fake) Start(ctx context.Context, prompt string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.running {
+		return ErrAgentAlreadyRunning
+	}
+
+	steps, err := loadFakeScript(f.scriptPath)
+	if err != nil {
+		return err
+	}
+
+	var runCtx context.Context
+	if f.config.Timeout > 0 {
+		runCtx, f.cancel = context.WithTimeout(ctx, f.config.Timeout)
+	} else {
+		runCtx, f.cancel = context.WithCancel(ctx)
+	}
+
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+	f.stdout = stdoutR
+	f.stderr = stderrR
+	f.done = make(chan struct{})
+	f.running = true
+
+	go f.replay(runCtx, steps, stdoutW, stderrW)
+
+	return nil
+}
+
+// replay writes the script to the pipes, honouring delays and cancellation.
+func (f *Fake) replay(ctx context.Context, steps []fakeStep, stdout, stderr *io.PipeWriter) {
+	exitCode := 0
+	var delay time.Duration
+
+	defer func() {
+		stdout.Close()
+		stderr.Close()
+
+		f.mu.Lock()
+		f.exitCode = exitCode
+		f.timedOut = ctx.Err() == context.DeadlineExceeded
+		f.running = false
+		f.mu.Unlock()
+		close(f.done)
+	}()
+
+	wait := func(d time.Duration) bool {
+		if d <= 0 {
+			return ctx.Err() == nil
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(d):
+			return true
+		}
+	}
+
+	for _, step := range steps {
+		if step.directive == nil {
+			if !wait(delay) {
+				exitCode = -1
+				return
+			}
+			fmt.Fprintln(stdout, step.stdout)
+			continue
+		}
+
+		d := step.directive
+		if d.Delay != "" {
+			delay, _ = time.ParseDuration(d.Delay)
+		}
+		if d.Sleep != "" {
+			sleep, _ := time.ParseDuration(d.Sleep)
+			if !wait(sleep) {
+				exitCode = -1
+				return
+			}
+		}
+		if d.Stderr != "" {
+			fmt.Fprintln(stderr, d.Stderr)
+		}
+		if d.Exit != nil {
+			exitCode = *d.Exit
+			return
+		}
+	}
+}
+
+// Stdout returns a reader for the agent's stdout
+func (f *Fake) Stdout() io.Reader {
+	return f.stdout
+}
+
+// Stderr returns a reader for the agent's stderr
+func (f *Fake) Stderr() io.Reader {
+	return f.stderr
+}
+
+// Wait blocks until the script finishes and returns the exit code, with
+// ErrAgentTimeout if the configured timeout cut it short
+func (f *Fake) Wait() (int, error) {
+	f.mu.Lock()
+	done := f.done
+	f.mu.Unlock()
+
+	if done == nil {
+		return -1, ErrAgentNotStarted
+	}
+	<-done
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.timedOut {
+		return f.exitCode, ErrAgentTimeout
+	}
+	return f.exitCode, nil
+}
+
+// Cancel stops the replay; Wait then reports exit code -1
+func (f *Fake) Cancel() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.running && f.cancel != nil {
+		f.cancel()
+	}
+	return nil
+}
+
+// IsRunning returns whether the script is still being replayed
+func (f *Fake) IsRunning() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.running
+}
+
+// loadFakeScript reads and parses a fake agent script.
+func loadFakeScript(path string) ([]fakeStep, error) {
+	if path == "" {
+		return nil, fmt.Errorf("fake agent requires a script (use fake:<path>)")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake agent script: %w", err)
+	}
+
+	var steps []fakeStep
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var wrapper struct {
+			Fake *fakeDirective `json:"fake"`
+		}
+		if json.Unmarshal(line, &wrapper) == nil && wrapper.Fake != nil {
+			if err := wrapper.Fake.validate(); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+			}
+			steps = append(steps, fakeStep{directive: wrapper.Fake})
+			continue
+		}
+		steps = append(steps, fakeStep{stdout: string(line)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fake agent script: %w", err)
+	}
+	return steps, nil
+}
+
+func (d *fakeDirective) validate() error {
+	for _, v := range []string{d.Delay, d.Sleep} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package agent
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -24,6 +26,9 @@ func NewRegistry() *Registry {
 	r.Register("claude", func(cfg Config) Agent {
 		return NewClaudeCode(cfg)
 	})
+	r.Register("fake", func(cfg Config) Agent {
+		return NewFake(cfg)
+	})
 
 	return r
 }
@@ -54,7 +59,7 @@ func (r *Registry) Create(name string, config Config) (Agent, error) {
 	return factory(config), nil
 }
 
-// Available returns the names of all registered agents
+// Available returns the names of all registered agents, sorted
 func (r *Registry) Available() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -63,6 +68,7 @@ func (r *Registry) Available() []string {
 	for name := range r.agents {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
 
@@ -74,6 +80,13 @@ func (r *Registry) Has(name string) bool {
 	return ok
 }
 
+// ParseSpec splits an agent spec of the form "name" or "name:options"
+// (e.g. "fake:script.jsonl") into the registry name and its options.
+func ParseSpec(spec string) (name, options string) {
+	name, options, _ = strings.Cut(strings.TrimSpace(spec), ":")
+	return name, options
+}
+
 // DefaultRegistry is the global agent registry
 var DefaultRegistry = NewRegistry()
 
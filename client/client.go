@@ -65,6 +65,39 @@ type Task struct {
 	Blocked            bool        `json:"blocked"`
 	AcceptanceCriteria []string    `json:"acceptance_criteria,omitempty"`
 	Guardrails         []Guardrail `json:"guardrails,omitempty"`
+	// Priority is the task urgency where 0 is the most urgent (P0). Nil when unset.
+	Priority *int `json:"priority,omitempty"`
+	// DueDate is an RFC 3339 timestamp or YYYY-MM-DD date. Empty when unset.
+	DueDate   string   `json:"due_date,omitempty"`
+	CreatedAt string   `json:"created_at,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Assignee  string   `json:"assignee,omitempty"`
+	// Estimate is the expected effort in arbitrary units (e.g. points). Nil when unset.
+	Estimate *int `json:"estimate,omitempty"`
+	// Comments are the task's comments, oldest first.
+	Comments []Comment `json:"comments,omitempty"`
+	// AgentName is who last moved the task, as sent with MoveTaskStatus.
+	AgentName string `json:"agent_name,omitempty"`
+}
+
+// Comment is a comment on a Flux task.
+type Comment struct {
+	ID   string `json:"id"`
+	Body string `json:"body"`
+	// Author is "user" for people, "mcp" for agents using the Flux MCP
+	// tools, or the name of the tool that posted it.
+	Author    string `json:"author,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// Due returns the parsed due date, or false if unset or unparseable.
+func (t Task) Due() (time.Time, bool) {
+	return parseTimestamp(t.DueDate)
+}
+
+// Created returns the parsed creation time, or false if unset or unparseable.
+func (t Task) Created() (time.Time, bool) {
+	return parseTimestamp(t.CreatedAt)
 }
 
 // EpicUpdate contains optional fields for updating an epic.
@@ -73,6 +106,7 @@ type EpicUpdate struct {
 	Notes     *string   `json:"notes,omitempty"`
 	Status    *string   `json:"status,omitempty"`
 	DependsOn *[]string `json:"depends_on,omitempty"`
+	Auto      *bool     `json:"auto,omitempty"`
 }
 
 // TaskUpdate contains optional fields for updating a task.
@@ -83,6 +117,9 @@ type TaskUpdate struct {
 	EpicID    *string   `json:"epic_id,omitempty"`
 	DependsOn *[]string `json:"depends_on,omitempty"`
 	AgentName *string   `json:"agent_name,omitempty"`
+	// ExpectedStatus makes the update conditional: servers that support it
+	// reject the update with 409 Conflict unless the task has this status.
+	ExpectedStatus *string `json:"expected_status,omitempty"`
 }
 
 // TaskFilters contains optional filters for listing tasks.
@@ -298,6 +335,16 @@ func (c *Client) CreateTask(projectID, title, notes, epicID string) (*Task, erro
 	return &task, nil
 }
 
+// GetTask returns the task with the given ID.
+func (c *Client) GetTask(taskID string) (*Task, error) {
+	var task Task
+	path := fmt.Sprintf("/api/tasks/%s", url.PathEscape(taskID))
+	if err := c.doRequest(http.MethodGet, path, nil, &task); err != nil {
+		return nil, fmt.Errorf("failed to get task %s: %w", taskID, err)
+	}
+	return &task, nil
+}
+
 // UpdateTask updates an existing task with the provided updates.
 func (c *Client) UpdateTask(taskID string, updates TaskUpdate) (*Task, error) {
 	var task Task
@@ -317,6 +364,22 @@ func (c *Client) DeleteTask(taskID string) error {
 	return nil
 }
 
+// AddTaskComment adds a comment to a task. An empty author leaves it to
+// the server.
+func (c *Client) AddTaskComment(taskID, body, author string) (*Comment, error) {
+	payload := map[string]string{"body": body}
+	if author != "" {
+		payload["author"] = author
+	}
+
+	var comment Comment
+	path := fmt.Sprintf("/api/tasks/%s/comments", url.PathEscape(taskID))
+	if err := c.doRequest(http.MethodPost, path, payload, &comment); err != nil {
+		return nil, fmt.Errorf("failed to comment on task %s: %w", taskID, err)
+	}
+	return &comment, nil
+}
+
 // MoveTaskStatus is a shortcut method to quickly change a task's status.
 // An optional agentName identifies who performed the transition.
 func (c *Client) MoveTaskStatus(taskID, status string, agentName ...string) (*Task, error) {
@@ -336,6 +399,30 @@ func StringPtr(s string) *string {
 	return &s
 }
 
+// IntPtr returns a pointer to the given int. Useful for optional priority fields.
+func IntPtr(i int) *int {
+	return &i
+}
+
+// parseTimestamp parses an RFC 3339 timestamp or a YYYY-MM-DD date.
+func parseTimestamp(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// BoolPtr returns a pointer to the given bool. Useful for optional fields in updates.
+func BoolPtr(b bool) *bool {
+	return &b
+}
+
 // StringSlicePtr returns a pointer to the given string slice. Useful for optional depends_on fields.
 func StringSlicePtr(s []string) *[]string {
 	return &s
@@ -450,6 +450,32 @@ func TestCreateTask(t *testing.T) {
 	}
 }
 
+func TestGetTask(t *testing.T) {
+	expectedTask := Task{ID: "task-1", Title: "Task", Status: "review", ProjectID: "proj-1"}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("expected GET method, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/tasks/task-1" {
+			t.Errorf("expected path /api/tasks/task-1, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(expectedTask)
+	})
+
+	server, client := setupTestServer(handler)
+	defer server.Close()
+
+	task, err := client.GetTask("task-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.ID != "task-1" || task.Status != "review" {
+		t.Errorf("GetTask() = %+v, want task-1 in review", task)
+	}
+}
+
 func TestUpdateTask(t *testing.T) {
 	expectedTask := Task{ID: "task-1", Title: "Updated Task", Status: "done", ProjectID: "proj-1"}
 
@@ -519,6 +545,40 @@ func TestDeleteTask(t *testing.T) {
 	}
 }
 
+func TestAddTaskComment(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST method, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/tasks/task-1/comments" {
+			t.Errorf("expected path /api/tasks/task-1/comments, got %s", r.URL.Path)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		if body["body"] != "Looks good" || body["author"] != "momentum" {
+			t.Errorf("unexpected body %v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(Comment{ID: "comment-1", Body: body["body"], Author: body["author"]})
+	})
+
+	server, client := setupTestServer(handler)
+	defer server.Close()
+
+	comment, err := client.AddTaskComment("task-1", "Looks good", "momentum")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if comment.ID != "comment-1" || comment.Author != "momentum" {
+		t.Errorf("AddTaskComment() = %+v", comment)
+	}
+}
+
 func TestMoveTaskStatus(t *testing.T) {
 	expectedTask := Task{ID: "task-1", Title: "Task 1", Status: "done", ProjectID: "proj-1"}
 
@@ -752,3 +812,26 @@ func TestUpdateEpicWithDependsOn(t *testing.T) {
 		t.Errorf("expected 1 dependency, got %d", len(epic.DependsOn))
 	}
 }
+
+func TestUpdateEpicAuto(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		// false must be sent, not dropped as empty
+		if auto, ok := body["auto"]; !ok || auto != false {
+			t.Errorf("expected auto false, got %v (present %v)", auto, ok)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Epic{ID: "epic-1", Status: "done"})
+	})
+
+	server, client := setupTestServer(handler)
+	defer server.Close()
+
+	if _, err := client.UpdateEpic("epic-1", EpicUpdate{Status: StringPtr("done"), Auto: BoolPtr(false)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/git"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+// gitCommitTimeout bounds committing and pushing a task's changes.
+const gitCommitTimeout = 2 * time.Minute
+
+// gitMu serialises commits, which agents finishing together in one workdir
+// would otherwise race for the index.
+var gitMu sync.Mutex
+
+// commitMessage builds the commit message for a task's changes.
+func commitMessage(task *client.Task) string {
+	return fmt.Sprintf("[%s] %s", task.ID, task.Title)
+}
+
+// gitDirty reports whether dir has uncommitted changes. A workdir that is
+// not a git repository counts as clean; committing there fails anyway.
+func gitDirty(ctx context.Context, dir string) bool {
+	repo, err := git.Open(ctx, dir)
+	if err != nil {
+		return false
+	}
+	status, err := repo.Status(ctx)
+	return err == nil && status != ""
+}
+
+// commitSkipReason says why a run's changes must not be committed, or ""
+// when they can be. A commit takes every change in the workdir, so it would
+// sweep up other agents' work or edits made before the agent started.
+func commitSkipReason(shared, dirty bool) string {
+	switch {
+	case shared:
+		return "other agents were running in the workdir"
+	case dirty:
+		return "the workdir had uncommitted changes when the agent started"
+	}
+	return ""
+}
+
+// commitTask commits everything the agent left in dir on the branch cfg
+// asks for, and pushes it when a remote is configured. It returns nil when
+// there was nothing to commit. A failed push returns the commit with the
+// error.
+func commitTask(ctx context.Context, dir string, task *client.Task, cfg config.GitConfig) (commit *workflow.Commit, err error) {
+	gitMu.Lock()
+	defer gitMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gitCommitTimeout)
+	defer cancel()
+
+	repo, err := git.Open(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if status, err := repo.Status(ctx); err != nil || status == "" {
+		return nil, err
+	}
+
+	branch, err := repo.CurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Branch == "task" {
+		// Go back to where the agent started once the work is committed,
+		// so the next task does not build on this one
+		original := branch
+		if original == "" {
+			if original, err = repo.Head(ctx); err != nil {
+				return nil, err
+			}
+		}
+		branch = cfg.BranchPrefix + task.ID
+		if err := repo.CreateBranch(ctx, branch); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if checkoutErr := repo.Checkout(ctx, original); checkoutErr != nil {
+				err = errors.Join(err, checkoutErr)
+			}
+		}()
+	}
+
+	sha, err := repo.CommitAll(ctx, commitMessage(task))
+	if err != nil || sha == "" {
+		return nil, err
+	}
+	commit = &workflow.Commit{SHA: sha, Branch: branch}
+
+	if cfg.Remote == "" {
+		return commit, nil
+	}
+	if branch == "" {
+		return commit, errors.New("cannot push a detached HEAD")
+	}
+	// Task branches belong to momentum; a re-run replaces the last attempt
+	if err := repo.Push(ctx, cfg.Remote, branch, cfg.Branch == "task"); err != nil {
+		return commit, err
+	}
+	commit.Remote = cfg.Remote
+	return commit, nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/fluxtest"
+	"github.com/stephenmfriend/momentum/gittest"
+	"github.com/stephenmfriend/momentum/ui"
+)
+
+func TestCommitTask(t *testing.T) {
+	task := &client.Task{ID: "task-1", Title: "Add feature"}
+	tests := []struct {
+		name       string
+		cfg        config.GitConfig
+		change     bool
+		wantBranch string
+		wantPushed bool
+	}{
+		{name: "nothing to commit", cfg: config.GitConfig{Commit: true, Branch: "current"}},
+		{name: "current branch", cfg: config.GitConfig{Commit: true, Branch: "current"}, change: true, wantBranch: "main"},
+		{
+			name:       "task branch pushed",
+			cfg:        config.GitConfig{Commit: true, Branch: "task", BranchPrefix: "momentum/", Remote: "origin"},
+			change:     true,
+			wantBranch: "momentum/task-1",
+			wantPushed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			gittest.Init(t, dir)
+			remote := gittest.BareRemote(t, dir)
+			if tt.change {
+				if err := os.WriteFile(filepath.Join(dir, "feature.go"), []byte("package feature\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			commit, err := commitTask(context.Background(), dir, task, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantBranch == "" {
+				if commit != nil {
+					t.Errorf("expected no commit, got %+v", commit)
+				}
+				return
+			}
+			if commit == nil || commit.Branch != tt.wantBranch {
+				t.Fatalf("commit = %+v, want one on %s", commit, tt.wantBranch)
+			}
+			if got := gittest.Run(t, dir, "rev-parse", tt.wantBranch); got != commit.SHA {
+				t.Errorf("%s at %s, want %s", tt.wantBranch, got, commit.SHA)
+			}
+			if got := gittest.Run(t, dir, "log", "-1", "--format=%s", commit.SHA); got != "[task-1] Add feature" {
+				t.Errorf("commit message = %q", got)
+			}
+			if got := gittest.Run(t, dir, "symbolic-ref", "--short", "HEAD"); got != "main" {
+				t.Errorf("checked out %q after committing, want main", got)
+			}
+			if tt.wantPushed {
+				if commit.Remote != "origin" {
+					t.Errorf("commit.Remote = %q, want origin", commit.Remote)
+				}
+				if got := gittest.Run(t, remote, "rev-parse", tt.wantBranch); got != commit.SHA {
+					t.Errorf("remote %s at %s, want %s", tt.wantBranch, got, commit.SHA)
+				}
+			}
+		})
+	}
+}
+
+func TestCommitSkipReason(t *testing.T) {
+	if reason := commitSkipReason(false, false); reason != "" {
+		t.Errorf("clean lone run: reason = %q, want none", reason)
+	}
+	if reason := commitSkipReason(true, false); !strings.Contains(reason, "other agents") {
+		t.Errorf("shared run: reason = %q", reason)
+	}
+	if reason := commitSkipReason(false, true); !strings.Contains(reason, "uncommitted changes") {
+		t.Errorf("dirty run: reason = %q", reason)
+	}
+}
+
+func TestRunWorker_CommitsTask(t *testing.T) {
+	flux := fluxtest.New()
+	script := writeScript(t,
+		`{"fake":{"sleep":"300ms"}}`,
+		`{"type":"result","result":"Added the feature"}`,
+	)
+	repoCfg := config.RepoConfig{
+		Git: config.GitConfig{Commit: true, Branch: "task", BranchPrefix: "momentum/", Remote: "origin"},
+	}
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeSync, "fake:"+script, repoCfg)
+
+	gittest.Init(t, workDir)
+	remote := gittest.BareRemote(t, workDir)
+	tasks := seedAutoTasks(flux, 1)
+
+	// The agent leaves a new file behind
+	h.waitForAgent(t, tasks[0].ID, true)
+	if err := os.WriteFile(filepath.Join(workDir, "feature.go"), []byte("package feature\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h.waitForStatus(t, tasks[0].ID, "done")
+	branch := "momentum/" + tasks[0].ID
+	sha := gittest.Run(t, remote, "rev-parse", branch)
+	task, _ := flux.Task(tasks[0].ID)
+	if want := "committed " + sha + " on " + branch + ", pushed to origin"; !strings.Contains(task.Notes, want) {
+		t.Errorf("notes missing %q:\n%s", want, task.Notes)
+	}
+	if len(task.Comments) != 1 || !strings.Contains(task.Comments[0].Body, "Committed "+sha) {
+		t.Errorf("expected the commit in the outcome comment, got %+v", task.Comments)
+	}
+}
+
+func TestRunWorker_SkipsCommitOfDirtyWorkdir(t *testing.T) {
+	flux := fluxtest.New()
+	script := writeScript(t, `{"type":"result","result":"Added the feature"}`)
+	repoCfg := config.RepoConfig{
+		Git: config.GitConfig{Commit: true, Branch: "current"},
+	}
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeSync, "fake:"+script, repoCfg)
+
+	// Someone's edits were in the workdir before the agent started
+	gittest.Init(t, workDir)
+	if err := os.WriteFile(filepath.Join(workDir, "README.md"), []byte("edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	head := gittest.Run(t, workDir, "rev-parse", "HEAD")
+	tasks := seedAutoTasks(flux, 1)
+
+	h.waitForStatus(t, tasks[0].ID, "done")
+	h.waitForNotes(t, tasks[0].ID, "not committed")
+	if got := gittest.Run(t, workDir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD moved to %s, want no commit", got)
+	}
+	task, _ := flux.Task(tasks[0].ID)
+	if len(task.Comments) != 1 || !strings.Contains(task.Comments[0].Body, "Not committed: the workdir had uncommitted changes") {
+		t.Errorf("expected the reason in the outcome comment, got %+v", task.Comments)
+	}
+}
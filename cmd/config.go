@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stephenmfriend/momentum/config"
+)
+
+// configKeyAnnotation marks flags that override a config setting; its
+// value is the setting's dotted key.
+const configKeyAnnotation = "momentum_config_key"
+
+// activeFlags are the flags of the command being run. They are nil when
+// commands are driven directly, as in tests.
+var activeFlags *pflag.FlagSet
+
+var configResolved bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect momentum's configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration",
+	Long: `Show the configuration momentum would run with in the workdir.
+
+Settings are resolved in layers, each overriding the ones before it:
+
+  1. built-in defaults
+  2. ~/.config/momentum/config.yaml (under $XDG_CONFIG_HOME if set)
+  3. .momentum.yaml in the workdir
+  4. MOMENTUM_* environment variables, e.g. MOMENTUM_BASE_URL or
+     MOMENTUM_REVIEW_ENABLED (lists are comma-separated)
+  5. command-line flags
+
+With --resolved every setting is listed with the layer it came from.
+
+Examples:
+  momentum config show
+  MOMENTUM_TIMEOUT=30m momentum config show --resolved --fairness weighted`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigShow(os.Stdout, configResolved)
+	},
+}
+
+func init() {
+	configShowCmd.Flags().BoolVar(&configResolved, "resolved", false, "List every setting with where its value came from")
+	addSelectionFlags(configShowCmd)
+	addAgentFlag(configShowCmd)
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// loadConfig resolves the configuration for the workdir from every layer,
+// with the command's flags on top. When run from the command line it also
+// applies base_url, execution_mode and agent to the globals their flags
+// set.
+func loadConfig() (config.Resolved, error) {
+	resolved, err := config.Resolve(GetWorkDir(), flagLayer(activeFlags))
+	if err != nil {
+		return config.Resolved{}, fmt.Errorf("loading config: %w", err)
+	}
+	if activeFlags != nil {
+		baseURL = resolved.Config.BaseURL
+		executionMode = resolved.Config.ExecutionMode
+		agentSpec = resolved.Config.Agent
+	}
+	return resolved, nil
+}
+
+// flagLayer returns the settings set by the flags given on the command
+// line, or nil without flags.
+func flagLayer(flags *pflag.FlagSet) *config.Layer {
+	if flags == nil {
+		return nil
+	}
+	layer := config.NewLayer("flags")
+	var watch []any
+	var watchSources []string
+	flags.Visit(func(f *pflag.Flag) {
+		keys := f.Annotations[configKeyAnnotation]
+		if len(keys) == 0 {
+			return
+		}
+		var value any = f.Value.String()
+		if list, ok := f.Value.(pflag.SliceValue); ok {
+			var items []any
+			for _, item := range list.GetSlice() {
+				items = append(items, item)
+			}
+			value = items
+		}
+		// --project, --epic and --task together replace the watch list
+		if keys[0] == "watch" {
+			for _, id := range value.([]any) {
+				watch = append(watch, map[string]any{f.Name: id})
+			}
+			watchSources = append(watchSources, "--"+f.Name)
+			return
+		}
+		layer.Set(keys[0], value, "flag --"+f.Name)
+	})
+	if len(watch) > 0 {
+		layer.Set("watch", watch, "flag "+strings.Join(watchSources, " "))
+	}
+	return layer
+}
+
+// workDirSource says where the workdir setting came from.
+func workDirSource() string {
+	if activeFlags != nil && activeFlags.Changed("workdir") {
+		return "flag --workdir"
+	}
+	if os.Getenv("MOMENTUM_WORKDIR") != "" {
+		return "env MOMENTUM_WORKDIR"
+	}
+	return config.SourceDefault
+}
+
+func runConfigShow(w io.Writer, withSources bool) error {
+	InitWorkDir()
+	resolved, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if !withSources {
+		data, err := yaml.Marshal(resolved.Config)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	settings, err := resolved.Settings()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	fmt.Fprintf(tw, "workdir\t%s\t%s\n", GetWorkDir(), workDirSource())
+	for _, s := range settings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestFlagLayer(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	for name, key := range map[string]string{"fairness": "selection.fairness", "agent": "agent"} {
+		flags.String(name, "", "")
+		flags.SetAnnotation(name, configKeyAnnotation, []string{key})
+	}
+	for name, key := range map[string]string{"label": "selection.labels", "project": "watch", "epic": "watch"} {
+		flags.StringArray(name, nil, "")
+		flags.SetAnnotation(name, configKeyAnnotation, []string{key})
+	}
+	if err := flags.Parse([]string{"--fairness", "weighted", "--label", "a", "--label", "b", "--project", "p1", "--epic", "e1"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".momentum.yaml"), []byte("selection:\n  fairness: round-robin\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldWorkDir := workDir
+	workDir = dir
+	t.Cleanup(func() { workDir = oldWorkDir })
+	activeFlags = flags
+	t.Cleanup(func() { activeFlags = nil })
+
+	var out bytes.Buffer
+	if err := runConfigShow(&out, true); err != nil {
+		t.Fatal(err)
+	}
+	rows := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 3 {
+			rows[fields[0]] = strings.Join(fields[1:], " ")
+		}
+	}
+	want := map[string]string{
+		"selection.fairness": "weighted flag --fairness",
+		"selection.labels":   `["a","b"] flag --label`,
+		"watch":              `[{"epic":"e1"},{"project":"p1"}] flag --epic --project`,
+		"selection.strategy": `"" default`,
+		"workdir":            dir + " default",
+	}
+	for key, row := range want {
+		if rows[key] != row {
+			t.Errorf("%s row = %q, want %q", key, rows[key], row)
+		}
+	}
+}
+
+func TestRunConfigShow(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("MOMENTUM_TIMEOUT", "30m")
+	oldWorkDir := workDir
+	workDir = t.TempDir()
+	t.Cleanup(func() { workDir = oldWorkDir })
+
+	var out bytes.Buffer
+	if err := runConfigShow(&out, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"base_url: http://localhost:3000", "timeout: 30m0s", "mode: orchestrator"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stephenmfriend/momentum/fluxtest"
+)
+
+var (
+	devServerAddr   string
+	devServerNoSeed bool
+)
+
+var devServerCmd = &cobra.Command{
+	Use:   "dev-server",
+	Short: "Run an in-memory fake Flux server",
+	Long: `Run an in-memory Flux server for development and demos.
+
+The server implements the Flux REST API and /api/events SSE stream with
+no persistence. By default it is seeded with a small demo project so that
+momentum can be tried end to end without a Flux install.
+
+Examples:
+  # Start the fake server, then point momentum at it
+  momentum dev-server --addr localhost:3001
+  momentum --base-url http://localhost:3001`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDevServer(devServerAddr, !devServerNoSeed)
+	},
+}
+
+func init() {
+	devServerCmd.Flags().StringVar(&devServerAddr, "addr", "localhost:3000", "Address to listen on")
+	devServerCmd.Flags().BoolVar(&devServerNoSeed, "no-seed", false, "Start with an empty board")
+	rootCmd.AddCommand(devServerCmd)
+}
+
+func runDevServer(addr string, seed bool) error {
+	flux := fluxtest.New()
+	if seed {
+		flux.Seed()
+	}
+
+	srv := &http.Server{Addr: addr, Handler: flux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	fmt.Printf("Fake Flux server listening on http://%s (ctrl+c to stop)\n", addr)
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("dev server: %w", err)
+	case <-ctx.Done():
+	}
+
+	flux.Close()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("dev server shutdown: %w", err)
+	}
+	return nil
+}
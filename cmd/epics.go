@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/sse"
+	"github.com/stephenmfriend/momentum/ui"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+// watchEpicCompletion completes an auto epic when one of its tasks moves
+// to the done status and it was the last one left, then runs the epic hook.
+// Tasks are seen finishing whoever moved them, so in review mode the epic
+// completes as the last task is approved. When events were missed, every
+// open auto epic is checked instead.
+func watchEpicCompletion(ctx context.Context, p *tea.Program, events <-chan sse.TypedEvent, c *client.Client, wf *workflow.Workflow, cfg config.EpicsConfig) {
+	done := wf.Statuses().Done
+	complete := func(projectID, epicID string) {
+		epic, err := wf.CompleteEpic(projectID, epicID, cfg.DisableAuto)
+		if err != nil {
+			p.Send(ui.ListenerErrorMsg{Err: err})
+			return
+		}
+		if epic == nil {
+			return
+		}
+		if cfg.Hook == "" {
+			p.Send(ui.NoticeMsg{Text: fmt.Sprintf("Epic %s (%s) completed", epic.ID, epic.Title)})
+			return
+		}
+		// Hooks such as integration test runs can take a while
+		go func() {
+			out, err := runEpicHook(ctx, GetWorkDir(), cfg.Hook, epic)
+			if err != nil {
+				p.Send(ui.ListenerErrorMsg{Err: fmt.Errorf("epic %s hook `%s` failed: %w\n%s", epic.ID, cfg.Hook, err, out)})
+				return
+			}
+			p.Send(ui.NoticeMsg{Text: fmt.Sprintf("Epic %s (%s) completed; hook `%s` passed", epic.ID, epic.Title, cfg.Hook)})
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Lagged() {
+				if err := completeOpenEpics(c, done, complete); err != nil {
+					p.Send(ui.ListenerErrorMsg{Err: err})
+				}
+				continue
+			}
+			if event.Task == nil {
+				continue
+			}
+			task := event.Task.Task
+			if task.Status != done || task.EpicID == "" {
+				continue
+			}
+			complete(event.ProjectID(), task.EpicID)
+		}
+	}
+}
+
+// completeOpenEpics calls complete for every auto epic not yet in the done
+// status, across all projects.
+func completeOpenEpics(c *client.Client, done string, complete func(projectID, epicID string)) error {
+	projects, err := c.ListProjects()
+	if err != nil {
+		return err
+	}
+	for _, project := range projects {
+		epics, err := c.ListEpics(project.ID)
+		if err != nil {
+			return err
+		}
+		for _, epic := range epics {
+			if epic.Auto && epic.Status != done {
+				complete(project.ID, epic.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// runEpicHook runs the epic hook for a completed epic in dir and returns
+// the end of its output.
+func runEpicHook(ctx context.Context, dir, command string, epic *client.Epic) (string, error) {
+	out, err := runShell(ctx, dir, command,
+		"MOMENTUM_EPIC_ID="+epic.ID,
+		"MOMENTUM_EPIC_TITLE="+epic.Title,
+		"MOMENTUM_PROJECT_ID="+epic.ProjectID,
+	)
+	return lastBytes(strings.TrimSpace(out), maxSummaryOutput), err
+}
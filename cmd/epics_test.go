@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/fluxtest"
+	"github.com/stephenmfriend/momentum/ui"
+)
+
+func TestRunWorker_CompletesEpic(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 2)
+	script := writeScript(t, `{"type":"result","result":"ok"}`)
+	repoCfg := config.RepoConfig{
+		Epics: config.EpicsConfig{
+			Complete:    true,
+			DisableAuto: true,
+			Hook:        `echo "$MOMENTUM_EPIC_ID $MOMENTUM_EPIC_TITLE" > epic-hook.txt`,
+		},
+	}
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, repoCfg)
+
+	for _, task := range tasks {
+		h.waitForStatus(t, task.ID, "done")
+	}
+
+	epicID := tasks[0].EpicID
+	hookOutput := filepath.Join(workDir, "epic-hook.txt")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		epic, _ := flux.Epic(epicID)
+		data, _ := os.ReadFile(hookOutput)
+		if epic.Status == "done" && strings.TrimSpace(string(data)) == epicID+" Epic" {
+			if epic.Auto {
+				t.Error("expected auto to be turned off")
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("epic = %+v, hook wrote %q; want done with the hook run", epic, data)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRunWorker_CompletesEpicOnApproval(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 2)
+	script := writeScript(t, `{"type":"result","result":"ok"}`)
+	repoCfg := config.RepoConfig{
+		Statuses: config.StatusConfig{Succeeded: "review"},
+		Review:   config.ReviewConfig{Enabled: true, Status: "review"},
+		Epics:    config.EpicsConfig{Complete: true, Hook: `echo ran >> epic-hook.txt`},
+	}
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, repoCfg)
+
+	for _, task := range tasks {
+		h.waitForStatus(t, task.ID, "review")
+	}
+	epicID := tasks[0].EpicID
+	hookOutput := filepath.Join(workDir, "epic-hook.txt")
+
+	// Approving all but the last task leaves the epic open
+	c := client.NewClient(baseURL)
+	if _, err := c.MoveTaskStatus(tasks[0].ID, "done"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if epic, _ := flux.Epic(epicID); epic.Status == "done" {
+		t.Fatal("expected the epic to stay open while a task waits for review")
+	}
+	if _, err := os.Stat(hookOutput); err == nil {
+		t.Fatal("expected the hook not to run before every task is approved")
+	}
+
+	if _, err := c.MoveTaskStatus(tasks[1].ID, "done"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		epic, _ := flux.Epic(epicID)
+		data, _ := os.ReadFile(hookOutput)
+		if epic.Status == "done" && strings.TrimSpace(string(data)) == "ran" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("epic = %+v, hook wrote %q; want done with the hook run once", epic, data)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
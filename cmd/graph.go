@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/selection"
+)
+
+var (
+	graphProjectID string
+	graphFormat    string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print a project's task and epic dependency graph",
+	Long: `Print the dependency graph of a project's tasks and epics.
+
+Each todo task is marked ready, or lists the tasks and epics it is still
+waiting on. Dependency cycles are reported at the end; tasks in a cycle are
+never picked up.
+
+Examples:
+  # Text outline grouped by epic
+  momentum graph --project myproject
+
+  # Render with Graphviz
+  momentum graph --project myproject --format dot | dot -Tsvg > graph.svg`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGraph(os.Stdout, graphProjectID, graphFormat)
+	},
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphProjectID, "project", "", "Project ID to graph (required)")
+	graphCmd.Flags().StringVar(&graphFormat, "format", "text", "Output format: text or dot")
+	graphCmd.MarkFlagRequired("project")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(w io.Writer, projectID, format string) error {
+	if format != "text" && format != "dot" {
+		return fmt.Errorf("invalid format %q (use text or dot)", format)
+	}
+
+	// For base_url and statuses
+	InitWorkDir()
+	resolved, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	c := client.NewClient(GetBaseURL())
+	tasks, err := c.ListTasks(projectID, client.TaskFilters{})
+	if err != nil {
+		return fmt.Errorf("listing tasks: %w", err)
+	}
+	epics, err := c.ListEpics(projectID)
+	if err != nil {
+		return fmt.Errorf("listing epics: %w", err)
+	}
+
+	graph := selection.NewGraph(tasks, epics)
+	graph.SetStatuses(selectionStatuses(resolved.Config))
+	if format == "dot" {
+		return graph.WriteDOT(w, projectID)
+	}
+	return graph.WriteText(w)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/fluxtest"
+)
+
+func TestRunGraph(t *testing.T) {
+	flux := fluxtest.New()
+	project := flux.AddProject("Graph", "")
+	epic := flux.AddEpic(project.ID, "Epic", true)
+	first := flux.AddTask(client.Task{ProjectID: project.ID, EpicID: epic.ID, Title: "First"})
+	flux.AddTask(client.Task{ProjectID: project.ID, EpicID: epic.ID, Title: "Second", DependsOn: []string{first.ID}})
+	server := httptest.NewServer(flux)
+	defer server.Close()
+	defer flux.Close()
+
+	oldBaseURL := baseURL
+	baseURL = server.URL
+	defer func() { baseURL = oldBaseURL }()
+
+	projectID := project.ID
+
+	var text strings.Builder
+	if err := runGraph(&text, projectID, "text"); err != nil {
+		t.Fatalf("text: %v", err)
+	}
+	if !strings.Contains(text.String(), "· ready") || !strings.Contains(text.String(), "· waiting on") {
+		t.Errorf("expected ready and waiting tasks in output:\n%s", text.String())
+	}
+
+	var dot strings.Builder
+	if err := runGraph(&dot, projectID, "dot"); err != nil {
+		t.Fatalf("dot: %v", err)
+	}
+	if !strings.HasPrefix(dot.String(), "digraph ") || !strings.Contains(dot.String(), "->") {
+		t.Errorf("unexpected DOT output:\n%s", dot.String())
+	}
+
+	if err := runGraph(&dot, projectID, "svg"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
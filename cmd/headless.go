@@ -2,11 +2,17 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"sync"
@@ -22,28 +28,46 @@ import (
 	"github.com/stephenmfriend/momentum/workflow"
 )
 
-// sseEventData represents the structure of SSE event payloads
-type sseEventData struct {
-	Epic *struct {
-		Auto bool `json:"auto"`
-	} `json:"epic,omitempty"`
-}
-
 // runningAgents tracks which tasks have active agents
 type runningAgents struct {
 	mu            sync.Mutex
 	tasks         map[string]bool
 	runners       map[string]*agent.Runner
 	stoppedByUser map[string]bool
-	doneCh        chan string
+	// prompts holds the prompt each running agent was started with
+	prompts map[string]string
+	// cancelledUpstream marks agents stopped because their task was
+	// deleted or moved away in Flux
+	cancelledUpstream map[string]bool
+	// restarts holds the updated task for agents being restarted
+	restarts map[string]*client.Task
+	// shared marks agents that ran alongside another agent, whose workdir
+	// changes cannot be told apart
+	shared map[string]bool
+	// settling holds tasks whose agent finished but whose changes are not
+	// committed yet; the workdir stays theirs until then
+	settling map[string]bool
+	// dirty marks agents that started in a workdir with uncommitted
+	// changes, which must not be committed as theirs
+	dirty map[string]bool
+	// owned records held tasks on disk for crash recovery; nil records
+	// nothing
+	owned  *ownedTasks
+	doneCh chan string
 }
 
 func newRunningAgents() *runningAgents {
 	return &runningAgents{
-		tasks:         make(map[string]bool),
-		runners:       make(map[string]*agent.Runner),
-		stoppedByUser: make(map[string]bool),
-		doneCh:        make(chan string, 100),
+		tasks:             make(map[string]bool),
+		runners:           make(map[string]*agent.Runner),
+		stoppedByUser:     make(map[string]bool),
+		prompts:           make(map[string]string),
+		cancelledUpstream: make(map[string]bool),
+		restarts:          make(map[string]*client.Task),
+		shared:            make(map[string]bool),
+		settling:          make(map[string]bool),
+		dirty:             make(map[string]bool),
+		doneCh:            make(chan string, 100),
 	}
 }
 
@@ -58,6 +82,19 @@ func (r *runningAgents) markRunning(taskID string, runner *agent.Runner) {
 	defer r.mu.Unlock()
 	r.tasks[taskID] = true
 	r.runners[taskID] = runner
+	// A restart keeps what the earlier run shared the workdir with
+	for id := range r.tasks {
+		if id != taskID {
+			r.shared[id] = true
+			r.shared[taskID] = true
+		}
+	}
+	for id := range r.settling {
+		if id != taskID {
+			r.shared[id] = true
+			r.shared[taskID] = true
+		}
+	}
 }
 
 func (r *runningAgents) markDone(taskID string) {
@@ -66,12 +103,55 @@ func (r *runningAgents) markDone(taskID string) {
 	delete(r.tasks, taskID)
 	delete(r.runners, taskID)
 	delete(r.stoppedByUser, taskID)
+	delete(r.prompts, taskID)
+	delete(r.cancelledUpstream, taskID)
+	delete(r.restarts, taskID)
+	if !r.settling[taskID] {
+		delete(r.shared, taskID)
+		delete(r.dirty, taskID)
+	}
 	select {
 	case r.doneCh <- taskID:
 	default:
 	}
 }
 
+// ranAlongside reports whether another agent ran in the workdir while the
+// task's agent did.
+func (r *runningAgents) ranAlongside(taskID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.shared[taskID]
+}
+
+// markSettling keeps the workdir to a finished agent until markSettled, so
+// its changes can be committed before another agent starts.
+func (r *runningAgents) markSettling(taskID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settling[taskID] = true
+}
+
+func (r *runningAgents) markSettled(taskID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.settling, taskID)
+	delete(r.shared, taskID)
+	delete(r.dirty, taskID)
+}
+
+func (r *runningAgents) setDirty(taskID string, dirty bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dirty[taskID] = dirty
+}
+
+func (r *runningAgents) startedDirty(taskID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dirty[taskID]
+}
+
 func (r *runningAgents) markStoppedByUser(taskID string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -84,6 +164,72 @@ func (r *runningAgents) wasStoppedByUser(taskID string) bool {
 	return r.stoppedByUser[taskID]
 }
 
+func (r *runningAgents) setPrompt(taskID, prompt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts[taskID] = prompt
+}
+
+// promptChanged records prompt as the task's current prompt and reports
+// whether it differs from the previous one.
+func (r *runningAgents) promptChanged(taskID, prompt string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.prompts[taskID] == prompt {
+		return false
+	}
+	r.prompts[taskID] = prompt
+	return true
+}
+
+// cancelUpstream stops the agent for a task that was deleted or moved away
+// in Flux. It reports false if no agent is running or it was already
+// cancelled.
+func (r *runningAgents) cancelUpstream(taskID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.tasks[taskID] || r.cancelledUpstream[taskID] {
+		return false
+	}
+	r.cancelledUpstream[taskID] = true
+	delete(r.restarts, taskID)
+	if runner := r.runners[taskID]; runner != nil {
+		runner.Cancel()
+	}
+	return true
+}
+
+func (r *runningAgents) wasCancelledUpstream(taskID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cancelledUpstream[taskID]
+}
+
+// restart stops the agent for a task so it can be started again with the
+// updated task. It reports false if no agent is running.
+func (r *runningAgents) restart(task *client.Task) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.tasks[task.ID] || r.cancelledUpstream[task.ID] {
+		return false
+	}
+	_, pending := r.restarts[task.ID]
+	r.restarts[task.ID] = task
+	if runner := r.runners[task.ID]; runner != nil && !pending {
+		runner.Cancel()
+	}
+	return true
+}
+
+// takeRestart returns and clears the updated task for a pending restart.
+func (r *runningAgents) takeRestart(taskID string) *client.Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task := r.restarts[taskID]
+	delete(r.restarts, taskID)
+	return task
+}
+
 func (r *runningAgents) cancelAll() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -94,30 +240,30 @@ func (r *runningAgents) cancelAll() {
 	}
 }
 
+// runningIDs returns the IDs of tasks with a running agent.
+func (r *runningAgents) runningIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Collect(maps.Keys(r.tasks))
+}
+
+// hasRunning reports whether an agent is running or its changes are still
+// being settled.
 func (r *runningAgents) hasRunning() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	return len(r.tasks) > 0
+	return len(r.tasks) > 0 || len(r.settling) > 0
 }
 
 func (r *runningAgents) done() <-chan string {
 	return r.doneCh
 }
 
-// isAutoEpicEvent checks if the SSE event contains an epic with auto=true
-func isAutoEpicEvent(event sse.Event) bool {
-	var data sseEventData
-	if err := json.Unmarshal([]byte(event.Data), &data); err != nil {
-		return false
-	}
-	return data.Epic != nil && data.Epic.Auto
-}
-
 var (
 	// Task selection flags (defined here, registered in root.go)
-	taskID    string
-	epicID    string
-	projectID string
+	taskIDs    []string
+	epicIDs    []string
+	projectIDs []string
 )
 
 // runHeadless executes the headless mode logic with TUI
@@ -127,25 +273,53 @@ func runHeadless() error {
 	// Initialize workdir from CLI flag > env var > "."
 	InitWorkDir()
 
+	// Load config for the workdir, which also settles the flags it covers
+	resolved, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	repoCfg := resolved.Config
+
 	mode, err := parseExecutionMode(executionMode)
 	if err != nil {
 		return err
 	}
 
-	// Load repo-specific config from workdir
-	repoCfg, err := config.Load(GetWorkDir())
+	if name, _ := agent.ParseSpec(agentSpec); !agent.DefaultRegistry.Has(name) {
+		return fmt.Errorf("unknown agent %q (available: %s)", name, strings.Join(agent.AvailableAgents(), ", "))
+	}
+
+	if _, err := resolveStrategy(repoCfg); err != nil {
+		return err
+	}
+	fairnessMode, err := resolveFairness(repoCfg)
 	if err != nil {
-		return fmt.Errorf("loading .momentum.yaml: %w", err)
+		return err
+	}
+	filter, err := resolveFilter(repoCfg)
+	if err != nil {
+		return err
 	}
 
 	// Build criteria string for display
-	criteria := buildCriteriaString()
+	criteria := buildCriteriaString(repoCfg)
+	if desc := filter.String(); desc != "" {
+		criteria += " · " + desc
+	}
 
 	// Create the TUI model
 	modeUpdates := make(chan ui.ExecutionMode, 10)
 	stopUpdates := make(chan string, 10)
 	workDirUpdates := make(chan string, 10)
 	model := ui.NewModel(criteria, mode, GetWorkDir(), modeUpdates, stopUpdates, workDirUpdates)
+	model.SetFairness(describeFairness(fairnessMode, repoCfg.Selection.Weights))
+	if repoCfg.Git.Commit {
+		// Commits take every change in the workdir, so agents must not
+		// overlap
+		model.SetModeLock("Mode is locked to sync while git.commit is on")
+	}
+	whyRequests := make(chan string, 10)
+	model.SetWhyRequests(whyRequests)
 
 	// Create the bubbletea program
 	p := tea.NewProgram(&model, tea.WithAltScreen())
@@ -157,7 +331,7 @@ func runHeadless() error {
 	agents := newRunningAgents()
 
 	// Start the background worker
-	go runWorker(ctx, p, agents, mode, repoCfg, modeUpdates, stopUpdates, workDirUpdates)
+	go runWorker(ctx, p, agents, mode, repoCfg, modeUpdates, stopUpdates, workDirUpdates, whyRequests)
 
 	// Run the TUI
 	_, err = p.Run()
@@ -173,17 +347,161 @@ func runHeadless() error {
 	return nil
 }
 
-func buildCriteriaString() string {
-	if taskID != "" {
-		return fmt.Sprintf("Task: %s", taskID)
+func buildCriteriaString(repoCfg config.RepoConfig) string {
+	return resolveWatch(repoCfg).String()
+}
+
+// resolveWatch collects the projects, epics and tasks to watch from the
+// repeatable --project, --epic and --task flags. When none are given, the
+// watch list in .momentum.yaml is used instead.
+func resolveWatch(repoCfg config.RepoConfig) selection.Watch {
+	if len(projectIDs) > 0 || len(epicIDs) > 0 || len(taskIDs) > 0 {
+		return selection.Watch{Projects: projectIDs, Epics: epicIDs, Tasks: taskIDs}
+	}
+	var w selection.Watch
+	for _, entry := range repoCfg.Watch {
+		switch {
+		case entry.Project != "":
+			w.Projects = append(w.Projects, entry.Project)
+		case entry.Epic != "":
+			w.Epics = append(w.Epics, entry.Epic)
+		case entry.Task != "":
+			w.Tasks = append(w.Tasks, entry.Task)
+		}
+	}
+	return w
+}
+
+// resolveStrategy picks the selection strategy from the --selection-strategy
+// flag, falling back to .momentum.yaml and then the default. The
+// critical-path strategy also takes its task weight from --task-weight.
+func resolveStrategy(repoCfg config.RepoConfig) (selection.Strategy, error) {
+	name := selectionStrategy
+	if name == "" {
+		name = repoCfg.Selection.Strategy
+	}
+	strategy, err := selection.StrategyByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	weightName := taskWeight
+	if weightName == "" {
+		weightName = repoCfg.Selection.TaskWeight
+	}
+	weight, err := selection.ParseTaskWeight(weightName)
+	if err != nil {
+		return nil, err
 	}
-	if epicID != "" {
-		return fmt.Sprintf("Epic: %s", epicID)
+	if strategy == selection.CriticalPath {
+		strategy = selection.NewCriticalPath(weight)
 	}
-	if projectID != "" {
-		return fmt.Sprintf("Project: %s", projectID)
+	return strategy, nil
+}
+
+// resolveFairness picks the project fairness mode from the --fairness flag,
+// falling back to .momentum.yaml and then none.
+func resolveFairness(repoCfg config.RepoConfig) (selection.Fairness, error) {
+	name := fairness
+	if name == "" {
+		name = repoCfg.Selection.Fairness
+	}
+	return selection.ParseFairness(name)
+}
+
+// newWorkflow creates a workflow using the statuses and transition table
+// from .momentum.yaml.
+func newWorkflow(c *client.Client, repoCfg config.RepoConfig) (*workflow.Workflow, error) {
+	statuses := repoStatuses(repoCfg)
+	transitions := workflow.Transitions(repoCfg.Transitions)
+	if err := transitions.Validate(statuses); err != nil {
+		return nil, fmt.Errorf("invalid status configuration: %w", err)
+	}
+	wf := workflow.NewWorkflow(c)
+	wf.SetStatuses(statuses)
+	wf.SetTransitions(transitions)
+	return wf, nil
+}
+
+// repoStatuses returns the configured statuses, with defaults for those
+// left empty.
+func repoStatuses(repoCfg config.RepoConfig) workflow.Statuses {
+	return workflow.Statuses{
+		Queued:    repoCfg.Statuses.Queued,
+		Planning:  repoCfg.Statuses.Planning,
+		Done:      repoCfg.Statuses.Done,
+		PickedUp:  repoCfg.Statuses.PickedUp,
+		Succeeded: repoCfg.Statuses.Succeeded,
+		Failed:    repoCfg.Statuses.Failed,
+		Stopped:   repoCfg.Statuses.Stopped,
+		TimedOut:  repoCfg.Statuses.TimedOut,
+	}.WithDefaults()
+}
+
+// configureSelector applies the resolved strategy and fairness settings.
+func configureSelector(selector *selection.Selector, repoCfg config.RepoConfig) error {
+	strategy, err := resolveStrategy(repoCfg)
+	if err != nil {
+		return err
+	}
+	fairnessMode, err := resolveFairness(repoCfg)
+	if err != nil {
+		return err
+	}
+	filter, err := resolveFilter(repoCfg)
+	if err != nil {
+		return err
+	}
+	selector.SetStrategy(strategy)
+	selector.SetStatuses(selectionStatuses(repoCfg))
+	selector.SetFairness(fairnessMode, repoCfg.Selection.Weights)
+	selector.SetFilter(filter)
+	return nil
+}
+
+// selectionStatuses returns the statuses selection treats as queued and
+// done.
+func selectionStatuses(repoCfg config.RepoConfig) selection.Statuses {
+	return selection.Statuses{Queued: repoCfg.Statuses.Queued, Done: repoCfg.Statuses.Done}
+}
+
+// resolveFilter builds the task filter from the repeatable filter flags,
+// each falling back to its .momentum.yaml counterpart when not given.
+func resolveFilter(repoCfg config.RepoConfig) (selection.Filter, error) {
+	sel := repoCfg.Selection
+	return selection.NewFilter(
+		flagOrConfig(labelFilters, sel.Labels),
+		flagOrConfig(excludeLabelFilters, sel.ExcludeLabels),
+		flagOrConfig(assigneeFilters, sel.Assignees),
+		flagOrConfig(matchFilters, sel.Match),
+	)
+}
+
+func flagOrConfig(flagValues, configValues []string) []string {
+	if len(flagValues) > 0 {
+		return flagValues
+	}
+	return configValues
+}
+
+// describeFairness formats the fairness mode for the TUI, including
+// per-project weights in weighted mode. It returns "" when disabled.
+func describeFairness(mode selection.Fairness, weights map[string]int) string {
+	switch mode {
+	case selection.FairnessRoundRobin:
+		return string(mode)
+	case selection.FairnessWeighted:
+		if len(weights) == 0 {
+			return string(mode)
+		}
+		parts := make([]string, 0, len(weights))
+		for _, id := range slices.Sorted(maps.Keys(weights)) {
+			parts = append(parts, fmt.Sprintf("%s=%d", id, weights[id]))
+		}
+		return fmt.Sprintf("%s (%s)", mode, strings.Join(parts, ", "))
+	default:
+		return ""
 	}
-	return "All projects"
 }
 
 func parseExecutionMode(value string) (ui.ExecutionMode, error) {
@@ -198,26 +516,94 @@ func parseExecutionMode(value string) (ui.ExecutionMode, error) {
 }
 
 // runWorker runs the background task selection and agent spawning
-func runWorker(ctx context.Context, p *tea.Program, agents *runningAgents, mode ui.ExecutionMode, repoCfg config.RepoConfig, modeUpdates <-chan ui.ExecutionMode, stopUpdates <-chan string, workDirUpdates <-chan string) {
+func runWorker(ctx context.Context, p *tea.Program, agents *runningAgents, mode ui.ExecutionMode, repoCfg config.RepoConfig, modeUpdates <-chan ui.ExecutionMode, stopUpdates <-chan string, workDirUpdates <-chan string, whyRequests <-chan string) {
 	// Create the REST client
 	c := client.NewClient(GetBaseURL())
 
 	// Create workflow for status updates
-	wf := workflow.NewWorkflow(c)
+	wf, err := newWorkflow(c, repoCfg)
+	if err != nil {
+		p.Send(ui.ListenerErrorMsg{Err: err})
+		return
+	}
 	wf.SetOutput(io.Discard)
+	wf.SetAgentName(instanceName())
+	if path, err := auditLogPath(repoCfg); err != nil {
+		p.Send(ui.ListenerErrorMsg{Err: err})
+	} else {
+		wf.SetAuditLog(workflow.NewAuditLog(path))
+	}
+	agents.owned = newOwnedTasks(GetBaseURL(), wf.AgentName())
 
 	// Create the selector
-	selector := selection.NewSelector(c, projectID, epicID, taskID)
+	selector := selection.NewWatchSelector(c, resolveWatch(repoCfg))
+	if err := configureSelector(selector, repoCfg); err != nil {
+		p.Send(ui.ListenerErrorMsg{Err: err})
+		return
+	}
 
-	// Start SSE subscriber
+	// Start SSE subscriber, resuming from where the last run left off
 	subscriber := sse.NewSubscriber(GetBaseURL())
-	sseEvents := subscriber.Start(ctx)
+	subscriber.ResumeFrom(loadResumeID(GetBaseURL()))
+	taskEvents := subscriber.Subscribe(sse.Filter{
+		Types: []string{"task.created", "task.updated", "task.status_changed"},
+	})
+	upstreamEvents := subscriber.Subscribe(sse.Filter{
+		Types: []string{"task.updated", "task.status_changed", "task.deleted"},
+	})
+	allEvents := subscriber.Subscribe(sse.Filter{})
+	var epicEvents <-chan sse.TypedEvent
+	if repoCfg.Epics.Complete {
+		epicEvents = subscriber.Subscribe(sse.Filter{
+			Types: []string{"task.updated", "task.status_changed"},
+		})
+	}
+	subscriber.OnStateChange(func(state sse.ConnState) {
+		p.Send(ui.ConnectionStateMsg{State: state.String()})
+	})
+	subscriber.Start(ctx)
 	defer subscriber.Stop()
+	go watchRunningTasks(ctx, p, upstreamEvents, c, agents, wf, repoCfg, ownStatuses(repoCfg))
+	if epicEvents != nil {
+		go watchEpicCompletion(ctx, p, epicEvents, c, wf, repoCfg.Epics)
+	}
+	go func() {
+		for range allEvents {
+			p.Send(ui.FluxEventMsg{At: time.Now()})
+		}
+	}()
+	defer func() { saveResumeID(GetBaseURL(), subscriber.LastEventID()) }()
 
 	// Signal connected
 	p.Send(ui.ListenerConnectedMsg{})
 
-	// Process stop requests and workdir updates even when the main loop blocks waiting for SSE.
+	// Put back tasks left in progress by momentum processes that died
+	report, err := recoverOrphans(c, wf, repoCfg, GetBaseURL(), agents.owned)
+	if err != nil {
+		p.Send(ui.ListenerErrorMsg{Err: fmt.Errorf("recovering orphaned tasks: %w", err)})
+	}
+	if len(report) > 0 {
+		p.Send(ui.NoticeMsg{Text: fmt.Sprintf("Recovered %d orphaned task(s):\n  %s", len(report), strings.Join(report, "\n  "))})
+	}
+
+	pending := make([]*client.Task, 0)
+	queued := make(map[string]bool)
+	// queuedMu guards writes to queued from the main loop and reads from
+	// task lookups; the main loop reads it without locking.
+	var queuedMu sync.Mutex
+
+	// busyTasks returns the IDs of queued and running tasks.
+	busyTasks := func() map[string]bool {
+		queuedMu.Lock()
+		busy := maps.Clone(queued)
+		queuedMu.Unlock()
+		for _, id := range agents.runningIDs() {
+			busy[id] = true
+		}
+		return busy
+	}
+
+	// Process stop requests, workdir updates and task lookups even when the main loop blocks waiting for SSE.
 	go func() {
 		for {
 			select {
@@ -227,29 +613,80 @@ func runWorker(ctx context.Context, p *tea.Program, agents *runningAgents, mode
 				agents.markStoppedByUser(taskID)
 			case newWorkDir := <-workDirUpdates:
 				SetWorkDir(newWorkDir)
+			case taskID := <-whyRequests:
+				go func() {
+					msg := ui.WhyResultMsg{TaskID: taskID}
+					eval, err := selector.Explain(taskID, busyTasks())
+					if err != nil {
+						msg.Err = err
+					} else {
+						msg.Lines = describeEvaluation(eval)
+					}
+					p.Send(msg)
+				}()
 			}
 		}
 	}()
 
-	pending := make([]*client.Task, 0)
-	queued := make(map[string]bool)
+	// claimTask claims a task off the main loop and waits for the result,
+	// still taking mode changes while the claim settles
+	claimTask := func(taskID string) error {
+		result := make(chan error, 1)
+		go func() { result <- wf.Claim(taskID) }()
+		for {
+			select {
+			case mode = <-modeUpdates:
+			case err := <-result:
+				return err
+			}
+		}
+	}
+
+	// pauseFor holds off selection for d, still taking mode changes
+	pauseFor := func(d time.Duration) {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case mode = <-modeUpdates:
+			case <-timer.C:
+				return
+			}
+		}
+	}
 
 	startTask := func(task *client.Task) {
+		queuedMu.Lock()
 		delete(queued, task.ID)
+		queuedMu.Unlock()
 		if !repoCfg.IsAgentMode() {
-			if err := wf.StartWorking([]string{task.ID}); err != nil {
+			if err := claimTask(task.ID); err != nil {
+				if errors.Is(err, workflow.ErrClaimLost) {
+					// Another instance is on it; give it a head start
+					// before competing for the next task
+					pauseFor(claimBackoff())
+					return
+				}
 				p.Send(ui.ListenerErrorMsg{Err: err})
 				return
 			}
 		}
-		spawnAgent(ctx, p, task, wf, agents, repoCfg)
+		agents.owned.hold(task.ID)
+		// Only work that is really ours counts towards fairness
+		if spawnAgent(ctx, p, task, wf, agents, repoCfg) {
+			selector.RecordPick(task)
+		}
 	}
 
 	queueTask := func(task *client.Task) {
 		if queued[task.ID] {
 			return
 		}
+		queuedMu.Lock()
 		queued[task.ID] = true
+		queuedMu.Unlock()
 		pending = append(pending, task)
 	}
 
@@ -279,13 +716,13 @@ func runWorker(ctx context.Context, p *tea.Program, agents *runningAgents, mode
 		case <-ctx.Done():
 			return
 		case <-agents.done():
-		case newMode := <-modeUpdates:
-			mode = newMode
-			if mode == ui.ExecutionModeAsync {
-				startAllPending()
-			}
+		case mode = <-modeUpdates:
 		default:
 		}
+		// Mode changes can also arrive while a claim settles
+		if mode == ui.ExecutionModeAsync {
+			startAllPending()
+		}
 
 		if mode == ui.ExecutionModeSync && len(pending) > 0 && !agents.hasRunning() {
 			startNextPending()
@@ -302,7 +739,7 @@ func runWorker(ctx context.Context, p *tea.Program, agents *runningAgents, mode
 					continue
 				}
 				// Wait for a task to become available (only from auto epics)
-				if err := waitForTaskWithSSE(ctx, sseEvents, selector); err != nil {
+				if err := waitForTaskWithSSE(ctx, taskEvents, selector); err != nil {
 					if errors.Is(err, context.Canceled) {
 						return
 					}
@@ -338,9 +775,65 @@ func runWorker(ctx context.Context, p *tea.Program, agents *runningAgents, mode
 	}
 }
 
+// instanceName identifies this momentum process on the board. It is sent
+// as agent_name with every status change and used as the claim token, so it
+// must differ between instances sharing a board.
+func instanceName() string {
+	name, _ := agent.ParseSpec(agentSpec)
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s@%s:%d", name, host, os.Getpid())
+}
+
+// claimBackoff returns how long to wait after losing a claim, jittered so
+// competing instances spread out.
+func claimBackoff() time.Duration {
+	return 500*time.Millisecond + rand.N(time.Second)
+}
+
+// resumeFile returns where the SSE resume position for a Flux server is
+// kept, or "" when no cache directory is available.
+func resumeFile(baseURL string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(baseURL))
+	return filepath.Join(dir, "momentum", "events-"+hex.EncodeToString(sum[:6]))
+}
+
+// loadResumeID returns the last SSE event ID seen from baseURL by a previous
+// run, or "" if there is none.
+func loadResumeID(baseURL string) string {
+	path := resumeFile(baseURL)
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// saveResumeID records the last SSE event ID seen from baseURL so the next
+// run can ask the server to replay what it missed. Failures are ignored.
+func saveResumeID(baseURL, eventID string) {
+	path := resumeFile(baseURL)
+	if path == "" || eventID == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, []byte(eventID+"\n"), 0o644)
+}
+
 // waitForTaskWithSSE waits for a task to become available using SSE.
 // Only processes events where the epic has auto=true.
-func waitForTaskWithSSE(ctx context.Context, sseEvents <-chan sse.Event, selector *selection.Selector) error {
+func waitForTaskWithSSE(ctx context.Context, taskEvents <-chan sse.TypedEvent, selector *selection.Selector) error {
 	pollTicker := time.NewTicker(5 * time.Second)
 	defer pollTicker.Stop()
 
@@ -349,21 +842,19 @@ func waitForTaskWithSSE(ctx context.Context, sseEvents <-chan sse.Event, selecto
 		case <-ctx.Done():
 			return ctx.Err()
 
-		case event, ok := <-sseEvents:
+		case event, ok := <-taskEvents:
 			if !ok {
+				// Subscriber shut down; keep polling
+				taskEvents = nil
 				continue
 			}
-			// Only process events from auto-enabled epics
-			if !isAutoEpicEvent(event) {
+			// Only process events from auto-enabled epics, or the
+			// stand-in for missed ones
+			if !event.AutoEpic() && !event.Lagged() {
 				continue
 			}
-			if event.Type == "task.created" ||
-				event.Type == "task.updated" ||
-				event.Type == "task.status_changed" ||
-				event.Type == "data-changed" {
-				if _, err := selector.SelectTask(); err == nil {
-					return nil
-				}
+			if _, err := selector.SelectTask(); err == nil {
+				return nil
 			}
 
 		case <-pollTicker.C:
@@ -374,52 +865,180 @@ func waitForTaskWithSSE(ctx context.Context, sseEvents <-chan sse.Event, selecto
 	}
 }
 
-// spawnAgent spawns a new agent for the given task
-func spawnAgent(ctx context.Context, p *tea.Program, task *client.Task, wf *workflow.Workflow, agents *runningAgents, repoCfg config.RepoConfig) {
+// ownStatuses returns the statuses a running task may be moved to by
+// momentum or its own agent: the configured outcomes plus, when the default
+// prompt lets agents move their task, the planning status it tells them to
+// use for blockers. Moving it anywhere else, including back to the queued
+// status, counts as someone taking it away.
+func ownStatuses(repoCfg config.RepoConfig) map[string]bool {
+	statuses := repoStatuses(repoCfg)
+	own := map[string]bool{}
+	for _, status := range []string{statuses.PickedUp, statuses.Succeeded, statuses.Failed, statuses.Stopped, statuses.TimedOut} {
+		if status != "" {
+			own[status] = true
+		}
+	}
+	if agentMovesTask(repoCfg) {
+		own[statuses.Planning] = true
+	}
+	return own
+}
+
+// watchRunningTasks reacts to changes made in Flux to tasks with a running
+// agent. A deleted task, or one moved to a status its agent would not set,
+// cancels the agent. Prompt changes are flagged on the panel and, with
+// restart_on_change, restart the agent with the new prompt. When events
+// were missed, every running task is read back from Flux instead.
+func watchRunningTasks(ctx context.Context, p *tea.Program, events <-chan sse.TypedEvent, c *client.Client, agents *runningAgents, wf *workflow.Workflow, repoCfg config.RepoConfig, own map[string]bool) {
+	check := func(deleted bool, task client.Task) {
+		if !agents.isRunning(task.ID) {
+			return
+		}
+		// Agents move their own tasks, so the status momentum set may be
+		// out of date by the time the agent exits
+		if !deleted {
+			wf.Known(task.ID, task.Status)
+		}
+
+		switch {
+		case deleted:
+			if agents.cancelUpstream(task.ID) {
+				p.Send(ui.TaskChangedUpstreamMsg{TaskID: task.ID, Note: "deleted upstream", Cancelled: true})
+			}
+		case !own[task.Status]:
+			if agents.cancelUpstream(task.ID) {
+				p.Send(ui.TaskChangedUpstreamMsg{TaskID: task.ID, Note: "moved to " + task.Status + " upstream", Cancelled: true})
+			}
+		case agents.promptChanged(task.ID, buildHeadlessPrompt(&task, repoCfg)):
+			note := "task changed upstream"
+			if repoCfg.RestartOnChange && agents.restart(&task) {
+				note += ", restarting"
+			}
+			p.Send(ui.TaskChangedUpstreamMsg{TaskID: task.ID, Note: note})
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Lagged() {
+				for _, id := range agents.runningIDs() {
+					task, err := c.GetTask(id)
+					var apiErr *client.APIError
+					switch {
+					case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+						check(true, client.Task{ID: id})
+					case err != nil:
+						p.Send(ui.ListenerErrorMsg{Err: err})
+					default:
+						check(false, *task)
+					}
+				}
+				continue
+			}
+			if event.Task != nil {
+				check(event.Type == "task.deleted", event.Task.Task)
+			}
+		}
+	}
+}
+
+// spawnAgent spawns a new agent for the given task and reports whether it
+// started. A task whose agent cannot start is given up (see abandonTask).
+func spawnAgent(ctx context.Context, p *tea.Program, task *client.Task, wf *workflow.Workflow, agents *runningAgents, repoCfg config.RepoConfig) bool {
 	// Create agent
-	ag := agent.NewClaudeCode(agent.Config{
+	name, options := agent.ParseSpec(agentSpec)
+	ag, err := agent.CreateAgent(name, agent.Config{
 		WorkDir: GetWorkDir(),
+		Timeout: repoCfg.Timeout,
+		Options: options,
 	})
+	if err != nil {
+		abandonTask(p, task, wf, agents, repoCfg, err)
+		return false
+	}
 
 	runner := agent.NewRunner(ag)
 
+	// Commits take every change in the workdir, so note whether it was
+	// clean before the agent started. A restart keeps the first answer.
+	if repoCfg.Git.Commit && !agents.isRunning(task.ID) {
+		agents.setDirty(task.ID, gitDirty(ctx, GetWorkDir()))
+	}
+
 	// Mark task as having a running agent (with runner reference for cleanup)
 	agents.markRunning(task.ID, runner)
 
 	// Build prompt
 	prompt := buildHeadlessPrompt(task, repoCfg)
+	agents.setPrompt(task.ID, prompt)
+
+	// Remember where the work started so the commits the agent makes can
+	// be reported
+	base := gitHead(ctx, GetWorkDir())
 
 	// Start the agent
 	if err := runner.Run(ctx, prompt); err != nil {
-		agents.markDone(task.ID)
-		p.Send(ui.ListenerErrorMsg{Err: err})
-		return
+		abandonTask(p, task, wf, agents, repoCfg, err)
+		return false
 	}
 
 	// Add panel to UI via message
 	p.Send(ui.AddAgentMsg{
 		TaskID:    task.ID,
 		TaskTitle: task.Title,
-		AgentName: "Claude",
+		AgentName: ag.Name(),
 		Runner:    runner,
 	})
 
-	// Stream output in background
+	// Stream output in background, keeping the agent's last message for
+	// the outcome report
+	final := make(chan string, 1)
 	go func() {
+		var last string
 		for line := range runner.Output() {
+			if msg := finalMessage(line.Text); msg != "" && !line.IsStderr {
+				last = msg
+			}
 			p.Send(ui.AgentOutputMsg{
 				TaskID: task.ID,
 				Line:   line,
 			})
 		}
+		final <- last
 	}()
 
 	// Wait for completion in background
 	go func() {
 		result := <-runner.Done()
 
-		// Check if stopped by user before marking done (which clears the flag)
+		// A restart keeps the task marked as running so it is not picked
+		// up again in between
+		if updated := agents.takeRestart(task.ID); updated != nil && !agents.wasStoppedByUser(task.ID) {
+			p.Send(ui.AgentCompletedMsg{
+				TaskID: task.ID,
+				Result: result,
+			})
+			spawnAgent(ctx, p, updated, wf, agents, repoCfg)
+			return
+		}
+
+		// Check flags before marking done (which clears them)
 		stoppedByUser := agents.wasStoppedByUser(task.ID)
+		cancelledUpstream := agents.wasCancelledUpstream(task.ID)
+		shared := agents.ranAlongside(task.ID)
+
+		// Hold the workdir until the changes are committed, so the next
+		// agent does not start on top of them
+		if repoCfg.Git.Commit {
+			agents.markSettling(task.ID)
+			defer agents.markSettled(task.ID)
+		}
 
 		// Mark agent as done
 		agents.markDone(task.ID)
@@ -429,23 +1048,148 @@ func spawnAgent(ctx context.Context, p *tea.Program, task *client.Task, wf *work
 			Result: result,
 		})
 
-		// Update task status based on mode:
+		// Someone else already moved or deleted the task; leave it be
+		if cancelledUpstream {
+			wf.Known(task.ID, "")
+			agents.owned.release(task.ID)
+			return
+		}
+
+		// Report the outcome on the task, then update its status based on
+		// mode:
 		// - orchestrator: momentum manages all transitions
-		// - agent: momentum only resets on user stop (safety net)
-		if stoppedByUser {
-			wf.ResetToPlanning([]string{task.ID})
-		} else if !repoCfg.IsAgentMode() && result.ExitCode == 0 {
-			wf.MarkComplete([]string{task.ID})
+		// - agent: momentum only resets on user stop or timeout (safety net)
+		message := <-final
+		outcome := gatherOutcome(ctx, GetWorkDir(), base, describeEnd(result, stoppedByUser), message, result.Duration)
+		outcome.Shared = shared
+
+		// Work the agent discovered becomes tasks of their own; blockers
+		// send the task back to wait for them
+		var blocked bool
+		if discovered := workflow.ParseDiscovered(message); !discovered.Empty() {
+			created, ok, err := wf.CreateDiscovered(task, discovered)
+			if err != nil {
+				p.Send(ui.ListenerErrorMsg{Err: err})
+			}
+			for _, t := range created {
+				outcome.Created = append(outcome.Created, t.ID+" "+t.Title)
+			}
+			blocked = ok && !repoCfg.IsAgentMode()
+			if blocked {
+				outcome.Ended = "blocked"
+			}
+		}
+
+		succeeded := !stoppedByUser && result.ExitCode == 0 && !errors.Is(result.Error, agent.ErrAgentTimeout) && !blocked
+		submitForReview := succeeded && repoCfg.Review.Enabled && !repoCfg.IsAgentMode()
+		if submitForReview && repoCfg.Review.Verify != "" {
+			outcome.Verification = verify(ctx, GetWorkDir(), repoCfg.Review.Verify)
+		}
+		if succeeded && repoCfg.Git.Commit {
+			if reason := commitSkipReason(agents.ranAlongside(task.ID), agents.startedDirty(task.ID)); reason != "" {
+				outcome.CommitSkipped = reason
+				p.Send(ui.NoticeMsg{Text: fmt.Sprintf("Not committing task %s: %s", task.ID, reason)})
+			} else {
+				commit, err := commitTask(ctx, GetWorkDir(), task, repoCfg.Git)
+				outcome.Commit = commit
+				if err != nil {
+					outcome.CommitError = err.Error()
+					p.Send(ui.ListenerErrorMsg{Err: fmt.Errorf("failed to commit task %s: %w", task.ID, err)})
+				}
+			}
+		}
+		// A review summary doubles as the outcome report
+		if !submitForReview {
+			if err := wf.RecordOutcome(task.ID, outcome); err != nil {
+				p.Send(ui.ListenerErrorMsg{Err: err})
+			}
+		}
+
+		var err error
+		switch {
+		case stoppedByUser:
+			err = wf.MarkStopped([]string{task.ID})
+		case errors.Is(result.Error, agent.ErrAgentTimeout):
+			err = wf.MarkTimedOut([]string{task.ID})
+		case repoCfg.IsAgentMode():
+		case blocked:
+			err = wf.MarkBlocked([]string{task.ID})
+		case submitForReview:
+			err = wf.SubmitForReview(task.ID, outcome)
+		case result.ExitCode == 0:
+			err = wf.MarkComplete([]string{task.ID})
+		default:
+			// Left in its picked-up status unless a failed status is set
+			err = wf.MarkFailed([]string{task.ID})
 		}
-		// On failure (not stopped by user), leave as in_progress for investigation
+		if err != nil {
+			p.Send(ui.ListenerErrorMsg{Err: err})
+		}
+		wf.Known(task.ID, "")
+		agents.owned.release(task.ID)
 	}()
+	return true
+}
+
+// abandonTask reports why no agent could start on a held task and lets go
+// of it. Claimed tasks go to the failed status if one is set and back to
+// the queued status otherwise, so they are not left picked up with nobody
+// working on them.
+func abandonTask(p *tea.Program, task *client.Task, wf *workflow.Workflow, agents *runningAgents, repoCfg config.RepoConfig, err error) {
+	agents.markDone(task.ID)
+	p.Send(ui.ListenerErrorMsg{Err: fmt.Errorf("failed to start an agent on task %s: %w", task.ID, err)})
+	if !repoCfg.IsAgentMode() {
+		var resetErr error
+		if wf.Statuses().Failed != "" {
+			resetErr = wf.MarkFailed([]string{task.ID})
+		} else {
+			resetErr = wf.ResetTask([]string{task.ID})
+		}
+		if resetErr != nil {
+			p.Send(ui.ListenerErrorMsg{Err: resetErr})
+		}
+	}
+	wf.Known(task.ID, "")
+	agents.owned.release(task.ID)
+}
+
+// agentMovesTask reports whether the default prompt tells agents to move
+// their own task. It only does on a stock board outside review mode; with
+// anything else the agent could not know which status momentum expects, so
+// momentum moves the task when the agent finishes.
+func agentMovesTask(repoCfg config.RepoConfig) bool {
+	if repoCfg.Instructions != "" || repoCfg.Review.Enabled {
+		return false
+	}
+	statuses := repoStatuses(repoCfg)
+	// Agents never set the failed status themselves
+	statuses.Failed = ""
+	return statuses == workflow.DefaultStatuses()
 }
 
 // defaultPreamble is used when no repo-specific instructions are configured.
-const defaultPreamble = `Goal: complete a single Flux task end-to-end, verify it works, and mark the task as done in Flux.
+// Agents are only told to move the task on a stock board (see
+// agentMovesTask).
+func defaultPreamble(repoCfg config.RepoConfig) string {
+	if !agentMovesTask(repoCfg) {
+		return fmt.Sprintf(preambleTemplate,
+			"and report back; momentum updates the task in Flux when you finish",
+			"Do not change the task's status; momentum moves it when you finish. Mention the task ID in your final message.",
+			"")
+	}
+	statuses := repoStatuses(repoCfg)
+	return fmt.Sprintf(preambleTemplate,
+		fmt.Sprintf("and mark the task as %s in Flux", statuses.Succeeded),
+		fmt.Sprintf("Mark the task as %s using Flux MCP (mcp__flux__move_task_status with status %q) and mention the task ID in your final message.", statuses.Succeeded, statuses.Succeeded),
+		fmt.Sprintf(" If nothing can be split out and only a person can unblock it, set the task status to %q instead.", statuses.Planning))
+}
+
+// preambleTemplate is filled in by defaultPreamble with the goal's ending,
+// the final step and what to do about blockers that cannot be split out.
+const preambleTemplate = `Goal: complete a single Flux task end-to-end, verify it works, %s.
 
 Process:
-1) Find the task to work on (use the given task ID/title, or select the highest-priority todo in the target project).
+1) Find the task to work on (use the given task ID/title, or select the highest-priority queued task in the target project).
 2) Inspect relevant files; keep changes minimal and aligned with existing patterns.
 3) Implement the task.
 4) Verify the change:
@@ -453,14 +1197,20 @@ Process:
    - Report what you ran and the result.
    - Add a comment to the task via MCP using mcp__flux__add_task_comment.
      Example: {"task_id":"<id>","body":"What you did + verification results + any notes."}
-5) Mark the task as done using Flux MCP (mcp__flux__move_task_status with status "done") and mention the task ID in your final message.
+5) %s
 
 Constraints:
 - Do not modify unrelated files.
 - Do not reset/revert unrelated git changes.
 - Be concise in explanations.
 
-If anything blocks completion, stop and report the blocker instead of guessing, and set the task status back to "planning", and add a comment explaining the issue.
+If anything blocks completion, stop and report the blocker instead of guessing, and add a comment explaining the issue. List the work that has to happen first as blockers in the json block below; momentum creates them and queues this task behind them.%s
+
+To have separate tasks created for work you could not do here, end your final message with a fenced json block:
+` + "```json" + `
+{"blockers": [{"title": "...", "notes": "..."}], "follow_ups": [{"title": "...", "notes": "..."}]}
+` + "```" + `
+Blockers must be done before this task can be finished; follow-ups come after it.
 `
 
 // buildHeadlessPrompt constructs the prompt for the agent.
@@ -473,7 +1223,7 @@ func buildHeadlessPrompt(task *client.Task, repoCfg config.RepoConfig) string {
 		b.WriteString(repoCfg.Instructions)
 		b.WriteString("\n")
 	} else {
-		b.WriteString(defaultPreamble)
+		b.WriteString(defaultPreamble(repoCfg))
 	}
 
 	b.WriteString("Task context:\n")
@@ -481,8 +1231,9 @@ func buildHeadlessPrompt(task *client.Task, repoCfg config.RepoConfig) string {
 	b.WriteString(fmt.Sprintf("- Task ID: %s\n", task.ID))
 	b.WriteString(fmt.Sprintf("- Task: %s\n", task.Title))
 
-	if task.Notes != "" {
-		b.WriteString(fmt.Sprintf("- Details:\n%s\n", task.Notes))
+	// The outcome of an earlier run is for people, not the next agent
+	if notes := workflow.StripOutcome(task.Notes); notes != "" {
+		b.WriteString(fmt.Sprintf("- Details:\n%s\n", notes))
 	}
 
 	// Acceptance criteria
@@ -505,5 +1256,7 @@ func buildHeadlessPrompt(task *client.Task, repoCfg config.RepoConfig) string {
 		}
 	}
 
+	writeReviewFeedback(&b, task)
+
 	return b.String()
 }
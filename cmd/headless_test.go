@@ -1,14 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stephenmfriend/momentum/client"
 	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/fluxtest"
+	"github.com/stephenmfriend/momentum/selection"
 	"github.com/stephenmfriend/momentum/sse"
+	"github.com/stephenmfriend/momentum/ui"
+	"github.com/stephenmfriend/momentum/workflow"
 )
 
 func TestNewRunningAgents(t *testing.T) {
@@ -41,6 +55,57 @@ func TestRunningAgents_MarkRunningAndIsRunning(t *testing.T) {
 	}
 }
 
+func TestRunningAgents_RanAlongside(t *testing.T) {
+	agents := newRunningAgents()
+
+	agents.markRunning("task-1", nil)
+	if agents.ranAlongside("task-1") {
+		t.Error("a lone agent should not count as shared")
+	}
+
+	// Both the running agent and the new one share the workdir
+	agents.markRunning("task-2", nil)
+	agents.markDone("task-1")
+	if !agents.ranAlongside("task-2") {
+		t.Error("task-2 ran alongside task-1")
+	}
+
+	// A restart leaves the other agent's changes in the workdir
+	agents.markRunning("task-2", nil)
+	if !agents.ranAlongside("task-2") {
+		t.Error("a restart should keep counting as shared")
+	}
+
+	agents.markDone("task-2")
+	agents.markRunning("task-2", nil)
+	if agents.ranAlongside("task-2") {
+		t.Error("a new run alone should not count as shared")
+	}
+}
+
+func TestRunningAgents_Settling(t *testing.T) {
+	agents := newRunningAgents()
+
+	agents.markRunning("task-1", nil)
+	agents.markRunning("task-2", nil)
+	agents.markDone("task-2")
+	agents.markSettling("task-1")
+	agents.markDone("task-1")
+
+	// The workdir stays taken, and what the run shared it with is kept
+	if !agents.hasRunning() {
+		t.Error("a settling task should hold the workdir")
+	}
+	if !agents.ranAlongside("task-1") {
+		t.Error("task-1 should still count as shared while settling")
+	}
+
+	agents.markSettled("task-1")
+	if agents.hasRunning() || agents.ranAlongside("task-1") {
+		t.Error("a settled task should release the workdir")
+	}
+}
+
 func TestRunningAgents_MarkDone(t *testing.T) {
 	agents := newRunningAgents()
 
@@ -101,88 +166,18 @@ func TestRunningAgents_ConcurrentAccess(t *testing.T) {
 	wg.Wait()
 }
 
-func TestIsAutoEpicEvent_AutoTrue(t *testing.T) {
-	event := sse.Event{
-		Type: "task.created",
-		Data: `{"epic": {"auto": true}}`,
-	}
-	if !isAutoEpicEvent(event) {
-		t.Error("expected event with epic.auto=true to return true")
-	}
-}
-
-func TestIsAutoEpicEvent_AutoFalse(t *testing.T) {
-	event := sse.Event{
-		Type: "task.created",
-		Data: `{"epic": {"auto": false}}`,
-	}
-	if isAutoEpicEvent(event) {
-		t.Error("expected event with epic.auto=false to return false")
-	}
-}
-
-func TestIsAutoEpicEvent_NoEpicField(t *testing.T) {
-	event := sse.Event{
-		Type: "task.created",
-		Data: `{"task": {"id": "123"}}`,
-	}
-	if isAutoEpicEvent(event) {
-		t.Error("expected event without epic field to return false")
-	}
-}
-
-func TestIsAutoEpicEvent_EmptyEpic(t *testing.T) {
-	event := sse.Event{
-		Type: "task.created",
-		Data: `{"epic": {}}`,
-	}
-	if isAutoEpicEvent(event) {
-		t.Error("expected event with empty epic to return false")
-	}
-}
-
-func TestIsAutoEpicEvent_InvalidJSON(t *testing.T) {
-	event := sse.Event{
-		Type: "task.created",
-		Data: `not valid json`,
-	}
-	if isAutoEpicEvent(event) {
-		t.Error("expected invalid JSON to return false")
-	}
-}
-
-func TestIsAutoEpicEvent_EmptyData(t *testing.T) {
-	event := sse.Event{
-		Type: "task.created",
-		Data: "",
-	}
-	if isAutoEpicEvent(event) {
-		t.Error("expected empty data to return false")
-	}
-}
-
-func TestIsAutoEpicEvent_NullEpic(t *testing.T) {
-	event := sse.Event{
-		Type: "task.created",
-		Data: `{"epic": null}`,
-	}
-	if isAutoEpicEvent(event) {
-		t.Error("expected null epic to return false")
-	}
-}
-
 func TestBuildCriteriaString_TaskID(t *testing.T) {
 	// Save and restore package variables
-	oldTaskID, oldEpicID, oldProjectID := taskID, epicID, projectID
+	oldTaskIDs, oldEpicIDs, oldProjectIDs := taskIDs, epicIDs, projectIDs
 	defer func() {
-		taskID, epicID, projectID = oldTaskID, oldEpicID, oldProjectID
+		taskIDs, epicIDs, projectIDs = oldTaskIDs, oldEpicIDs, oldProjectIDs
 	}()
 
-	taskID = "task-123"
-	epicID = ""
-	projectID = ""
+	taskIDs = []string{"task-123"}
+	epicIDs = nil
+	projectIDs = nil
 
-	result := buildCriteriaString()
+	result := buildCriteriaString(config.RepoConfig{})
 	expected := "Task: task-123"
 	if result != expected {
 		t.Errorf("expected %q, got %q", expected, result)
@@ -190,16 +185,16 @@ func TestBuildCriteriaString_TaskID(t *testing.T) {
 }
 
 func TestBuildCriteriaString_EpicID(t *testing.T) {
-	oldTaskID, oldEpicID, oldProjectID := taskID, epicID, projectID
+	oldTaskIDs, oldEpicIDs, oldProjectIDs := taskIDs, epicIDs, projectIDs
 	defer func() {
-		taskID, epicID, projectID = oldTaskID, oldEpicID, oldProjectID
+		taskIDs, epicIDs, projectIDs = oldTaskIDs, oldEpicIDs, oldProjectIDs
 	}()
 
-	taskID = ""
-	epicID = "epic-456"
-	projectID = ""
+	taskIDs = nil
+	epicIDs = []string{"epic-456"}
+	projectIDs = nil
 
-	result := buildCriteriaString()
+	result := buildCriteriaString(config.RepoConfig{})
 	expected := "Epic: epic-456"
 	if result != expected {
 		t.Errorf("expected %q, got %q", expected, result)
@@ -207,16 +202,16 @@ func TestBuildCriteriaString_EpicID(t *testing.T) {
 }
 
 func TestBuildCriteriaString_ProjectID(t *testing.T) {
-	oldTaskID, oldEpicID, oldProjectID := taskID, epicID, projectID
+	oldTaskIDs, oldEpicIDs, oldProjectIDs := taskIDs, epicIDs, projectIDs
 	defer func() {
-		taskID, epicID, projectID = oldTaskID, oldEpicID, oldProjectID
+		taskIDs, epicIDs, projectIDs = oldTaskIDs, oldEpicIDs, oldProjectIDs
 	}()
 
-	taskID = ""
-	epicID = ""
-	projectID = "project-789"
+	taskIDs = nil
+	epicIDs = nil
+	projectIDs = []string{"project-789"}
 
-	result := buildCriteriaString()
+	result := buildCriteriaString(config.RepoConfig{})
 	expected := "Project: project-789"
 	if result != expected {
 		t.Errorf("expected %q, got %q", expected, result)
@@ -224,43 +219,63 @@ func TestBuildCriteriaString_ProjectID(t *testing.T) {
 }
 
 func TestBuildCriteriaString_NoFilters(t *testing.T) {
-	oldTaskID, oldEpicID, oldProjectID := taskID, epicID, projectID
+	oldTaskIDs, oldEpicIDs, oldProjectIDs := taskIDs, epicIDs, projectIDs
 	defer func() {
-		taskID, epicID, projectID = oldTaskID, oldEpicID, oldProjectID
+		taskIDs, epicIDs, projectIDs = oldTaskIDs, oldEpicIDs, oldProjectIDs
 	}()
 
-	taskID = ""
-	epicID = ""
-	projectID = ""
+	taskIDs = nil
+	epicIDs = nil
+	projectIDs = nil
 
-	result := buildCriteriaString()
+	result := buildCriteriaString(config.RepoConfig{})
 	expected := "All projects"
 	if result != expected {
 		t.Errorf("expected %q, got %q", expected, result)
 	}
 }
 
-func TestBuildCriteriaString_PriorityOrder(t *testing.T) {
-	// Task ID takes priority over epic and project
-	oldTaskID, oldEpicID, oldProjectID := taskID, epicID, projectID
+func TestBuildCriteriaString_CombinesFlags(t *testing.T) {
+	// Repeated and mixed flags are all listed
+	oldTaskIDs, oldEpicIDs, oldProjectIDs := taskIDs, epicIDs, projectIDs
 	defer func() {
-		taskID, epicID, projectID = oldTaskID, oldEpicID, oldProjectID
+		taskIDs, epicIDs, projectIDs = oldTaskIDs, oldEpicIDs, oldProjectIDs
 	}()
 
-	taskID = "task-123"
-	epicID = "epic-456"
-	projectID = "project-789"
+	taskIDs = []string{"task-123"}
+	epicIDs = []string{"epic-456", "epic-457"}
+	projectIDs = []string{"project-789"}
 
-	result := buildCriteriaString()
-	if result != "Task: task-123" {
-		t.Errorf("expected task ID to take priority, got %q", result)
+	result := buildCriteriaString(config.RepoConfig{})
+	expected := "Project: project-789 · Epics: epic-456, epic-457 · Task: task-123"
+	if result != expected {
+		t.Errorf("expected %q, got %q", expected, result)
 	}
+}
 
-	// Epic takes priority over project
-	taskID = ""
-	result = buildCriteriaString()
-	if result != "Epic: epic-456" {
-		t.Errorf("expected epic ID to take priority over project, got %q", result)
+func TestResolveWatch_ConfigUsedWithoutFlags(t *testing.T) {
+	oldTaskIDs, oldEpicIDs, oldProjectIDs := taskIDs, epicIDs, projectIDs
+	defer func() {
+		taskIDs, epicIDs, projectIDs = oldTaskIDs, oldEpicIDs, oldProjectIDs
+	}()
+
+	repoCfg := config.RepoConfig{Watch: []config.WatchEntry{
+		{Project: "web"},
+		{Epic: "epic-1"},
+		{Project: "api"},
+	}}
+
+	taskIDs, epicIDs, projectIDs = nil, nil, nil
+	got := resolveWatch(repoCfg)
+	if got.String() != "Projects: web, api · Epic: epic-1" {
+		t.Errorf("expected config watch list, got %q", got.String())
+	}
+
+	// Any selection flag replaces the config list entirely
+	taskIDs = []string{"task-9"}
+	got = resolveWatch(repoCfg)
+	if got.String() != "Task: task-9" {
+		t.Errorf("expected flags to replace config, got %q", got.String())
 	}
 }
 
@@ -283,6 +298,30 @@ func TestBuildHeadlessPrompt_BasicTask(t *testing.T) {
 	}
 }
 
+func TestBuildHeadlessPrompt_StatusInstructions(t *testing.T) {
+	task := &client.Task{ID: "task-123", Title: "Fix the bug"}
+
+	result := buildHeadlessPrompt(task, config.RepoConfig{})
+	if !contains(result, `mcp__flux__move_task_status with status "done"`) || !contains(result, `set the task status to "planning"`) {
+		t.Errorf("stock board prompt should tell the agent to move the task:\n%s", result)
+	}
+
+	for name, cfg := range map[string]config.RepoConfig{
+		"review mode":     {Review: config.ReviewConfig{Enabled: true}},
+		"custom statuses": {Statuses: config.StatusConfig{Succeeded: "shipped"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result := buildHeadlessPrompt(task, cfg)
+			if contains(result, "mcp__flux__move_task_status") {
+				t.Errorf("prompt should not tell the agent to move the task:\n%s", result)
+			}
+			if !contains(result, "Do not change the task's status") {
+				t.Errorf("prompt should tell the agent to leave the status alone:\n%s", result)
+			}
+		})
+	}
+}
+
 func TestBuildHeadlessPrompt_WithNotes(t *testing.T) {
 	task := &client.Task{
 		ID:    "task-123",
@@ -300,6 +339,23 @@ func TestBuildHeadlessPrompt_WithNotes(t *testing.T) {
 	}
 }
 
+func TestBuildHeadlessPrompt_LeavesOutOutcome(t *testing.T) {
+	task := &client.Task{
+		ID:    "task-123",
+		Title: "Fix the bug",
+		Notes: "Check line 42.\n\n<!-- momentum:outcome -->\nLast run failed (exit 1).",
+	}
+
+	result := buildHeadlessPrompt(task, config.RepoConfig{})
+
+	if !contains(result, "Check line 42.") {
+		t.Error("prompt should contain notes content")
+	}
+	if contains(result, "momentum:outcome") || contains(result, "Last run failed") {
+		t.Errorf("prompt should not contain the previous outcome:\n%s", result)
+	}
+}
+
 func TestBuildHeadlessPrompt_EmptyNotes(t *testing.T) {
 	task := &client.Task{
 		ID:    "task-123",
@@ -902,3 +958,606 @@ func timeAfter(seconds int) <-chan struct{} {
 	}()
 	return ch
 }
+
+// --- runWorker integration tests (fake Flux server + fake agent) ---
+
+// workerHarness runs runWorker against an in-memory Flux server with a
+// headless TUI program.
+type workerHarness struct {
+	flux   *fluxtest.Server
+	cancel context.CancelFunc
+	agents *runningAgents
+	modes  chan ui.ExecutionMode
+	stops  chan string
+}
+
+func startWorkerHarness(t *testing.T, flux *fluxtest.Server, mode ui.ExecutionMode, spec string, repoCfg config.RepoConfig) *workerHarness {
+	t.Helper()
+
+	server := httptest.NewServer(flux)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	oldBaseURL, oldAgentSpec, oldWorkDir := baseURL, agentSpec, workDir
+	oldTaskIDs, oldEpicIDs, oldProjectIDs := taskIDs, epicIDs, projectIDs
+	baseURL, agentSpec, workDir = server.URL, spec, t.TempDir()
+	taskIDs, epicIDs, projectIDs = nil, nil, nil
+
+	h := &workerHarness{
+		flux:   flux,
+		agents: newRunningAgents(),
+		modes:  make(chan ui.ExecutionMode, 10),
+		stops:  make(chan string, 10),
+	}
+	workDirUpdates := make(chan string, 10)
+
+	model := ui.NewModel("test", mode, workDir, h.modes, h.stops, workDirUpdates)
+	p := tea.NewProgram(&model, tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutRenderer())
+	programDone := make(chan struct{})
+	go func() {
+		p.Run()
+		close(programDone)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	workerDone := make(chan struct{})
+	go func() {
+		runWorker(ctx, p, h.agents, mode, repoCfg, h.modes, h.stops, workDirUpdates, nil)
+		close(workerDone)
+	}()
+
+	t.Cleanup(func() {
+		h.agents.cancelAll()
+		cancel()
+		<-workerDone
+		// Cancelled agents still report on their tasks, reading the
+		// globals restored below
+		deadline := time.Now().Add(5 * time.Second)
+		for holdsTasks(h.agents.owned) && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		p.Quit()
+		<-programDone
+		flux.Close()
+		server.Close()
+		baseURL, agentSpec, workDir = oldBaseURL, oldAgentSpec, oldWorkDir
+		taskIDs, epicIDs, projectIDs = oldTaskIDs, oldEpicIDs, oldProjectIDs
+	})
+	return h
+}
+
+// waitForStatus polls the fake server until the task reaches status.
+func (h *workerHarness) waitForStatus(t *testing.T, id, status string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if task, ok := h.flux.Task(id); ok && task.Status == status {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	task, _ := h.flux.Task(id)
+	t.Fatalf("task %s: expected status %q, got %q", id, status, task.Status)
+}
+
+func writeScript(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func seedAutoTasks(flux *fluxtest.Server, n int) []client.Task {
+	project := flux.AddProject("Proj", "")
+	epic := flux.AddEpic(project.ID, "Epic", true)
+	var tasks []client.Task
+	for i := 0; i < n; i++ {
+		tasks = append(tasks, flux.AddTask(client.Task{
+			Title:     fmt.Sprintf("Task %d", i+1),
+			ProjectID: project.ID,
+			EpicID:    epic.ID,
+		}))
+	}
+	return tasks
+}
+
+func TestRunWorker_SuccessMarksDone(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 2)
+	script := writeScript(t, `{"type":"result","result":"ok"}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{})
+
+	for _, task := range tasks {
+		h.waitForStatus(t, task.ID, "done")
+	}
+}
+
+func TestRunWorker_FailureLeavesInProgress(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"fake":{"exit":1}}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{})
+
+	h.waitForStatus(t, tasks[0].ID, "in_progress")
+	// The outcome is the last thing written before the task is settled
+	h.waitForNotes(t, tasks[0].ID, "Last run failed (exit 1)")
+	if task, _ := flux.Task(tasks[0].ID); task.Status != "in_progress" {
+		t.Errorf("expected failed task to stay in_progress, got %q", task.Status)
+	}
+}
+
+func TestRunWorker_UserStopResetsToPlanning(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"fake":{"sleep":"10s"}}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{})
+
+	h.waitForStatus(t, tasks[0].ID, "in_progress")
+	h.waitForAgent(t, tasks[0].ID, true)
+	h.stops <- tasks[0].ID
+	deadline := time.Now().Add(5 * time.Second)
+	for !h.agents.wasStoppedByUser(tasks[0].ID) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the stop request to be recorded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	h.agents.cancelAll()
+
+	h.waitForStatus(t, tasks[0].ID, "planning")
+}
+
+// waitForAgent polls until the task's agent is running (or not).
+func (h *workerHarness) waitForAgent(t *testing.T, id string, running bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if h.agents.isRunning(id) == running {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("task %s: expected agent running=%v", id, running)
+}
+
+// waitForEvents polls until the worker's event stream is connected, so
+// changes made afterwards reach it.
+func (h *workerHarness) waitForEvents(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if h.flux.Subscribers() > 0 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("expected the worker to subscribe to events")
+}
+
+// waitForNotes polls until the task's notes contain want.
+func (h *workerHarness) waitForNotes(t *testing.T, id, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if task, ok := h.flux.Task(id); ok && strings.Contains(task.Notes, want) {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	task, _ := h.flux.Task(id)
+	t.Fatalf("task %s: expected notes to contain %q, got %q", id, want, task.Notes)
+}
+
+// waitForRelease polls until no ownership record holds the task, which
+// momentum writes once it has settled the task after its agent exits. The
+// task must already be held.
+func (h *workerHarness) waitForRelease(t *testing.T, id string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if !heldByAnyRecord(ownershipDir(baseURL), id) {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("task %s: expected it to be released", id)
+}
+
+// holdsTasks reports whether o still holds any task.
+func holdsTasks(o *ownedTasks) bool {
+	if o == nil {
+		return false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.state.Tasks) > 0
+}
+
+// heldByAnyRecord reports whether an ownership record in dir holds taskID.
+func heldByAnyRecord(dir, taskID string) bool {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var record ownership
+		if json.Unmarshal(data, &record) == nil {
+			if _, ok := record.Tasks[taskID]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestRunWorker_AgentFailsToStart(t *testing.T) {
+	for name, spec := range map[string]string{
+		"unknown agent":  "nope:",
+		"missing script": "fake:" + filepath.Join(t.TempDir(), "missing.jsonl"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			flux := fluxtest.New()
+			tasks := seedAutoTasks(flux, 1)
+			repoCfg := config.RepoConfig{Statuses: config.StatusConfig{Failed: "blocked"}}
+
+			h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, spec, repoCfg)
+
+			// Given up rather than left picked up with nobody on it
+			h.waitForStatus(t, tasks[0].ID, "blocked")
+			h.waitForRelease(t, tasks[0].ID)
+			h.waitForAgent(t, tasks[0].ID, false)
+		})
+	}
+}
+
+func TestOwnStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoCfg config.RepoConfig
+		want    []string
+	}{
+		{name: "stock board", want: []string{"done", "in_progress", "planning"}},
+		{
+			name:    "review mode",
+			repoCfg: config.RepoConfig{Statuses: config.StatusConfig{Succeeded: "review"}, Review: config.ReviewConfig{Enabled: true}},
+			want:    []string{"in_progress", "planning", "review"},
+		},
+		{
+			name: "custom statuses",
+			repoCfg: config.RepoConfig{Statuses: config.StatusConfig{
+				PickedUp: "doing", Succeeded: "shipped", Failed: "broken", Stopped: "paused", TimedOut: "paused",
+			}},
+			want: []string{"broken", "doing", "paused", "shipped"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Sorted(maps.Keys(ownStatuses(tt.repoCfg)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ownStatuses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWorker_UpstreamMoveCancelsAgent(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"fake":{"sleep":"10s"}}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{})
+
+	h.waitForStatus(t, tasks[0].ID, "in_progress")
+	h.waitForAgent(t, tasks[0].ID, true)
+	h.waitForEvents(t)
+	if _, err := client.NewClient(baseURL).MoveTaskStatus(tasks[0].ID, "backlog"); err != nil {
+		t.Fatal(err)
+	}
+
+	h.waitForAgent(t, tasks[0].ID, false)
+	h.waitForRelease(t, tasks[0].ID)
+	if task, _ := flux.Task(tasks[0].ID); task.Status != "backlog" {
+		t.Errorf("expected task to stay in backlog, got %q", task.Status)
+	}
+}
+
+func TestRunWorker_UpstreamDeleteCancelsAgent(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"fake":{"sleep":"10s"}}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{})
+
+	h.waitForStatus(t, tasks[0].ID, "in_progress")
+	h.waitForAgent(t, tasks[0].ID, true)
+	h.waitForEvents(t)
+	if err := client.NewClient(baseURL).DeleteTask(tasks[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	h.waitForAgent(t, tasks[0].ID, false)
+}
+
+func TestWatchRunningTasks_ResyncsAfterLag(t *testing.T) {
+	flux := fluxtest.New()
+	server := httptest.NewServer(flux)
+	defer server.Close()
+	tasks := seedAutoTasks(flux, 3)
+	c := client.NewClient(server.URL)
+
+	for _, task := range tasks {
+		if _, err := c.MoveTaskStatus(task.ID, "in_progress"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Changed while the subscription was dropping events
+	if _, err := c.MoveTaskStatus(tasks[0].ID, "backlog"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteTask(tasks[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	model := ui.NewModel("test", ui.ExecutionModeAsync, ".", nil, nil, nil)
+	p := tea.NewProgram(&model, tea.WithInput(nil), tea.WithOutput(io.Discard), tea.WithoutRenderer())
+	programDone := make(chan struct{})
+	go func() {
+		p.Run()
+		close(programDone)
+	}()
+	defer func() {
+		p.Quit()
+		<-programDone
+	}()
+
+	agents := newRunningAgents()
+	for _, task := range tasks {
+		agents.markRunning(task.ID, nil)
+	}
+	wf := workflow.NewWorkflow(c)
+	wf.SetOutput(nil)
+	events := make(chan sse.TypedEvent, 1)
+	events <- sse.TypedEvent{Event: sse.Event{Type: sse.EventLagged}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watchRunningTasks(ctx, p, events, c, agents, wf, config.RepoConfig{}, ownStatuses(config.RepoConfig{}))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !agents.wasCancelledUpstream(tasks[0].ID) || !agents.wasCancelledUpstream(tasks[1].ID) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the moved and deleted tasks' agents to be cancelled")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if agents.wasCancelledUpstream(tasks[2].ID) {
+		t.Error("expected the untouched task's agent to keep running")
+	}
+}
+
+func TestRunWorker_RestartOnChange(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"fake":{"sleep":"10s"}}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{RestartOnChange: true})
+
+	h.waitForStatus(t, tasks[0].ID, "in_progress")
+	h.waitForAgent(t, tasks[0].ID, true)
+	h.agents.mu.Lock()
+	first := h.agents.runners[tasks[0].ID]
+	h.agents.mu.Unlock()
+	h.waitForEvents(t)
+
+	notes := "Also update the docs"
+	if _, err := client.NewClient(baseURL).UpdateTask(tasks[0].ID, client.TaskUpdate{Notes: &notes}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h.agents.mu.Lock()
+		runner, prompt := h.agents.runners[tasks[0].ID], h.agents.prompts[tasks[0].ID]
+		h.agents.mu.Unlock()
+		if runner != nil && runner != first && strings.Contains(prompt, notes) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected agent to restart with the updated prompt")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	h.waitForStatus(t, tasks[0].ID, "in_progress")
+}
+
+func TestRunWorker_SyncModeRunsOneAtATime(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 2)
+	script := writeScript(t, `{"fake":{"sleep":"300ms"}}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeSync, "fake:"+script, config.RepoConfig{})
+
+	// Newer task is selected first; the other must wait in todo.
+	h.waitForStatus(t, tasks[1].ID, "in_progress")
+	if task, _ := flux.Task(tasks[0].ID); task.Status != "todo" {
+		t.Errorf("expected second task to wait in todo, got %q", task.Status)
+	}
+
+	h.waitForStatus(t, tasks[1].ID, "done")
+	h.waitForStatus(t, tasks[0].ID, "done")
+}
+
+func TestRunWorker_TimeoutMarksTimedOut(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"fake":{"sleep":"10s"}}`)
+	repoCfg := config.RepoConfig{
+		Timeout:  200 * time.Millisecond,
+		Statuses: config.StatusConfig{TimedOut: "timed_out"},
+	}
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, repoCfg)
+
+	h.waitForStatus(t, tasks[0].ID, "timed_out")
+	h.waitForRelease(t, tasks[0].ID)
+}
+
+func TestRunWorker_RetriesAfterFailedRun(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"fake":{"exit":1}}`)
+	repoCfg := config.RepoConfig{Statuses: config.StatusConfig{Failed: "failed"}}
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, repoCfg)
+
+	h.waitForStatus(t, tasks[0].ID, "failed")
+	h.waitForNotes(t, tasks[0].ID, "Last run failed (exit 1)")
+	h.waitForRelease(t, tasks[0].ID)
+
+	// Someone fixes the cause and sends the task back to be picked up
+	if err := os.WriteFile(script, []byte(`{"type":"result","result":"ok"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewClient(baseURL).MoveTaskStatus(tasks[0].ID, "todo"); err != nil {
+		t.Fatal(err)
+	}
+
+	h.waitForStatus(t, tasks[0].ID, "done")
+	h.waitForNotes(t, tasks[0].ID, "Last run succeeded")
+}
+
+func TestRunWorker_SwitchToAsyncStartsQueuedTasks(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 3)
+	script := writeScript(t, `{"fake":{"sleep":"3s"}}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeSync, "fake:"+script, config.RepoConfig{})
+
+	// The newest task runs; the others are queued behind it
+	h.waitForAgent(t, tasks[2].ID, true)
+	time.Sleep(600 * time.Millisecond)
+	for _, task := range tasks[:2] {
+		if h.agents.isRunning(task.ID) {
+			t.Fatalf("expected task %s to wait in sync mode", task.ID)
+		}
+	}
+
+	h.modes <- ui.ExecutionModeAsync
+	for _, task := range tasks[:2] {
+		h.waitForAgent(t, task.ID, true)
+	}
+	if !h.agents.isRunning(tasks[2].ID) {
+		t.Error("expected the queued tasks to start alongside the running one")
+	}
+}
+
+func TestResolveStrategy(t *testing.T) {
+	old := selectionStrategy
+	defer func() { selectionStrategy = old }()
+
+	selectionStrategy = ""
+	s, err := resolveStrategy(config.RepoConfig{})
+	if err != nil || s.Name() != "newest" {
+		t.Errorf("expected default newest, got %v, %v", s, err)
+	}
+
+	s, err = resolveStrategy(config.RepoConfig{Selection: config.SelectionConfig{Strategy: "oldest"}})
+	if err != nil || s.Name() != "oldest" {
+		t.Errorf("expected config strategy oldest, got %v, %v", s, err)
+	}
+
+	selectionStrategy = "priority"
+	s, err = resolveStrategy(config.RepoConfig{Selection: config.SelectionConfig{Strategy: "oldest"}})
+	if err != nil || s.Name() != "priority" {
+		t.Errorf("expected flag to override config, got %v, %v", s, err)
+	}
+
+	selectionStrategy = "bogus"
+	if _, err := resolveStrategy(config.RepoConfig{}); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestResolveStrategy_TaskWeight(t *testing.T) {
+	oldStrategy, oldWeight := selectionStrategy, taskWeight
+	defer func() { selectionStrategy, taskWeight = oldStrategy, oldWeight }()
+
+	selectionStrategy, taskWeight = "critical-path", ""
+	repoCfg := config.RepoConfig{Selection: config.SelectionConfig{TaskWeight: "estimate"}}
+	s, err := resolveStrategy(repoCfg)
+	if err != nil || s.Name() != "critical-path" {
+		t.Fatalf("expected critical-path, got %v, %v", s, err)
+	}
+	if s == selection.CriticalPath {
+		t.Error("expected configured task weight to replace the default critical-path strategy")
+	}
+
+	taskWeight = "heavy"
+	if _, err := resolveStrategy(repoCfg); err == nil {
+		t.Error("expected error for unknown task weight")
+	}
+}
+
+func TestDescribeFairness(t *testing.T) {
+	tests := []struct {
+		mode    selection.Fairness
+		weights map[string]int
+		want    string
+	}{
+		{selection.FairnessNone, nil, ""},
+		{selection.FairnessRoundRobin, nil, "round-robin"},
+		{selection.FairnessWeighted, nil, "weighted"},
+		{selection.FairnessWeighted, map[string]int{"b": 1, "a": 3}, "weighted (a=3, b=1)"},
+	}
+
+	for _, tt := range tests {
+		if got := describeFairness(tt.mode, tt.weights); got != tt.want {
+			t.Errorf("describeFairness(%q, %v) = %q, want %q", tt.mode, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestResolveFilter_FlagsReplaceConfig(t *testing.T) {
+	oldLabels, oldExclude := labelFilters, excludeLabelFilters
+	defer func() { labelFilters, excludeLabelFilters = oldLabels, oldExclude }()
+
+	repoCfg := config.RepoConfig{Selection: config.SelectionConfig{
+		Labels:        []string{"backend"},
+		ExcludeLabels: []string{"wip"},
+	}}
+
+	labelFilters = []string{"frontend"}
+	excludeLabelFilters = nil
+
+	f, err := resolveFilter(repoCfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.Labels) != 1 || f.Labels[0] != "frontend" {
+		t.Errorf("expected flag labels to replace config, got %v", f.Labels)
+	}
+	if len(f.ExcludeLabels) != 1 || f.ExcludeLabels[0] != "wip" {
+		t.Errorf("expected config exclude labels to be kept, got %v", f.ExcludeLabels)
+	}
+}
+
+func TestResumeID_RoundTrip(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	if got := loadResumeID("http://flux.test"); got != "" {
+		t.Fatalf("loadResumeID() before save = %q, want empty", got)
+	}
+	saveResumeID("http://flux.test", "42")
+	if got := loadResumeID("http://flux.test"); got != "42" {
+		t.Errorf("loadResumeID() = %q, want %q", got, "42")
+	}
+	if got := loadResumeID("http://other.test"); got != "" {
+		t.Errorf("loadResumeID() for another server = %q, want empty", got)
+	}
+}
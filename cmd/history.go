@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+var (
+	historyTaskID string
+	historyLog    string
+	historyFormat string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the status changes momentum made to tasks",
+	Long: `Show the status changes recorded in momentum's audit log.
+
+Every transition momentum makes is appended to the log with the task, the
+old and new status, the reason (claimed, agent success, agent failure, user
+stop, timeout, reconcile), the user momentum ran as and the instance that
+made it. The log is read from audit_log in .momentum.yaml, or
+momentum/audit.jsonl under the user cache directory.
+
+Examples:
+  momentum history --task task-789
+  momentum history --format json | jq .`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHistory(os.Stdout, historyTaskID, historyLog, historyFormat)
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyTaskID, "task", "", "Only show transitions of this task")
+	historyCmd.Flags().StringVar(&historyLog, "log", "", "Audit log to read (default from .momentum.yaml)")
+	historyCmd.Flags().StringVar(&historyFormat, "format", "text", "Output format: text or json")
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(w io.Writer, taskID, path, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid format %q (use text or json)", format)
+	}
+
+	if path == "" {
+		InitWorkDir()
+		resolved, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		repoCfg := resolved.Config
+		if path, err = auditLogPath(repoCfg); err != nil {
+			return err
+		}
+	}
+
+	history, err := workflow.ReadHistory(path, taskID)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		for _, t := range history {
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(history) == 0 {
+		_, err := fmt.Fprintf(w, "No transitions recorded in %s\n", path)
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tTASK\tFROM\tTO\tREASON\tACTOR\tINSTANCE")
+	for _, t := range history {
+		from := t.From
+		if from == "" {
+			from = "?"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Time.Local().Format(time.DateTime), t.TaskID, from, t.To, t.Reason, t.Actor, t.Instance)
+	}
+	return tw.Flush()
+}
+
+// auditLogPath returns the audit log configured in .momentum.yaml, relative
+// to the workdir, or the default location.
+func auditLogPath(repoCfg config.RepoConfig) (string, error) {
+	if path := repoCfg.AuditLog; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(GetWorkDir(), path)
+		}
+		return path, nil
+	}
+	path, err := workflow.DefaultAuditLogPath()
+	if err != nil {
+		return "", fmt.Errorf("locating audit log: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/fluxtest"
+	"github.com/stephenmfriend/momentum/ui"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+func TestRunHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := workflow.NewAuditLog(path)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	log.Append(workflow.Transition{Time: at, TaskID: "task-1", From: "todo", To: "in_progress", Reason: workflow.ReasonClaimed, Actor: "sam", Instance: "claude@box:7"})
+	log.Append(workflow.Transition{Time: at, TaskID: "task-2", From: "todo", To: "in_progress", Reason: workflow.ReasonClaimed})
+	log.Append(workflow.Transition{Time: at, TaskID: "task-1", From: "in_progress", To: "planning", Reason: workflow.ReasonStopped})
+
+	var out bytes.Buffer
+	if err := runHistory(&out, "task-1", path, "text"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "TIME") {
+		t.Fatalf("output:\n%s", out.String())
+	}
+	for _, want := range []string{"todo", "claimed", "sam", "claude@box:7"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("line %q missing %q", lines[1], want)
+		}
+	}
+	if !strings.Contains(lines[2], "user stop") || strings.Contains(out.String(), "task-2") {
+		t.Errorf("output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runHistory(&out, "", path, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 3 {
+		t.Errorf("json output has %d lines, want 3:\n%s", n, out.String())
+	}
+
+	if err := runHistory(&out, "", path, "yaml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestRunWorker_WritesAuditLog(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"type":"result","result":"ok"}`)
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{AuditLog: path})
+
+	h.waitForStatus(t, tasks[0].ID, "done")
+	history, err := workflow.ReadHistory(path, tasks[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tr := range history {
+		got = append(got, string(tr.Reason)+":"+tr.From+">"+tr.To)
+	}
+	want := "claimed:todo>in_progress agent success:in_progress>done"
+	if strings.Join(got, " ") != want {
+		t.Errorf("history = %q, want %q", got, want)
+	}
+}
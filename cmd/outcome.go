@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stephenmfriend/momentum/agent"
+	"github.com/stephenmfriend/momentum/git"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+// gitReportTimeout bounds the git commands run to report on a finished
+// agent, which may run after momentum itself was asked to stop.
+const gitReportTimeout = 30 * time.Second
+
+// gitHead returns the commit HEAD points at in dir, or "" when dir is not
+// a git repository. It is recorded when an agent starts so the commits it
+// makes can be listed afterwards.
+func gitHead(ctx context.Context, dir string) string {
+	repo, err := git.Open(ctx, dir)
+	if err != nil {
+		return ""
+	}
+	head, _ := repo.Head(ctx)
+	return head
+}
+
+// describeEnd says how an agent run ended, for its outcome.
+func describeEnd(result agent.Result, stoppedByUser bool) string {
+	switch {
+	case stoppedByUser:
+		return "stopped by user"
+	case errors.Is(result.Error, agent.ErrAgentTimeout):
+		return "timed out"
+	case result.ExitCode == 0:
+		return "succeeded"
+	default:
+		return fmt.Sprintf("failed (exit %d)", result.ExitCode)
+	}
+}
+
+// gatherOutcome collects what momentum reports about a finished run: the
+// agent's final message and the state of the workdir, including commits
+// made on top of base.
+func gatherOutcome(ctx context.Context, dir, base, ended, final string, duration time.Duration) workflow.Outcome {
+	outcome := workflow.Outcome{
+		Ended:    ended,
+		Duration: duration,
+		Result:   lastBytes(final, maxSummaryOutput),
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gitReportTimeout)
+	defer cancel()
+	repo, err := git.Open(ctx, dir)
+	if err == nil {
+		outcome.GitStatus, err = repo.Status(ctx)
+	}
+	if err == nil {
+		outcome.DiffStat, err = repo.DiffStat(ctx)
+	}
+	if err == nil {
+		outcome.Commits, err = repo.CommitsSince(ctx, base)
+	}
+	if err != nil {
+		outcome.GitError = err.Error()
+	}
+	outcome.GitStatus = lastBytes(outcome.GitStatus, maxSummaryOutput)
+	outcome.DiffStat = lastBytes(outcome.DiffStat, maxSummaryOutput)
+	return outcome
+}
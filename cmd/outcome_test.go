@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stephenmfriend/momentum/agent"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/fluxtest"
+	"github.com/stephenmfriend/momentum/gittest"
+	"github.com/stephenmfriend/momentum/ui"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+func TestDescribeEnd(t *testing.T) {
+	tests := []struct {
+		result        agent.Result
+		stoppedByUser bool
+		want          string
+	}{
+		{agent.Result{}, false, "succeeded"},
+		{agent.Result{ExitCode: 2}, false, "failed (exit 2)"},
+		{agent.Result{ExitCode: -1, Error: agent.ErrAgentTimeout}, false, "timed out"},
+		{agent.Result{ExitCode: -1}, true, "stopped by user"},
+	}
+	for _, tt := range tests {
+		if got := describeEnd(tt.result, tt.stoppedByUser); got != tt.want {
+			t.Errorf("describeEnd(%+v, %v) = %q, want %q", tt.result, tt.stoppedByUser, got, tt.want)
+		}
+	}
+}
+
+func TestRunWorker_RecordsOutcome(t *testing.T) {
+	flux := fluxtest.New()
+	script := writeScript(t,
+		`{"type":"result","result":"Could not fix the flaky test"}`,
+		`{"fake":{"exit":1}}`,
+	)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{})
+
+	// The agent leaves an uncommitted change behind
+	gittest.Init(t, workDir)
+	if err := os.WriteFile(filepath.Join(workDir, "README.md"), []byte("hello\nworld\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tasks := seedAutoTasks(flux, 1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		task, _ := flux.Task(tasks[0].ID)
+		if len(task.Comments) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected a completion comment")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	h.waitForStatus(t, tasks[0].ID, "in_progress")
+
+	task, _ := flux.Task(tasks[0].ID)
+	comment := task.Comments[0]
+	if comment.Author != workflow.CommentAuthor {
+		t.Errorf("comment author = %q, want %q", comment.Author, workflow.CommentAuthor)
+	}
+	for _, want := range []string{"Run failed (exit 1).", "Could not fix the flaky test", " M README.md", "1 file changed"} {
+		if !strings.Contains(comment.Body, want) {
+			t.Errorf("comment missing %q:\n%s", want, comment.Body)
+		}
+	}
+
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		task, _ = flux.Task(tasks[0].ID)
+		if strings.Contains(task.Notes, "Last run failed (exit 1)") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the outcome in the notes, got %q", task.Notes)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
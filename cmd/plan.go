@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/selection"
+	"github.com/stephenmfriend/momentum/ui"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show which tasks momentum would run, without running them",
+	Long: `Run task selection against the live board without spawning agents or
+changing any task status.
+
+Prints the order tasks would be started in, every todo task that would be
+skipped and why, and the exact prompt each agent would receive. Accepts the
+same selection flags as momentum itself; momentum --dry-run is equivalent.
+
+Examples:
+  # Check an epic before turning on auto for it
+  momentum plan --epic epic-456
+
+  # Preview sync mode with the critical-path strategy
+  momentum plan --project myproject --execution-mode sync --selection-strategy critical-path`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPlan(os.Stdout)
+	},
+}
+
+func init() {
+	addSelectionFlags(planCmd)
+	rootCmd.AddCommand(planCmd)
+}
+
+// plan is the outcome of a simulated scheduling pass.
+type plan struct {
+	criteria string
+	strategy string
+	mode     ui.ExecutionMode
+	queue    []client.Task
+	skipped  []selection.Evaluation
+}
+
+func runPlan(w io.Writer) error {
+	InitWorkDir()
+
+	resolved, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	repoCfg := resolved.Config
+
+	mode, err := parseExecutionMode(executionMode)
+	if err != nil {
+		return err
+	}
+
+	c := client.NewClient(GetBaseURL())
+	selector := selection.NewWatchSelector(c, resolveWatch(repoCfg))
+	if err := configureSelector(selector, repoCfg); err != nil {
+		return err
+	}
+
+	p, err := buildPlan(selector, mode)
+	if err != nil {
+		return err
+	}
+	filter, err := resolveFilter(repoCfg)
+	if err != nil {
+		return err
+	}
+	p.criteria = buildCriteriaString(repoCfg)
+	if desc := filter.String(); desc != "" {
+		p.criteria += " · " + desc
+	}
+
+	return writePlan(w, p, repoCfg)
+}
+
+// buildPlan simulates the worker: it orders the tasks that would be handed
+// to agents one after another until nothing is left. Nothing is written to
+// Flux.
+func buildPlan(selector *selection.Selector, mode ui.ExecutionMode) (plan, error) {
+	p := plan{strategy: selector.Strategy().Name(), mode: mode}
+
+	queue, evals, err := selector.Queue()
+	if err != nil && !errors.Is(err, selection.ErrNoTaskAvailable) {
+		return plan{}, err
+	}
+	p.queue = queue
+
+	queued := make(map[string]bool)
+	for _, task := range queue {
+		queued[task.ID] = true
+	}
+	for _, eval := range evals {
+		// Only pending work counts as skipped; tasks outside the watched
+		// targets are out of scope rather than skipped
+		if eval.Eligible() || queued[eval.Task.ID] || eval.Task.Status != selector.Statuses().Queued || eval.Has(selection.ReasonNotWatched) {
+			continue
+		}
+		p.skipped = append(p.skipped, eval)
+	}
+	return p, nil
+}
+
+func writePlan(w io.Writer, p plan, repoCfg config.RepoConfig) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Plan for %s · strategy: %s · mode: %s\n", p.criteria, p.strategy, p.mode)
+	b.WriteString("Dry run: no agents started, no task statuses changed.\n")
+
+	fmt.Fprintf(&b, "\nQueue (%d):\n", len(p.queue))
+	if len(p.queue) == 0 {
+		b.WriteString("  nothing to run\n")
+	}
+	for i, task := range p.queue {
+		when := "starts now"
+		if p.mode == ui.ExecutionModeSync && i > 0 {
+			when = "waits: concurrency cap (sync mode runs one task at a time)"
+		}
+		fmt.Fprintf(&b, "  %d. %s %q · %s\n", i+1, task.ID, task.Title, when)
+	}
+
+	if len(p.skipped) > 0 {
+		fmt.Fprintf(&b, "\nSkipped (%d):\n", len(p.skipped))
+		for _, eval := range p.skipped {
+			fmt.Fprintf(&b, "  %s %q\n", eval.Task.ID, eval.Task.Title)
+			for _, reason := range eval.Reasons {
+				fmt.Fprintf(&b, "    - %s\n", reason)
+			}
+		}
+	}
+
+	for _, task := range p.queue {
+		fmt.Fprintf(&b, "\nPrompt for %s:\n", task.ID)
+		b.WriteString("----\n")
+		b.WriteString(buildHeadlessPrompt(&task, repoCfg))
+		b.WriteString("----\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
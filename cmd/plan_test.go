@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/fluxtest"
+)
+
+// setupPlanTest serves a seeded fake Flux board and points the selection
+// globals at it, restoring them afterwards.
+func setupPlanTest(t *testing.T) *fluxtest.Server {
+	t.Helper()
+	flux := fluxtest.New()
+	flux.Seed()
+	server := httptest.NewServer(flux)
+
+	oldBaseURL, oldWorkDir, oldMode := baseURL, workDir, executionMode
+	oldTaskIDs, oldEpicIDs, oldProjectIDs := taskIDs, epicIDs, projectIDs
+	oldExclude := excludeLabelFilters
+	baseURL, workDir, executionMode = server.URL, t.TempDir(), "async"
+	taskIDs, epicIDs, projectIDs = nil, nil, nil
+	excludeLabelFilters = nil
+
+	t.Cleanup(func() {
+		flux.Close()
+		server.Close()
+		baseURL, workDir, executionMode = oldBaseURL, oldWorkDir, oldMode
+		taskIDs, epicIDs, projectIDs = oldTaskIDs, oldEpicIDs, oldProjectIDs
+		excludeLabelFilters = oldExclude
+	})
+	return flux
+}
+
+func TestRunPlan(t *testing.T) {
+	flux := setupPlanTest(t)
+	project := flux.AddProject("Extra", "")
+	epic := flux.AddEpic(project.ID, "Extra epic", true)
+	flux.AddTask(client.Task{ProjectID: project.ID, EpicID: epic.ID, Title: "Later", Labels: []string{"wip"}})
+	flux.AddTask(client.Task{ProjectID: project.ID, EpicID: epic.ID, Title: "Sooner"})
+	excludeLabelFilters = []string{"wip"}
+
+	var out strings.Builder
+	if err := runPlan(&out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		"Queue (2):",
+		`"Sooner" · starts now`,
+		`"Add welcome screen" · starts now`,
+		"Skipped (3):",
+		"has auto=false",
+		"waiting on task-",
+		"has excluded label wip",
+		"Prompt for task-",
+		"- Task: Add welcome screen",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected plan to contain %q:\n%s", want, got)
+		}
+	}
+
+	// Planning must not touch the board
+	for _, task := range flux.Tasks(project.ID) {
+		if task.Status != "todo" {
+			t.Errorf("task %s moved to %s during plan", task.ID, task.Status)
+		}
+	}
+}
+
+func TestRunPlan_SyncModeConcurrencyCap(t *testing.T) {
+	flux := setupPlanTest(t)
+	project := flux.AddProject("Extra", "")
+	epic := flux.AddEpic(project.ID, "Extra epic", true)
+	flux.AddTask(client.Task{ProjectID: project.ID, EpicID: epic.ID, Title: "One"})
+	flux.AddTask(client.Task{ProjectID: project.ID, EpicID: epic.ID, Title: "Two"})
+	executionMode = "sync"
+	projectIDs = []string{project.ID}
+
+	var out strings.Builder
+	if err := runPlan(&out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+
+	if strings.Count(got, "starts now") != 1 || !strings.Contains(got, "waits: concurrency cap") {
+		t.Errorf("expected one task to start and one to wait:\n%s", got)
+	}
+	if strings.Contains(got, "Add welcome screen") {
+		t.Errorf("expected tasks outside the watched project to be left out:\n%s", got)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build windows
+
+package cmd
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	// processQueryLimitedInformation is enough to read a process's exit
+	// code, and is granted for more processes than PROCESS_QUERY_INFORMATION
+	processQueryLimitedInformation = 0x1000
+	// stillActive is the exit code of a process that has not exited
+	stillActive = 259
+)
+
+// processAlive reports whether a process with the given PID exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// Another user's process cannot be opened but is still running
+		return errors.Is(err, syscall.ERROR_ACCESS_DENIED)
+	}
+	defer syscall.CloseHandle(h)
+	// A handle can outlive the process, so check that it has not exited
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+// ownership is what an instance records about the tasks it holds, so a
+// later run can find them if this one dies.
+type ownership struct {
+	Instance string `json:"instance"`
+	Host     string `json:"host"`
+	PID      int    `json:"pid"`
+	// Started tells apart instances that had the same PID
+	Started time.Time `json:"started"`
+	// Tasks maps the IDs of held tasks to when they were claimed
+	Tasks map[string]time.Time `json:"tasks"`
+}
+
+// ownedTasks keeps this instance's ownership record on disk. Its methods
+// are safe on a nil receiver, which records nothing.
+type ownedTasks struct {
+	mu    sync.Mutex
+	path  string
+	state ownership
+}
+
+// ownershipDir returns where instances talking to a Flux server keep their
+// ownership records, or "" when no cache directory is available.
+func ownershipDir(baseURL string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(baseURL))
+	return filepath.Join(dir, "momentum", "owned-"+hex.EncodeToString(sum[:6]))
+}
+
+// newOwnedTasks returns the ownership record for this process.
+func newOwnedTasks(baseURL, instance string) *ownedTasks {
+	dir := ownershipDir(baseURL)
+	if dir == "" {
+		return nil
+	}
+	host, _ := os.Hostname()
+	state := ownership{
+		Instance: instance,
+		Host:     host,
+		PID:      os.Getpid(),
+		Started:  time.Now().UTC(),
+		Tasks:    make(map[string]time.Time),
+	}
+	return &ownedTasks{
+		path:  filepath.Join(dir, recordName(state)),
+		state: state,
+	}
+}
+
+// recordName names the file an ownership record is kept in. The start time
+// keeps a new process from taking over the record of a dead one that had
+// the same PID.
+func recordName(record ownership) string {
+	return strconv.Itoa(record.PID) + "-" + strconv.FormatInt(record.Started.UnixNano(), 10) + ".json"
+}
+
+// recordPath returns where the record is kept, or "" for a nil record.
+func (o *ownedTasks) recordPath() string {
+	if o == nil {
+		return ""
+	}
+	return o.path
+}
+
+// hold records that this instance holds a task.
+func (o *ownedTasks) hold(taskID string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.state.Tasks[taskID] = time.Now().UTC()
+	o.save()
+}
+
+// release forgets a task once momentum has settled its status.
+func (o *ownedTasks) release(taskID string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.state.Tasks[taskID]; !ok {
+		return
+	}
+	delete(o.state.Tasks, taskID)
+	o.save()
+}
+
+// save writes the record, or removes the file when nothing is held.
+// Failures are ignored; they only leave the task out of crash recovery.
+// Callers must hold o.mu.
+func (o *ownedTasks) save() {
+	if len(o.state.Tasks) == 0 {
+		os.Remove(o.path)
+		return
+	}
+	data, err := json.MarshalIndent(o.state, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o755); err != nil {
+		return
+	}
+	tmp := o.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	os.Rename(tmp, o.path)
+}
+
+// orphan is a task held by a momentum process that is no longer running.
+type orphan struct {
+	taskID   string
+	instance string
+}
+
+// findOrphans lists tasks held by dead momentum processes on this host,
+// from the ownership records they left behind in dir. own is this
+// process's record, which is skipped. The records are returned so they can
+// be removed once handled.
+func findOrphans(dir, own string) ([]orphan, []string) {
+	host, _ := os.Hostname()
+	var orphans []orphan
+	var records []string
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range paths {
+		if path == own {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var record ownership
+		if err := json.Unmarshal(data, &record); err != nil {
+			continue
+		}
+		// Records from other machines sharing the cache directory cannot
+		// be checked, so they are left to their owners. A record with this
+		// process's PID that is not its own was left by a dead process
+		// whose PID has been reused.
+		if record.Host != host || (record.PID != os.Getpid() && processAlive(record.PID)) {
+			continue
+		}
+		records = append(records, path)
+		for _, taskID := range slices.Sorted(maps.Keys(record.Tasks)) {
+			orphans = append(orphans, orphan{taskID: taskID, instance: record.Instance})
+		}
+	}
+	return orphans, records
+}
+
+// recoverOrphans resets tasks left in progress by dead momentum processes
+// according to the recovery policy, and returns a description of each one
+// it found. owned is this process's own record, if any. A task is only touched while it is still in the picked-up
+// status and its agent_name in Flux still names the dead process (or no
+// one); otherwise someone has taken it over since.
+func recoverOrphans(c *client.Client, wf *workflow.Workflow, repoCfg config.RepoConfig, baseURL string, owned *ownedTasks) ([]string, error) {
+	dir := ownershipDir(baseURL)
+	if dir == "" {
+		return nil, nil
+	}
+	pickedUp := wf.Statuses().PickedUp
+	orphans, records := findOrphans(dir, owned.recordPath())
+
+	var report []string
+	var errs []error
+	for _, o := range orphans {
+		task, err := c.GetTask(o.taskID)
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if task.Status != pickedUp || (task.AgentName != "" && task.AgentName != o.instance) {
+			continue
+		}
+
+		var resetErr error
+		action := "left in " + task.Status
+		switch repoCfg.Recovery {
+		case "", "todo":
+			status := wf.Statuses().Queued
+			resetErr = wf.RecoverOrphan(task.ID, status)
+			action = "reset to " + status
+		case "planning":
+			status := wf.Statuses().Planning
+			resetErr = wf.RecoverOrphan(task.ID, status)
+			action = "reset to " + status
+		}
+		if resetErr != nil {
+			errs = append(errs, resetErr)
+			continue
+		}
+		report = append(report, fmt.Sprintf("%s (%s) %s, held by %s which is no longer running", task.ID, task.Title, action, o.instance))
+	}
+
+	// Keep the records if anything went wrong so the next start retries
+	if len(errs) > 0 {
+		return report, errors.Join(errs...)
+	}
+	for _, path := range records {
+		os.Remove(path)
+	}
+	return report, nil
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/fluxtest"
+	"github.com/stephenmfriend/momentum/ui"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+// deadPID is a process ID no running process has.
+const deadPID = 1 << 30
+
+func TestOwnedTasks_HoldRelease(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	owned := newOwnedTasks("http://flux.test", "claude@host:1")
+	owned.hold("task-1")
+	owned.hold("task-2")
+
+	data, err := os.ReadFile(owned.path)
+	if err != nil {
+		t.Fatalf("ownership record not written: %v", err)
+	}
+	var record ownership
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Instance != "claude@host:1" || record.PID != os.Getpid() || len(record.Tasks) != 2 {
+		t.Errorf("record = %+v", record)
+	}
+
+	owned.release("task-1")
+	owned.release("task-2")
+	if _, err := os.Stat(owned.path); !os.IsNotExist(err) {
+		t.Errorf("expected record to be removed once empty, got %v", err)
+	}
+
+	// A nil record is a no-op
+	var none *ownedTasks
+	none.hold("task-1")
+	none.release("task-1")
+}
+
+func TestRecoverOrphans(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{"", "todo"},
+		{"planning", "planning"},
+		{"leave", "in_progress"},
+	}
+	for _, tt := range tests {
+		t.Run("policy "+tt.want, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			flux := fluxtest.New()
+			server := httptest.NewServer(flux)
+			defer server.Close()
+			c := client.NewClient(server.URL)
+
+			dead := "claude@" + hostname(t) + ":" + strconv.Itoa(deadPID)
+			project := flux.AddProject("Proj", "")
+			orphaned := flux.AddTask(client.Task{Title: "Orphaned", ProjectID: project.ID, Status: "in_progress", AgentName: dead})
+			takenOver := flux.AddTask(client.Task{Title: "Taken over", ProjectID: project.ID, Status: "in_progress", AgentName: "claude@elsewhere:7"})
+			finished := flux.AddTask(client.Task{Title: "Finished", ProjectID: project.ID, Status: "done", AgentName: dead})
+			live := flux.AddTask(client.Task{Title: "Live", ProjectID: project.ID, Status: "in_progress"})
+			mine := flux.AddTask(client.Task{Title: "Mine", ProjectID: project.ID, Status: "in_progress"})
+			// A dead instance whose PID this process now has
+			reusedBy := "claude@" + hostname(t) + ":" + strconv.Itoa(os.Getpid())
+			reused := flux.AddTask(client.Task{Title: "Reused", ProjectID: project.ID, Status: "in_progress", AgentName: reusedBy})
+
+			writeOwnership(t, server.URL, ownership{
+				Instance: dead,
+				Host:     hostname(t),
+				PID:      deadPID,
+				Tasks:    map[string]time.Time{orphaned.ID: time.Now(), takenOver.ID: time.Now(), finished.ID: time.Now(), "task-gone": time.Now()},
+			})
+			livePath := writeOwnership(t, server.URL, ownership{
+				Instance: "claude@" + hostname(t) + ":1",
+				Host:     hostname(t),
+				PID:      os.Getppid(),
+				Tasks:    map[string]time.Time{live.ID: time.Now()},
+			})
+			writeOwnership(t, server.URL, ownership{
+				Instance: reusedBy,
+				Host:     hostname(t),
+				PID:      os.Getpid(),
+				Started:  time.Now().Add(-time.Hour),
+				Tasks:    map[string]time.Time{reused.ID: time.Now()},
+			})
+			owned := newOwnedTasks(server.URL, "claude@"+hostname(t)+":me")
+			owned.hold(mine.ID)
+
+			wf := workflow.NewWorkflow(c)
+			wf.SetOutput(nil)
+			report, err := recoverOrphans(c, wf, config.RepoConfig{Recovery: tt.policy}, server.URL, owned)
+			if err != nil {
+				t.Fatalf("recoverOrphans() error = %v", err)
+			}
+
+			if len(report) != 2 || !strings.Contains(strings.Join(report, "\n"), orphaned.ID+" (Orphaned)") || !strings.Contains(strings.Join(report, "\n"), reused.ID+" (Reused)") {
+				t.Errorf("report = %v, want lines about %s and %s", report, orphaned.ID, reused.ID)
+			}
+			for id, want := range map[string]string{orphaned.ID: tt.want, reused.ID: tt.want, takenOver.ID: "in_progress", finished.ID: "done", live.ID: "in_progress", mine.ID: "in_progress"} {
+				if task, _ := flux.Task(id); task.Status != want {
+					t.Errorf("task %s status = %q, want %q", id, task.Status, want)
+				}
+			}
+
+			paths, _ := filepath.Glob(filepath.Join(ownershipDir(server.URL), "*.json"))
+			if len(paths) != 2 || !slices.Contains(paths, livePath) || !slices.Contains(paths, owned.path) {
+				t.Errorf("records left = %v, want only the live instances'", paths)
+			}
+		})
+	}
+}
+
+func hostname(t *testing.T) string {
+	t.Helper()
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname")
+	}
+	return host
+}
+
+func writeOwnership(t *testing.T, baseURL string, record ownership) string {
+	t.Helper()
+	dir := ownershipDir(baseURL)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, recordName(record))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunWorker_RecordsOwnership(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t, `{"fake":{"sleep":"500ms"}}`)
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, config.RepoConfig{})
+
+	h.waitForAgent(t, tasks[0].ID, true)
+	paths, _ := filepath.Glob(filepath.Join(ownershipDir(baseURL), strconv.Itoa(os.Getpid())+"-*.json"))
+	if len(paths) != 1 {
+		t.Fatalf("expected one ownership record for this process, got %v", paths)
+	}
+	path := paths[0]
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), tasks[0].ID) {
+		t.Fatalf("expected %s to record %s, got %q (%v)", path, tasks[0].ID, data, err)
+	}
+	if task, _ := flux.Task(tasks[0].ID); !strings.HasPrefix(task.AgentName, "fake@") {
+		t.Errorf("agent_name = %q, want this instance's name", task.AgentName)
+	}
+
+	h.waitForStatus(t, tasks[0].ID, "done")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the ownership record to be removed once the task was settled")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
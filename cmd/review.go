@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+// maxSummaryOutput caps each block of command output in a review summary.
+const maxSummaryOutput = 4000
+
+// finalMessage extracts the agent's closing message from a stream-json
+// line: the "result" of the result line, or the text of an assistant
+// message. It returns "" for anything else.
+func finalMessage(line string) string {
+	var msg struct {
+		Type    string `json:"type"`
+		Result  string `json:"result"`
+		Message struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(line), &msg); err != nil {
+		return ""
+	}
+	switch msg.Type {
+	case "result":
+		return strings.TrimSpace(msg.Result)
+	case "assistant":
+		var texts []string
+		for _, block := range msg.Message.Content {
+			if block.Type == "text" && strings.TrimSpace(block.Text) != "" {
+				texts = append(texts, strings.TrimSpace(block.Text))
+			}
+		}
+		return strings.Join(texts, "\n\n")
+	}
+	return ""
+}
+
+// runShell runs command through the platform shell in dir, with env added
+// to momentum's environment, and returns its combined output.
+func runShell(ctx context.Context, dir, command string, env ...string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// verify runs the review verify command in dir.
+func verify(ctx context.Context, dir, command string) *workflow.Verification {
+	out, err := runShell(ctx, dir, command)
+	v := &workflow.Verification{
+		Command: command,
+		Output:  lastBytes(strings.TrimSpace(out), maxSummaryOutput),
+	}
+	if err != nil {
+		v.Failure = err.Error()
+	}
+	return v
+}
+
+// lastBytes keeps the end of s, where failures usually are, when it is
+// longer than n bytes.
+func lastBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	start := len(s) - n
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return "…" + s[start:]
+}
+
+// writeReviewFeedback appends comments left by a reviewer who sent the
+// task back, so the next attempt addresses them.
+func writeReviewFeedback(b *strings.Builder, task *client.Task) {
+	feedback := workflow.ReviewFeedback(*task)
+	if len(feedback) == 0 {
+		return
+	}
+	b.WriteString("\nReviewer feedback (a previous attempt was sent back; address this):\n")
+	for _, comment := range feedback {
+		fmt.Fprintf(b, "- %s\n", strings.TrimSpace(comment.Body))
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/config"
+	"github.com/stephenmfriend/momentum/fluxtest"
+	"github.com/stephenmfriend/momentum/ui"
+	"github.com/stephenmfriend/momentum/workflow"
+)
+
+func TestFinalMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"result", `{"type":"result","result":" Added the flag. "}`, "Added the flag."},
+		{"assistant text", `{"type":"assistant","message":{"content":[{"type":"text","text":"Done"},{"type":"tool_use","name":"Edit"}]}}`, "Done"},
+		{"tool only", `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Edit"}]}}`, ""},
+		{"other type", `{"type":"system"}`, ""},
+		{"not json", "plain output", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := finalMessage(tt.line); got != tt.want {
+				t.Errorf("finalMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastBytes(t *testing.T) {
+	if got := lastBytes("short", 10); got != "short" {
+		t.Errorf("lastBytes() = %q, want unchanged", got)
+	}
+	if got := lastBytes("abcdef", 3); got != "…def" {
+		t.Errorf("lastBytes() = %q, want …def", got)
+	}
+	// Never splits a multi-byte rune
+	if got := lastBytes("aé", 1); got != "…" {
+		t.Errorf("lastBytes() = %q, want …", got)
+	}
+}
+
+func TestBuildHeadlessPrompt_ReviewerFeedback(t *testing.T) {
+	task := &client.Task{
+		ID:    "task-123",
+		Title: "Fix the bug",
+		Comments: []client.Comment{
+			{Body: "Ready for review.", Author: workflow.CommentAuthor},
+			{Body: "Missing a regression test", Author: "user"},
+		},
+	}
+
+	result := buildHeadlessPrompt(task, config.RepoConfig{})
+
+	if !contains(result, "Reviewer feedback") || !contains(result, "- Missing a regression test") {
+		t.Errorf("prompt should contain reviewer feedback, got:\n%s", result)
+	}
+
+	task.Comments = task.Comments[:1]
+	if contains(buildHeadlessPrompt(task, config.RepoConfig{}), "Reviewer feedback") {
+		t.Error("prompt should not contain a feedback section without feedback")
+	}
+}
+
+func TestRunWorker_ReviewMode(t *testing.T) {
+	flux := fluxtest.New()
+	tasks := seedAutoTasks(flux, 1)
+	script := writeScript(t,
+		`{"fake":{"sleep":"500ms"}}`,
+		`{"type":"result","result":"Fixed the login redirect"}`,
+	)
+	repoCfg := config.RepoConfig{
+		Statuses: config.StatusConfig{Succeeded: "review"},
+		Review:   config.ReviewConfig{Enabled: true, Status: "review", Verify: "echo all tests passed"},
+	}
+
+	h := startWorkerHarness(t, flux, ui.ExecutionModeAsync, "fake:"+script, repoCfg)
+
+	h.waitForStatus(t, tasks[0].ID, "review")
+	task, _ := flux.Task(tasks[0].ID)
+	if len(task.Comments) != 1 {
+		t.Fatalf("expected one summary comment, got %+v", task.Comments)
+	}
+	summary := task.Comments[0]
+	if summary.Author != workflow.CommentAuthor {
+		t.Errorf("summary author = %q, want %q", summary.Author, workflow.CommentAuthor)
+	}
+	for _, want := range []string{"Fixed the login redirect", "Changed files:", "`echo all tests passed`) passed", "all tests passed"} {
+		if !strings.Contains(summary.Body, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary.Body)
+		}
+	}
+
+	// The reviewer sends it back with a comment
+	c := client.NewClient(baseURL)
+	if _, err := c.AddTaskComment(tasks[0].ID, "Please add a test", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.MoveTaskStatus(tasks[0].ID, "todo"); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h.agents.mu.Lock()
+		prompt := h.agents.prompts[tasks[0].ID]
+		h.agents.mu.Unlock()
+		if strings.Contains(prompt, "- Please add a test") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the task to re-run with the reviewer's comment in the prompt")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	h.waitForStatus(t, tasks[0].ID, "review")
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/stephenmfriend/momentum/config"
 	"github.com/stephenmfriend/momentum/version"
 )
 
@@ -15,6 +16,19 @@ var (
 	baseURL       string
 	executionMode string
 	workDir       string
+	agentSpec     string
+	dryRun        bool
+	// selectionStrategy overrides selection.strategy in .momentum.yaml
+	selectionStrategy string
+	// taskWeight overrides selection.task_weight in .momentum.yaml
+	taskWeight string
+	// fairness overrides selection.fairness in .momentum.yaml
+	fairness string
+	// Repeatable filter flags; each replaces its .momentum.yaml counterpart
+	labelFilters        []string
+	excludeLabelFilters []string
+	assigneeFilters     []string
+	matchFilters        []string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -37,9 +51,21 @@ Examples:
   # Work with a specific task
   momentum --task task-789
 
+  # Watch two projects and one extra epic at once
+  momentum --project web --project api --epic epic-456
+
+  # Only handle frontend tasks that are not work in progress
+  momentum --project myproject --label frontend --exclude-label wip
+
   # Use a custom Flux server URL
   momentum --base-url http://flux.example.com:3000 --project myproject`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		activeFlags = cmd.Flags()
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if dryRun {
+			return runPlan(os.Stdout)
+		}
 		return runHeadless()
 	},
 }
@@ -52,14 +78,53 @@ func Execute() error {
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", "http://localhost:3000", "Flux server base URL")
+	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", config.DefaultBaseURL, "Flux server base URL")
+	rootCmd.PersistentFlags().SetAnnotation("base-url", configKeyAnnotation, []string{"base_url"})
 
 	// Task selection flags (on root command now)
-	rootCmd.Flags().StringVar(&taskID, "task", "", "Specific task ID to work with")
-	rootCmd.Flags().StringVar(&epicID, "epic", "", "Filter tasks by epic ID")
-	rootCmd.Flags().StringVar(&projectID, "project", "", "Filter tasks by project ID")
-	rootCmd.Flags().StringVar(&executionMode, "execution-mode", "async", "Task execution mode: async or sync")
-	rootCmd.Flags().StringVar(&workDir, "workdir", "", "Working directory for agents (inherits CLAUDE.md)")
+	addSelectionFlags(rootCmd)
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what momentum would do (same as momentum plan) and exit")
+	addAgentFlag(rootCmd)
+}
+
+// addAgentFlag registers the flag choosing the agent to run tasks with.
+func addAgentFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&agentSpec, "agent", "claude", "Agent to run tasks with: claude, or fake:<script.jsonl> for scripted runs")
+	cmd.Flags().SetAnnotation("agent", configKeyAnnotation, []string{"agent"})
+}
+
+// addSelectionFlags registers the flags that decide which tasks are picked
+// and in what order. They are shared by the root command and plan.
+func addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&taskIDs, "task", nil, "Specific task ID to work with (repeatable)")
+	cmd.Flags().StringArrayVar(&epicIDs, "epic", nil, "Filter tasks by epic ID (repeatable)")
+	cmd.Flags().StringArrayVar(&projectIDs, "project", nil, "Filter tasks by project ID (repeatable)")
+	cmd.Flags().StringVar(&executionMode, "execution-mode", "async", "Task execution mode: async or sync")
+	cmd.Flags().StringVar(&workDir, "workdir", "", "Working directory for agents (inherits CLAUDE.md)")
+	cmd.Flags().StringVar(&selectionStrategy, "selection-strategy", "", "Task ordering: newest (default), oldest, priority, due-date, or critical-path")
+	cmd.Flags().StringVar(&taskWeight, "task-weight", "", "Task weight for critical-path: count (default), notes, or estimate")
+	cmd.Flags().StringVar(&fairness, "fairness", "", "Share agents across projects: none (default), round-robin, or weighted")
+	cmd.Flags().StringArrayVar(&labelFilters, "label", nil, "Only pick tasks with this label (repeatable)")
+	cmd.Flags().StringArrayVar(&excludeLabelFilters, "exclude-label", nil, "Skip tasks with this label (repeatable)")
+	cmd.Flags().StringArrayVar(&assigneeFilters, "assignee", nil, "Only pick tasks assigned to this user (repeatable)")
+	cmd.Flags().StringArrayVar(&matchFilters, "match", nil, "Only pick tasks whose title or notes match this regex (repeatable)")
+
+	// Flags that override a setting are applied as the top config layer
+	for name, key := range map[string]string{
+		"task":               "watch",
+		"epic":               "watch",
+		"project":            "watch",
+		"execution-mode":     "execution_mode",
+		"selection-strategy": "selection.strategy",
+		"task-weight":        "selection.task_weight",
+		"fairness":           "selection.fairness",
+		"label":              "selection.labels",
+		"exclude-label":      "selection.exclude_labels",
+		"assignee":           "selection.assignees",
+		"match":              "selection.match",
+	} {
+		cmd.Flags().SetAnnotation(name, configKeyAnnotation, []string{key})
+	}
 }
 
 // GetBaseURL returns the configured base URL for the Flux server
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/selection"
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why <task-id>",
+	Short: "Explain why a task is or is not being picked up",
+	Long: `Explain why momentum would or would not pick up a task.
+
+Every rejection reason is listed: the epic has auto=false, the status is
+not todo, the task is blocked or waiting on dependencies, it has no epic,
+or it falls outside the watched targets or the label, assignee and match
+filters. Accepts the same selection flags as momentum itself.
+
+Whether a task is already queued or running is only known to a running
+momentum; press ? in the TUI to include that.
+
+Examples:
+  momentum why task-789
+  momentum why task-789 --project myproject --label frontend`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWhy(os.Stdout, args[0])
+	},
+}
+
+func init() {
+	addSelectionFlags(whyCmd)
+	rootCmd.AddCommand(whyCmd)
+}
+
+func runWhy(w io.Writer, id string) error {
+	InitWorkDir()
+
+	resolved, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	repoCfg := resolved.Config
+
+	c := client.NewClient(GetBaseURL())
+	selector := selection.NewWatchSelector(c, resolveWatch(repoCfg))
+	if err := configureSelector(selector, repoCfg); err != nil {
+		return err
+	}
+
+	eval, err := selector.Explain(id, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, strings.Join(describeEvaluation(eval), "\n")+"\n")
+	return err
+}
+
+// describeEvaluation formats a selector verdict as display lines, shared by
+// momentum why and the TUI lookup.
+func describeEvaluation(eval selection.Evaluation) []string {
+	task := eval.Task
+	header := fmt.Sprintf("%s %q · status %s · project %s", task.ID, task.Title, task.Status, eval.ProjectID)
+	if task.EpicID != "" {
+		header += " · epic " + task.EpicID
+	}
+
+	lines := []string{header}
+	if eval.Eligible() {
+		return append(lines, "Eligible: momentum would pick this task up.")
+	}
+	lines = append(lines, "Not picked up because:")
+	for _, reason := range eval.Reasons {
+		lines = append(lines, "  - "+reason.String())
+	}
+	return lines
+}
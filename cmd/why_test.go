@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+func TestRunWhy(t *testing.T) {
+	flux := setupPlanTest(t)
+	project := flux.AddProject("Extra", "")
+	manual := flux.AddEpic(project.ID, "Manual", false)
+	auto := flux.AddEpic(project.ID, "Auto", true)
+	skipped := flux.AddTask(client.Task{ProjectID: project.ID, EpicID: manual.ID, Title: "Skipped", Status: "in_progress"})
+	ready := flux.AddTask(client.Task{ProjectID: project.ID, EpicID: auto.ID, Title: "Ready"})
+
+	tests := []struct {
+		name     string
+		taskID   string
+		projects []string
+		want     []string
+	}{
+		{
+			name:   "every reason listed",
+			taskID: skipped.ID,
+			want:   []string{"Not picked up because:", "has auto=false", "status is in_progress, not todo"},
+		},
+		{
+			name:   "eligible task",
+			taskID: ready.ID,
+			want:   []string{`"Ready"`, "Eligible"},
+		},
+		{
+			name:     "outside watched project",
+			taskID:   ready.ID,
+			projects: []string{"other-project"},
+			want:     []string{"outside the watched targets (Project: other-project)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectIDs = tt.projects
+			var out strings.Builder
+			if err := runWhy(&out, tt.taskID); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected output to contain %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+
+	projectIDs = nil
+	if err := runWhy(&strings.Builder{}, "task-missing"); err == nil {
+		t.Error("expected error for unknown task")
+	}
+}
@@ -1,19 +1,24 @@
-// Package config provides repo-specific configuration for Momentum.
+// Package config provides configuration for Momentum.
 //
-// Momentum looks for a .momentum.yaml file in the working directory.
-// If found, its settings override built-in defaults.
+// Settings are resolved in layers, each overriding the ones before it:
+// built-in defaults, the user's ~/.config/momentum/config.yaml, the
+// .momentum.yaml file in the working directory, MOMENTUM_* environment
+// variables and command-line flags.
 package config
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-
-	"gopkg.in/yaml.v3"
+	"slices"
+	"time"
 )
 
 const filename = ".momentum.yaml"
 
+// DefaultBaseURL is the Flux server momentum talks to unless told
+// otherwise.
+const DefaultBaseURL = "http://localhost:3000"
+
 // Mode controls how momentum manages task lifecycle.
 type Mode string
 
@@ -27,14 +32,168 @@ const (
 	ModeAgent Mode = "agent"
 )
 
-// RepoConfig holds repo-specific Momentum configuration.
+// RepoConfig holds Momentum configuration. Most settings belong in the
+// repo's .momentum.yaml, but any of them can be set in any layer.
 type RepoConfig struct {
+	// BaseURL is the Flux server's base URL.
+	BaseURL string `yaml:"base_url"`
+
+	// ExecutionMode is "async" (default) or "sync".
+	ExecutionMode string `yaml:"execution_mode"`
+
+	// Agent is the agent to run tasks with: "claude" (default) or
+	// "fake:<script.jsonl>".
+	Agent string `yaml:"agent"`
+
 	// Mode controls lifecycle management: "orchestrator" (default) or "agent".
 	Mode Mode `yaml:"mode"`
 
 	// Instructions replaces the default agent prompt preamble.
 	// Task context (ID, title, AC, guardrails) is always appended.
 	Instructions string `yaml:"instructions"`
+
+	// Selection controls which tasks momentum picks up and in what order.
+	Selection SelectionConfig `yaml:"selection"`
+
+	// Watch lists the projects, epics and tasks to draw work from when no
+	// --project, --epic or --task flags are given. Empty means all projects.
+	Watch []WatchEntry `yaml:"watch"`
+
+	// RestartOnChange restarts a running agent with the new prompt when its
+	// task's title, notes, acceptance criteria or guardrails change in Flux.
+	RestartOnChange bool `yaml:"restart_on_change"`
+
+	// Statuses maps what happened to a task to the board status momentum
+	// moves it to. Unset entries keep the stock Flux statuses.
+	Statuses StatusConfig `yaml:"statuses"`
+
+	// Transitions lists, for each status, the statuses a task may move to
+	// from it. Momentum refuses any other move. Empty allows every move.
+	Transitions map[string][]string `yaml:"transitions"`
+
+	// Timeout stops an agent that runs longer than this, e.g. "45m".
+	// Zero means no limit.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Review hands successful tasks to a human instead of marking them done.
+	Review ReviewConfig `yaml:"review"`
+
+	// Git commits the workdir's changes after a successful run.
+	Git GitConfig `yaml:"git"`
+
+	// Epics completes auto epics once all their tasks are done.
+	Epics EpicsConfig `yaml:"epics"`
+
+	// Recovery says what to do on startup with tasks left in progress by a
+	// momentum process that died: "todo" (default) puts them back in the
+	// queue, "planning" parks them for a human, "leave" only reports them.
+	Recovery string `yaml:"recovery"`
+
+	// AuditLog is the JSON lines file every status change is appended to.
+	// Empty means momentum/audit.jsonl under the user cache directory.
+	AuditLog string `yaml:"audit_log"`
+}
+
+// ReviewConfig holds review mode settings.
+type ReviewConfig struct {
+	// Enabled moves tasks whose agent succeeded to Status, with a summary
+	// comment, for a human to approve or send back to todo.
+	Enabled bool `yaml:"enabled"`
+	// Status is where tasks wait for review (default "review").
+	Status string `yaml:"status"`
+	// Verify is a shell command run in the workdir after the agent
+	// succeeds, e.g. "go test ./...". Its output goes in the summary.
+	Verify string `yaml:"verify"`
+}
+
+// GitConfig holds settings for committing agents' work.
+type GitConfig struct {
+	// Commit commits everything left in the workdir after a successful run,
+	// with a message naming the task.
+	Commit bool `yaml:"commit"`
+	// Branch is where the commit goes: "current" (default) commits on the
+	// checked-out branch, "task" on a branch of its own, after which the
+	// original branch is checked out again.
+	Branch string `yaml:"branch"`
+	// BranchPrefix is prepended to the task ID to name task branches
+	// (default "momentum/").
+	BranchPrefix string `yaml:"branch_prefix"`
+	// Remote is pushed the commit's branch after committing, e.g. "origin".
+	// Empty keeps commits local.
+	Remote string `yaml:"remote"`
+}
+
+// EpicsConfig holds settings for completing epics.
+type EpicsConfig struct {
+	// Complete moves an auto epic to done when its last task is done.
+	Complete bool `yaml:"complete"`
+	// DisableAuto also turns the epic's auto flag off.
+	DisableAuto bool `yaml:"disable_auto"`
+	// Hook is a shell command run in the workdir when an epic completes,
+	// e.g. "make integration-test". MOMENTUM_EPIC_ID, MOMENTUM_EPIC_TITLE
+	// and MOMENTUM_PROJECT_ID are set for it.
+	Hook string `yaml:"hook"`
+}
+
+// StatusConfig names the board status for each task outcome.
+type StatusConfig struct {
+	// Queued is where tasks wait to be picked up (default "todo").
+	Queued string `yaml:"queued"`
+	// Planning is where tasks wait for a human (default "planning").
+	Planning string `yaml:"planning"`
+	// Done is where finished tasks end up, after any review (default
+	// "done").
+	Done string `yaml:"done"`
+	// PickedUp is set when an agent starts (default "in_progress").
+	PickedUp string `yaml:"picked_up"`
+	// Succeeded is set when the agent finishes successfully (default "done").
+	Succeeded string `yaml:"succeeded"`
+	// Failed is set when the agent fails. Unset leaves the task as is.
+	Failed string `yaml:"failed"`
+	// Stopped is set when a user stops the agent (default "planning").
+	Stopped string `yaml:"stopped"`
+	// TimedOut is set when the agent hits Timeout (default "planning").
+	TimedOut string `yaml:"timed_out"`
+}
+
+// WatchEntry names exactly one project, epic or task to watch.
+type WatchEntry struct {
+	Project string `yaml:"project,omitempty"`
+	Epic    string `yaml:"epic,omitempty"`
+	Task    string `yaml:"task,omitempty"`
+}
+
+// SelectionConfig holds task selection settings.
+type SelectionConfig struct {
+	// Strategy orders candidate tasks: "newest" (default), "oldest",
+	// "priority", "due-date" or "critical-path".
+	Strategy string `yaml:"strategy"`
+
+	// TaskWeight sets how much each task counts towards a dependency chain
+	// under the critical-path strategy: "count" (default), "notes" or
+	// "estimate".
+	TaskWeight string `yaml:"task_weight"`
+
+	// Fairness shares agents between projects when watching all projects:
+	// "none" (default), "round-robin" or "weighted".
+	Fairness string `yaml:"fairness"`
+
+	// Weights maps project IDs to their share under weighted fairness.
+	// Unlisted projects get weight 1.
+	Weights map[string]int `yaml:"weights"`
+
+	// Labels limits selection to tasks carrying at least one of these labels.
+	Labels []string `yaml:"labels"`
+
+	// ExcludeLabels skips tasks carrying any of these labels.
+	ExcludeLabels []string `yaml:"exclude_labels"`
+
+	// Assignees limits selection to tasks assigned to one of these users.
+	Assignees []string `yaml:"assignees"`
+
+	// Match limits selection to tasks whose title or notes match at least
+	// one of these regular expressions.
+	Match []string `yaml:"match"`
 }
 
 // IsAgentMode returns true when the agent owns the task lifecycle.
@@ -42,23 +201,31 @@ func (c RepoConfig) IsAgentMode() bool {
 	return c.Mode == ModeAgent
 }
 
-// Load reads .momentum.yaml from dir. Returns a zero-value RepoConfig
-// (not an error) if the file doesn't exist.
+// Load reads .momentum.yaml from dir over the built-in defaults. A missing
+// file is not an error. Use Resolve to apply every layer.
 func Load(dir string) (RepoConfig, error) {
-	path := filepath.Join(dir, filename)
-
-	data, err := os.ReadFile(path)
+	repo, err := FileLayer(filepath.Join(dir, filename))
 	if err != nil {
-		if os.IsNotExist(err) {
-			return RepoConfig{}, nil
-		}
 		return RepoConfig{}, err
 	}
-
-	var cfg RepoConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	resolved, err := ResolveLayers(repo)
+	if err != nil {
 		return RepoConfig{}, err
 	}
+	return resolved.Config, nil
+}
+
+// validate checks the settings and fills in defaults.
+func (cfg *RepoConfig) validate() error {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if cfg.ExecutionMode == "" {
+		cfg.ExecutionMode = "async"
+	}
+	if cfg.Agent == "" {
+		cfg.Agent = "claude"
+	}
 
 	// Validate mode
 	switch cfg.Mode {
@@ -67,8 +234,72 @@ func Load(dir string) (RepoConfig, error) {
 	case ModeAgent:
 		// valid
 	default:
-		return RepoConfig{}, fmt.Errorf("invalid mode %q (use \"orchestrator\" or \"agent\")", cfg.Mode)
+		return fmt.Errorf("invalid mode %q (use \"orchestrator\" or \"agent\")", cfg.Mode)
+	}
+
+	for projectID, weight := range cfg.Selection.Weights {
+		if weight <= 0 {
+			return fmt.Errorf("invalid weight %d for project %q (must be positive)", weight, projectID)
+		}
+	}
+
+	for from, tos := range cfg.Transitions {
+		if from == "" || slices.Contains(tos, "") {
+			return fmt.Errorf("invalid transitions: status names must not be empty")
+		}
+	}
+
+	// Review mode is the succeeded status under another name
+	if cfg.Review.Enabled {
+		if cfg.Review.Status == "" {
+			cfg.Review.Status = "review"
+		}
+		if cfg.Statuses.Succeeded != "" && cfg.Statuses.Succeeded != cfg.Review.Status {
+			return fmt.Errorf("review.status %q conflicts with statuses.succeeded %q", cfg.Review.Status, cfg.Statuses.Succeeded)
+		}
+		cfg.Statuses.Succeeded = cfg.Review.Status
+	}
+
+	switch cfg.Git.Branch {
+	case "":
+		cfg.Git.Branch = "current"
+	case "current", "task":
+		// valid
+	default:
+		return fmt.Errorf("invalid git.branch %q (use \"current\" or \"task\")", cfg.Git.Branch)
+	}
+	if cfg.Git.BranchPrefix == "" {
+		cfg.Git.BranchPrefix = "momentum/"
+	}
+	// Agents share the workdir, and a commit takes everything in it
+	if cfg.Git.Commit && cfg.ExecutionMode != "sync" {
+		return fmt.Errorf("git.commit needs execution_mode sync, since agents running together share the workdir")
+	}
+
+	switch cfg.Recovery {
+	case "":
+		cfg.Recovery = "todo"
+	case "todo", "planning", "leave":
+		// valid
+	default:
+		return fmt.Errorf("invalid recovery %q (use \"todo\", \"planning\" or \"leave\")", cfg.Recovery)
+	}
+
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("invalid timeout %v (must not be negative)", cfg.Timeout)
+	}
+
+	for i, entry := range cfg.Watch {
+		set := 0
+		for _, id := range []string{entry.Project, entry.Epic, entry.Task} {
+			if id != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("invalid watch entry %d (set exactly one of project, epic or task)", i+1)
+		}
 	}
 
-	return cfg, nil
+	return nil
 }
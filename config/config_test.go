@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad_FileExists(t *testing.T) {
@@ -116,3 +118,283 @@ func TestLoad_ModeInvalid(t *testing.T) {
 		t.Fatal("expected error for invalid mode")
 	}
 }
+
+func TestLoad_SelectionStrategy(t *testing.T) {
+	dir := t.TempDir()
+	content := `selection:
+  strategy: priority
+`
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Selection.Strategy != "priority" {
+		t.Errorf("got strategy %q, want %q", cfg.Selection.Strategy, "priority")
+	}
+}
+
+func TestLoad_SelectionWeights(t *testing.T) {
+	dir := t.TempDir()
+	content := `selection:
+  fairness: weighted
+  weights:
+    proj-a: 3
+    proj-b: 1
+`
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Selection.Fairness != "weighted" {
+		t.Errorf("got fairness %q, want %q", cfg.Selection.Fairness, "weighted")
+	}
+	if cfg.Selection.Weights["proj-a"] != 3 || cfg.Selection.Weights["proj-b"] != 1 {
+		t.Errorf("unexpected weights %v", cfg.Selection.Weights)
+	}
+}
+
+func TestLoad_SelectionWeightInvalid(t *testing.T) {
+	dir := t.TempDir()
+	content := `selection:
+  weights:
+    proj-a: 0
+`
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(dir); err == nil {
+		t.Fatal("expected error for non-positive weight")
+	}
+}
+
+func TestLoad_SelectionFilters(t *testing.T) {
+	dir := t.TempDir()
+	content := `selection:
+  labels: [frontend]
+  exclude_labels: [wip, blocked-upstream]
+  assignees: [alice]
+  match: ["^UI:"]
+`
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sel := cfg.Selection
+	if len(sel.Labels) != 1 || sel.Labels[0] != "frontend" {
+		t.Errorf("unexpected labels %v", sel.Labels)
+	}
+	if len(sel.ExcludeLabels) != 2 {
+		t.Errorf("unexpected exclude_labels %v", sel.ExcludeLabels)
+	}
+	if len(sel.Assignees) != 1 || sel.Assignees[0] != "alice" {
+		t.Errorf("unexpected assignees %v", sel.Assignees)
+	}
+	if len(sel.Match) != 1 || sel.Match[0] != "^UI:" {
+		t.Errorf("unexpected match %v", sel.Match)
+	}
+}
+
+func TestLoad_Watch(t *testing.T) {
+	dir := t.TempDir()
+	content := `watch:
+  - project: web
+  - project: api
+  - epic: epic-456
+`
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Watch) != 3 {
+		t.Fatalf("expected 3 watch entries, got %v", cfg.Watch)
+	}
+	if cfg.Watch[1].Project != "api" || cfg.Watch[2].Epic != "epic-456" {
+		t.Errorf("unexpected watch entries %v", cfg.Watch)
+	}
+}
+
+func TestLoad_WatchEntryInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty entry", "watch:\n  - {}\n"},
+		{"two targets", "watch:\n  - project: web\n    epic: epic-1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, filename), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); err == nil {
+				t.Error("expected error for invalid watch entry")
+			}
+		})
+	}
+}
+
+func TestLoad_StatusesAndTransitions(t *testing.T) {
+	dir := t.TempDir()
+	content := `statuses:
+  succeeded: review
+  failed: blocked
+timeout: 45m
+transitions:
+  todo: [in_progress]
+  in_progress: [review, blocked, planning]
+  review: [done, todo]
+  done: []
+`
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Statuses.Succeeded != "review" || cfg.Statuses.Failed != "blocked" || cfg.Statuses.PickedUp != "" {
+		t.Errorf("unexpected statuses %+v", cfg.Statuses)
+	}
+	if cfg.Timeout != 45*time.Minute {
+		t.Errorf("expected timeout 45m, got %v", cfg.Timeout)
+	}
+	if got := cfg.Transitions["in_progress"]; len(got) != 3 || got[0] != "review" {
+		t.Errorf("unexpected transitions from in_progress: %v", got)
+	}
+	if got, ok := cfg.Transitions["done"]; !ok || len(got) != 0 {
+		t.Errorf("expected done to be final, got %v (present %v)", got, ok)
+	}
+}
+
+func TestLoad_InvalidTransitionsAndTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty target", "transitions:\n  todo: [\"\"]\n"},
+		{"negative timeout", "timeout: -5m\n"},
+		{"unknown recovery policy", "recovery: delete\n"},
+		{"review status conflict", "review:\n  enabled: true\nstatuses:\n  succeeded: done\n"},
+		{"unknown git branch", "git:\n  branch: feature\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, filename), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoad_Review(t *testing.T) {
+	dir := t.TempDir()
+	content := `review:
+  enabled: true
+  verify: go test ./...
+`
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Review.Status != "review" || cfg.Review.Verify != "go test ./..." {
+		t.Errorf("unexpected review config %+v", cfg.Review)
+	}
+	if cfg.Statuses.Succeeded != "review" {
+		t.Errorf("expected succeeded status review, got %q", cfg.Statuses.Succeeded)
+	}
+}
+
+func TestLoad_Git(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    GitConfig
+	}{
+		{"defaults", "", GitConfig{Branch: "current", BranchPrefix: "momentum/"}},
+		{
+			"task branches",
+			"execution_mode: sync\ngit:\n  commit: true\n  branch: task\n  branch_prefix: agents/\n  remote: origin\n",
+			GitConfig{Commit: true, Branch: "task", BranchPrefix: "agents/", Remote: "origin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, filename), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Git != tt.want {
+				t.Errorf("Git = %+v, want %+v", cfg.Git, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_GitCommitNeedsSync(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte("git:\n  commit: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "execution_mode sync") {
+		t.Errorf("Load() error = %v, want git.commit rejected in async mode", err)
+	}
+}
+
+func TestLoad_Epics(t *testing.T) {
+	dir := t.TempDir()
+	content := `epics:
+  complete: true
+  disable_auto: true
+  hook: make integration-test
+`
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := EpicsConfig{Complete: true, DisableAuto: true, Hook: "make integration-test"}
+	if cfg.Epics != want {
+		t.Errorf("Epics = %+v, want %+v", cfg.Epics, want)
+	}
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceDefault is the source of settings no layer sets.
+const SourceDefault = "default"
+
+// envPrefix starts the environment variables that override settings, e.g.
+// MOMENTUM_REVIEW_ENABLED for review.enabled.
+const envPrefix = "MOMENTUM_"
+
+// Layer is one source of settings. Layers are applied in order, each
+// overriding the settings of the ones before it.
+type Layer struct {
+	name   string
+	values map[string]any
+	// sources names where individual settings came from, by dotted key,
+	// when that is more specific than the layer's name
+	sources map[string]string
+}
+
+// NewLayer returns an empty layer called name.
+func NewLayer(name string) *Layer {
+	return &Layer{name: name, values: make(map[string]any), sources: make(map[string]string)}
+}
+
+// Set sets a setting by its dotted key, e.g. "selection.strategy", noting
+// source as where it came from.
+func (l *Layer) Set(key string, value any, source string) {
+	parts := strings.Split(key, ".")
+	values := l.values
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := values[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			values[part] = next
+		}
+		values = next
+	}
+	values[parts[len(parts)-1]] = value
+	l.sources[key] = source
+}
+
+// FileLayer reads settings from a YAML file. A missing file is an empty
+// layer.
+func FileLayer(path string) (*Layer, error) {
+	layer := NewLayer(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return layer, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &layer.values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if layer.values == nil {
+		layer.values = make(map[string]any)
+	}
+	return layer, nil
+}
+
+// EnvLayer reads settings from MOMENTUM_* variables in environ, which is
+// in the form of os.Environ. Each scalar setting has one, named after its
+// key in upper case with dots and dashes as underscores; list settings
+// take comma-separated values, and durations given as a bare number are
+// seconds. Other MOMENTUM_* variables are ignored.
+func EnvLayer(environ []string) *Layer {
+	layer := NewLayer("environment")
+	keys := envKeys()
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		key, ok := keys[name]
+		if !ok {
+			continue
+		}
+		if key.list {
+			var items []any
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			layer.Set(key.key, items, "env "+name)
+			continue
+		}
+		if key.duration {
+			// YAML would read a bare number as nanoseconds
+			if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				value = (time.Duration(n) * time.Second).String()
+			}
+		}
+		var parsed any
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil || parsed == nil {
+			parsed = value
+		}
+		if _, isMap := parsed.(map[string]any); isMap {
+			parsed = value
+		}
+		if _, isList := parsed.([]any); isList {
+			parsed = value
+		}
+		layer.Set(key.key, parsed, "env "+name)
+	}
+	return layer
+}
+
+type envKey struct {
+	key      string
+	list     bool
+	duration bool
+}
+
+// envKeys maps environment variable names to the settings they set: every
+// scalar and string list setting. Maps and lists of sections, such as
+// transitions and watch, can only be set in files.
+func envKeys() map[string]envKey {
+	keys := make(map[string]envKey)
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for _, field := range reflect.VisibleFields(t) {
+			tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			key := prefix + tag
+			name := envPrefix + strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(key))
+			switch field.Type.Kind() {
+			case reflect.Struct:
+				walk(field.Type, key+".")
+			case reflect.Map:
+			case reflect.Slice:
+				if field.Type.Elem().Kind() == reflect.String {
+					keys[name] = envKey{key: key, list: true}
+				}
+			default:
+				keys[name] = envKey{key: key, duration: field.Type == reflect.TypeFor[time.Duration]()}
+			}
+		}
+	}
+	walk(reflect.TypeFor[RepoConfig](), "")
+	return keys
+}
+
+// GlobalPath returns the user's config file:
+// $XDG_CONFIG_HOME/momentum/config.yaml, by default under ~/.config.
+func GlobalPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "momentum", "config.yaml")
+}
+
+// Resolved is a configuration built from layers, with where each setting
+// came from.
+type Resolved struct {
+	Config RepoConfig
+	// Sources maps the dotted keys of settings set by a layer to where
+	// they came from. Missing keys have their default.
+	Sources map[string]string
+}
+
+// Setting is one resolved setting.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Resolve builds the configuration for dir from, in increasing order of
+// precedence: built-in defaults, the global config file (GlobalPath), the
+// repo's .momentum.yaml, MOMENTUM_* environment variables and flags. Flags
+// may be nil.
+func Resolve(dir string, flags *Layer) (Resolved, error) {
+	var layers []*Layer
+	if path := GlobalPath(); path != "" {
+		global, err := FileLayer(path)
+		if err != nil {
+			return Resolved{}, err
+		}
+		layers = append(layers, global)
+	}
+	repo, err := FileLayer(filepath.Join(dir, filename))
+	if err != nil {
+		return Resolved{}, err
+	}
+	layers = append(layers, repo, EnvLayer(os.Environ()))
+	if flags != nil {
+		layers = append(layers, flags)
+	}
+	return ResolveLayers(layers...)
+}
+
+// ResolveLayers merges layers in order over the built-in defaults and
+// validates the result. Sections are merged key by key; any other value,
+// including a list, replaces the one before it.
+func ResolveLayers(layers ...*Layer) (Resolved, error) {
+	merged := make(map[string]any)
+	sources := make(map[string]string)
+	for _, layer := range layers {
+		merge(merged, layer.values, "", layer, sources)
+	}
+
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return Resolved{}, err
+	}
+	var cfg RepoConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Resolved{}, err
+	}
+	if err := cfg.validate(); err != nil {
+		return Resolved{}, err
+	}
+	return Resolved{Config: cfg, Sources: sources}, nil
+}
+
+// merge copies src over dst, recording the source of every setting it
+// sets.
+func merge(dst, src map[string]any, prefix string, layer *Layer, sources map[string]string) {
+	for name, value := range src {
+		key := prefix + name
+		if section, ok := value.(map[string]any); ok {
+			existing, ok := dst[name].(map[string]any)
+			if !ok {
+				existing = make(map[string]any)
+				dst[name] = existing
+			}
+			merge(existing, section, key+".", layer, sources)
+			continue
+		}
+		dst[name] = value
+		if source, ok := layer.sources[key]; ok {
+			sources[key] = source
+		} else {
+			sources[key] = layer.name
+		}
+	}
+}
+
+// Settings lists every setting with its resolved value and source, sorted
+// by key. Sections are flattened into dotted keys; lists are shown whole.
+func (r Resolved) Settings() ([]Setting, error) {
+	data, err := yaml.Marshal(r.Config)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	var settings []Setting
+	var flatten func(values map[string]any, prefix string)
+	flatten = func(values map[string]any, prefix string) {
+		for name, value := range values {
+			key := prefix + name
+			if section, ok := value.(map[string]any); ok && len(section) > 0 {
+				flatten(section, key+".")
+				continue
+			}
+			source, ok := r.Sources[key]
+			if !ok {
+				source = SourceDefault
+			}
+			settings = append(settings, Setting{Key: key, Value: formatValue(value), Source: source})
+		}
+	}
+	flatten(tree, "")
+	slices.SortFunc(settings, func(a, b Setting) int { return strings.Compare(a.Key, b.Key) })
+	return settings, nil
+}
+
+// formatValue shows a setting's value: strings as they are, anything else
+// as JSON.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return `""`
+		}
+		return v
+	case nil:
+		return "null"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
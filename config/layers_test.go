@@ -0,0 +1,171 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestResolveLayers_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	global := filepath.Join(dir, "global.yaml")
+	repo := filepath.Join(dir, filename)
+	os.WriteFile(global, []byte("base_url: http://global:3000\nselection:\n  strategy: oldest\n  labels: [a, b]\ntimeout: 10m\n"), 0o644)
+	os.WriteFile(repo, []byte("selection:\n  labels: [c]\ntimeout: 20m\n"), 0o644)
+
+	globalLayer, err := FileLayer(global)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repoLayer, err := FileLayer(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := EnvLayer([]string{"MOMENTUM_TIMEOUT=30m", "MOMENTUM_WORKDIR=/elsewhere", "PATH=/bin"})
+	flags := NewLayer("flags")
+	flags.Set("selection.fairness", "round-robin", "flag --fairness")
+
+	resolved, err := ResolveLayers(globalLayer, repoLayer, env, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := resolved.Config
+	if cfg.BaseURL != "http://global:3000" || cfg.Selection.Strategy != "oldest" {
+		t.Errorf("global settings not applied: %+v", cfg)
+	}
+	if !slices.Equal(cfg.Selection.Labels, []string{"c"}) {
+		t.Errorf("labels = %v, want the repo's list to replace the global one", cfg.Selection.Labels)
+	}
+	if cfg.Timeout != 30*time.Minute || cfg.Selection.Fairness != "round-robin" {
+		t.Errorf("timeout = %v, fairness = %q", cfg.Timeout, cfg.Selection.Fairness)
+	}
+
+	want := map[string]string{
+		"base_url":           global,
+		"selection.strategy": global,
+		"selection.labels":   repo,
+		"timeout":            "env MOMENTUM_TIMEOUT",
+		"selection.fairness": "flag --fairness",
+	}
+	for key, source := range want {
+		if got := resolved.Sources[key]; got != source {
+			t.Errorf("source of %s = %q, want %q", key, got, source)
+		}
+	}
+}
+
+func TestResolveLayers_Validates(t *testing.T) {
+	env := EnvLayer([]string{"MOMENTUM_RECOVERY=delete"})
+	if _, err := ResolveLayers(env); err == nil {
+		t.Error("expected an invalid recovery policy from the environment to be rejected")
+	}
+}
+
+func TestEnvLayer(t *testing.T) {
+	resolved, err := ResolveLayers(EnvLayer([]string{
+		"MOMENTUM_REVIEW_ENABLED=true",
+		"MOMENTUM_SELECTION_EXCLUDE_LABELS=wip, blocked",
+		"MOMENTUM_GIT_BRANCH_PREFIX=agents/",
+		"MOMENTUM_INSTRUCTIONS=Use: the skill",
+		"MOMENTUM_EPIC_ID=epic-1",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := resolved.Config
+	if !cfg.Review.Enabled {
+		t.Error("expected review to be enabled")
+	}
+	if !slices.Equal(cfg.Selection.ExcludeLabels, []string{"wip", "blocked"}) {
+		t.Errorf("exclude labels = %v", cfg.Selection.ExcludeLabels)
+	}
+	if cfg.Git.BranchPrefix != "agents/" {
+		t.Errorf("branch prefix = %q", cfg.Git.BranchPrefix)
+	}
+	if cfg.Instructions != "Use: the skill" {
+		t.Errorf("instructions = %q", cfg.Instructions)
+	}
+}
+
+func TestEnvLayer_Durations(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"30", 30 * time.Second},
+		{"45m", 45 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		resolved, err := ResolveLayers(EnvLayer([]string{"MOMENTUM_TIMEOUT=" + tt.value}))
+		if err != nil {
+			t.Fatalf("MOMENTUM_TIMEOUT=%s: %v", tt.value, err)
+		}
+		if resolved.Config.Timeout != tt.want {
+			t.Errorf("MOMENTUM_TIMEOUT=%s: timeout = %v, want %v", tt.value, resolved.Config.Timeout, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("MOMENTUM_AGENT", "fake:script.jsonl")
+	os.MkdirAll(filepath.Join(xdg, "momentum"), 0o755)
+	os.WriteFile(filepath.Join(xdg, "momentum", "config.yaml"), []byte("mode: agent\n"), 0o644)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, filename), []byte("instructions: repo\n"), 0o644)
+
+	flags := NewLayer("flags")
+	flags.Set("base_url", "http://flag:1", "flag --base-url")
+	resolved, err := Resolve(dir, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := resolved.Config
+	if cfg.Mode != ModeAgent || cfg.Instructions != "repo" || cfg.Agent != "fake:script.jsonl" || cfg.BaseURL != "http://flag:1" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if got := GlobalPath(); got != filepath.Join(xdg, "momentum", "config.yaml") {
+		t.Errorf("GlobalPath() = %q", got)
+	}
+}
+
+func TestResolved_Settings(t *testing.T) {
+	repo := NewLayer(filename)
+	repo.Set("transitions.todo", []any{"in_progress"}, filename)
+	repo.Set("selection.labels", []any{"backend"}, filename)
+	resolved, err := ResolveLayers(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := resolved.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]Setting)
+	var keys []string
+	for _, s := range settings {
+		got[s.Key] = s
+		keys = append(keys, s.Key)
+	}
+	if !slices.IsSorted(keys) {
+		t.Errorf("settings not sorted by key: %v", keys)
+	}
+	want := []Setting{
+		{Key: "base_url", Value: DefaultBaseURL, Source: SourceDefault},
+		{Key: "audit_log", Value: `""`, Source: SourceDefault},
+		{Key: "review.enabled", Value: "false", Source: SourceDefault},
+		{Key: "timeout", Value: "0s", Source: SourceDefault},
+		{Key: "transitions.todo", Value: `["in_progress"]`, Source: filename},
+		{Key: "selection.labels", Value: `["backend"]`, Source: filename},
+	}
+	for _, w := range want {
+		if got[w.Key] != w {
+			t.Errorf("setting %s = %+v, want %+v", w.Key, got[w.Key], w)
+		}
+	}
+}
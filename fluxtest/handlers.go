@@ -0,0 +1,441 @@
+package fluxtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// --- Project handlers ---
+
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	projects := slices.Clone(s.projects)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, nonNil(projects))
+}
+
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	s.mu.Lock()
+	project := s.createProject(body.Name, body.Description)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, project)
+}
+
+func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.projectIndex(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+	if body.Name != nil {
+		s.projects[i].Name = *body.Name
+	}
+	if body.Description != nil {
+		s.projects[i].Description = *body.Description
+	}
+	s.broadcast("project.updated", map[string]any{"project": s.projects[i]})
+	writeJSON(w, http.StatusOK, s.projects[i])
+}
+
+func (s *Server) handleDeleteProject(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("id")
+	i := s.projectIndex(id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+	project := s.projects[i]
+	s.projects = slices.Delete(s.projects, i, i+1)
+	s.epics = slices.DeleteFunc(s.epics, func(e client.Epic) bool { return e.ProjectID == id })
+	s.tasks = slices.DeleteFunc(s.tasks, func(t client.Task) bool { return t.ProjectID == id })
+	s.broadcast("project.deleted", map[string]any{"project": project})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- Epic handlers ---
+
+func (s *Server) handleListEpics(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	projectID := r.PathValue("id")
+	if s.projectIndex(projectID) < 0 {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+	epics := []client.Epic{}
+	for _, epic := range s.epics {
+		if epic.ProjectID == projectID {
+			epics = append(epics, epic)
+		}
+	}
+	writeJSON(w, http.StatusOK, epics)
+}
+
+func (s *Server) handleCreateEpic(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Title string `json:"title"`
+		Notes string `json:"notes"`
+		Auto  bool   `json:"auto"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Title == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	projectID := r.PathValue("id")
+	if s.projectIndex(projectID) < 0 {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+	epic := s.createEpic(projectID, body.Title, body.Notes, body.Auto)
+	writeJSON(w, http.StatusCreated, epic)
+}
+
+func (s *Server) handleUpdateEpic(w http.ResponseWriter, r *http.Request) {
+	var updates client.EpicUpdate
+	if !readJSON(w, r, &updates) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.epicIndex(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "epic not found")
+		return
+	}
+	epic := &s.epics[i]
+	if updates.Title != nil {
+		epic.Title = *updates.Title
+	}
+	if updates.Notes != nil {
+		epic.Notes = *updates.Notes
+	}
+	if updates.Status != nil {
+		epic.Status = *updates.Status
+	}
+	if updates.DependsOn != nil {
+		epic.DependsOn = *updates.DependsOn
+	}
+	if updates.Auto != nil {
+		epic.Auto = *updates.Auto
+	}
+	s.broadcastEpic("epic.updated", *epic)
+	writeJSON(w, http.StatusOK, *epic)
+}
+
+func (s *Server) handleDeleteEpic(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("id")
+	i := s.epicIndex(id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "epic not found")
+		return
+	}
+	epic := s.epics[i]
+	s.epics = slices.Delete(s.epics, i, i+1)
+	// Tasks become unassigned rather than deleted, matching Flux.
+	for j := range s.tasks {
+		if s.tasks[j].EpicID == id {
+			s.tasks[j].EpicID = ""
+		}
+	}
+	s.broadcastEpic("epic.deleted", epic)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- Task handlers ---
+
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	projectID := r.PathValue("id")
+	if s.projectIndex(projectID) < 0 {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	epicID := r.URL.Query().Get("epic_id")
+	status := r.URL.Query().Get("status")
+
+	tasks := []client.Task{}
+	for _, task := range s.tasks {
+		if task.ProjectID != projectID {
+			continue
+		}
+		if epicID != "" && task.EpicID != epicID {
+			continue
+		}
+		if status != "" && task.Status != status {
+			continue
+		}
+		tasks = append(tasks, s.present(task))
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Title  string `json:"title"`
+		Notes  string `json:"notes"`
+		EpicID string `json:"epic_id"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Title == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	projectID := r.PathValue("id")
+	if s.projectIndex(projectID) < 0 {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+	if body.EpicID != "" && s.epicIndex(body.EpicID) < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("epic %s not found", body.EpicID))
+		return
+	}
+
+	task := client.Task{
+		ID:        s.newID("task"),
+		Title:     body.Title,
+		Notes:     body.Notes,
+		Status:    "todo",
+		ProjectID: projectID,
+		EpicID:    body.EpicID,
+		CreatedAt: now(),
+	}
+	s.tasks = append(s.tasks, task)
+	s.broadcastTask("task.created", task)
+	writeJSON(w, http.StatusCreated, s.present(task))
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.taskIndex(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.present(s.tasks[i]))
+}
+
+func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+	var updates client.TaskUpdate
+	if !readJSON(w, r, &updates) {
+		return
+	}
+	if updates.Status != nil && *updates.Status == "" {
+		writeError(w, http.StatusBadRequest, "status must not be empty")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.taskIndex(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	task := &s.tasks[i]
+	if updates.ExpectedStatus != nil && *updates.ExpectedStatus != task.Status {
+		writeError(w, http.StatusConflict, fmt.Sprintf("task status is %q, expected %q", task.Status, *updates.ExpectedStatus))
+		return
+	}
+	previousStatus := task.Status
+	if updates.Title != nil {
+		task.Title = *updates.Title
+	}
+	if updates.Notes != nil {
+		task.Notes = *updates.Notes
+	}
+	if updates.Status != nil {
+		task.Status = *updates.Status
+	}
+	if updates.EpicID != nil {
+		task.EpicID = *updates.EpicID
+	}
+	if updates.DependsOn != nil {
+		task.DependsOn = *updates.DependsOn
+	}
+	if updates.AgentName != nil {
+		task.AgentName = *updates.AgentName
+	}
+
+	updated := *task
+	if updated.Status != previousStatus {
+		s.broadcastTask("task.status_changed", updated)
+		// Dependents may have become unblocked.
+		for _, other := range s.tasks {
+			if slices.Contains(other.DependsOn, updated.ID) {
+				s.broadcastTask("task.updated", other)
+			}
+		}
+	} else {
+		s.broadcastTask("task.updated", updated)
+	}
+	writeJSON(w, http.StatusOK, s.present(updated))
+}
+
+func (s *Server) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.taskIndex(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	task := s.tasks[i]
+	s.tasks = slices.Delete(s.tasks, i, i+1)
+	s.broadcastTask("task.deleted", task)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAddComment(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Body   string `json:"body"`
+		Author string `json:"author"`
+	}
+	if !readJSON(w, r, &body) {
+		return
+	}
+	if body.Body == "" {
+		writeError(w, http.StatusBadRequest, "body is required")
+		return
+	}
+	if body.Author == "" {
+		body.Author = "user"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.taskIndex(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	comment := client.Comment{
+		ID:        s.newID("comment"),
+		Body:      body.Body,
+		Author:    body.Author,
+		CreatedAt: now(),
+	}
+	s.tasks[i].Comments = append(s.tasks[i].Comments, comment)
+	s.broadcastTask("task.updated", s.tasks[i])
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+// --- Event stream ---
+
+// handleEvents streams broadcast events as Server-Sent Events until the
+// client disconnects or the server is closed.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	missed, events, cancel := s.SubscribeAfter(r.Header.Get("Last-Event-ID"))
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	for _, event := range missed {
+		writeEvent(w, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case event := <-events:
+			writeEvent(w, event)
+			flusher.Flush()
+		case <-heartbeat.C:
+			// Keeps idle clients from treating the stream as stalled
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event Event) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
+
+// --- Encoding helpers ---
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,359 @@
+// Package fluxtest provides an in-memory fake of the Flux API.
+//
+// The Server implements the REST endpoints used by the client package and
+// an /api/events SSE stream that emits real task.*, epic.* and project.*
+// events as data changes. It is intended for tests and for running momentum
+// end to end without a Flux install (see "momentum dev-server").
+package fluxtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// Event is a change notification broadcast on the /api/events stream.
+type Event struct {
+	// Type is the SSE event type (e.g., "task.created", "epic.updated")
+	Type string
+	// Data is the JSON-encoded event payload
+	Data string
+	// ID is the event's sequence number, sent as the SSE id: field
+	ID string
+}
+
+// maxHistory is how many past events are kept for Last-Event-ID replay.
+const maxHistory = 1000
+
+// heartbeatInterval is how often an idle event stream sends a comment.
+const heartbeatInterval = 15 * time.Second
+
+// Server is an in-memory Flux server. The zero value is not usable;
+// create instances with New.
+type Server struct {
+	mu       sync.Mutex
+	projects []client.Project
+	epics    []client.Epic
+	tasks    []client.Task
+	nextID   int
+
+	// subscribers receive every broadcast event
+	subscribers map[chan Event]struct{}
+	// history holds recent events for clients resuming with Last-Event-ID
+	history  []Event
+	eventSeq int
+	// done is closed by Close to end all open event streams
+	done   chan struct{}
+	closed bool
+
+	mux *http.ServeMux
+}
+
+// New creates an empty in-memory Flux server.
+func New() *Server {
+	s := &Server{
+		subscribers: make(map[chan Event]struct{}),
+		done:        make(chan struct{}),
+		mux:         http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /api/projects", s.handleListProjects)
+	s.mux.HandleFunc("POST /api/projects", s.handleCreateProject)
+	s.mux.HandleFunc("PATCH /api/projects/{id}", s.handleUpdateProject)
+	s.mux.HandleFunc("DELETE /api/projects/{id}", s.handleDeleteProject)
+
+	s.mux.HandleFunc("GET /api/projects/{id}/epics", s.handleListEpics)
+	s.mux.HandleFunc("POST /api/projects/{id}/epics", s.handleCreateEpic)
+	s.mux.HandleFunc("PATCH /api/epics/{id}", s.handleUpdateEpic)
+	s.mux.HandleFunc("DELETE /api/epics/{id}", s.handleDeleteEpic)
+
+	s.mux.HandleFunc("GET /api/projects/{id}/tasks", s.handleListTasks)
+	s.mux.HandleFunc("POST /api/projects/{id}/tasks", s.handleCreateTask)
+	s.mux.HandleFunc("GET /api/tasks/{id}", s.handleGetTask)
+	s.mux.HandleFunc("PATCH /api/tasks/{id}", s.handleUpdateTask)
+	s.mux.HandleFunc("DELETE /api/tasks/{id}", s.handleDeleteTask)
+	s.mux.HandleFunc("POST /api/tasks/{id}/comments", s.handleAddComment)
+
+	s.mux.HandleFunc("GET /api/events", s.handleEvents)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close ends all open event streams. It should be called before closing an
+// httptest.Server wrapping this handler, since open SSE requests would
+// otherwise keep it from shutting down.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+}
+
+// --- Seeding and inspection ---
+
+// AddProject creates a project and returns it.
+func (s *Server) AddProject(name, description string) client.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createProject(name, description)
+}
+
+// AddEpic creates an epic in the given project and returns it.
+func (s *Server) AddEpic(projectID, title string, auto bool) client.Epic {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createEpic(projectID, title, "", auto)
+}
+
+// AddTask stores the given task and returns it as the API would report it.
+// An ID and creation time are generated when unset, and the status defaults
+// to "todo".
+func (s *Server) AddTask(task client.Task) client.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if task.ID == "" {
+		task.ID = s.newID("task")
+	}
+	if task.Status == "" {
+		task.Status = "todo"
+	}
+	if task.CreatedAt == "" {
+		task.CreatedAt = now()
+	}
+	s.tasks = append(s.tasks, task)
+	s.broadcastTask("task.created", task)
+	return s.present(task)
+}
+
+// Project returns the project with the given ID.
+func (s *Server) Project(id string) (client.Project, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.projectIndex(id); i >= 0 {
+		return s.projects[i], true
+	}
+	return client.Project{}, false
+}
+
+// Epic returns the epic with the given ID.
+func (s *Server) Epic(id string) (client.Epic, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.epicIndex(id); i >= 0 {
+		return s.epics[i], true
+	}
+	return client.Epic{}, false
+}
+
+// Task returns the task with the given ID, with Blocked computed from its
+// dependencies.
+func (s *Server) Task(id string) (client.Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.taskIndex(id); i >= 0 {
+		return s.present(s.tasks[i]), true
+	}
+	return client.Task{}, false
+}
+
+// Tasks returns all tasks in the given project.
+func (s *Server) Tasks(projectID string) []client.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var tasks []client.Task
+	for _, task := range s.tasks {
+		if task.ProjectID == projectID {
+			tasks = append(tasks, s.present(task))
+		}
+	}
+	return tasks
+}
+
+// SetEpicAuto toggles the auto flag on an epic and broadcasts epic.updated.
+func (s *Server) SetEpicAuto(epicID string, auto bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.epicIndex(epicID)
+	if i < 0 {
+		return fmt.Errorf("epic %s not found", epicID)
+	}
+	s.epics[i].Auto = auto
+	s.broadcastEpic("epic.updated", s.epics[i])
+	return nil
+}
+
+// Seed populates the server with a small demo project: one auto epic with
+// a short dependency chain and one manual epic.
+func (s *Server) Seed() {
+	project := s.AddProject("Demo", "Sample project served by momentum dev-server")
+
+	auto := s.AddEpic(project.ID, "Onboarding flow", true)
+	first := s.AddTask(client.Task{
+		Title:              "Add welcome screen",
+		Notes:              "Render a welcome screen on first launch.",
+		ProjectID:          project.ID,
+		EpicID:             auto.ID,
+		AcceptanceCriteria: []string{"Welcome screen is shown once per user"},
+	})
+	s.AddTask(client.Task{
+		Title:     "Persist onboarding progress",
+		Notes:     "Store the last completed onboarding step.",
+		ProjectID: project.ID,
+		EpicID:    auto.ID,
+		DependsOn: []string{first.ID},
+	})
+
+	manual := s.AddEpic(project.ID, "Billing", false)
+	s.AddTask(client.Task{
+		Title:     "Draft pricing page copy",
+		ProjectID: project.ID,
+		EpicID:    manual.ID,
+	})
+}
+
+// Subscribe registers a listener for broadcast events. The returned cancel
+// function must be called to release it.
+func (s *Server) Subscribe() (<-chan Event, func()) {
+	_, ch, cancel := s.SubscribeAfter("")
+	return ch, cancel
+}
+
+// Subscribers returns how many listeners are registered, e.g. to wait for
+// a client's event stream to connect.
+func (s *Server) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers)
+}
+
+// SubscribeAfter is like Subscribe, but also returns the recent events that
+// came after the event with ID lastEventID, so a reconnecting client misses
+// nothing. An empty or unknown ID replays nothing.
+func (s *Server) SubscribeAfter(lastEventID string) ([]Event, <-chan Event, func()) {
+	ch := make(chan Event, 100)
+
+	s.mu.Lock()
+	var missed []Event
+	if seq, err := strconv.Atoi(lastEventID); err == nil {
+		for _, event := range s.history {
+			if id, _ := strconv.Atoi(event.ID); id > seq {
+				missed = append(missed, event)
+			}
+		}
+	}
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	return missed, ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}
+}
+
+// --- Internal helpers (callers must hold s.mu) ---
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	// Zero-padded so that lexical ID order matches creation order.
+	return fmt.Sprintf("%s-%04d", prefix, s.nextID)
+}
+
+func (s *Server) createProject(name, description string) client.Project {
+	project := client.Project{
+		ID:          s.newID("proj"),
+		Name:        name,
+		Description: description,
+	}
+	s.projects = append(s.projects, project)
+	s.broadcast("project.created", map[string]any{"project": project})
+	return project
+}
+
+func (s *Server) createEpic(projectID, title, notes string, auto bool) client.Epic {
+	epic := client.Epic{
+		ID:        s.newID("epic"),
+		Title:     title,
+		Notes:     notes,
+		Status:    "todo",
+		ProjectID: projectID,
+		Auto:      auto,
+	}
+	s.epics = append(s.epics, epic)
+	s.broadcastEpic("epic.created", epic)
+	return epic
+}
+
+func (s *Server) projectIndex(id string) int {
+	return slices.IndexFunc(s.projects, func(p client.Project) bool { return p.ID == id })
+}
+
+func (s *Server) epicIndex(id string) int {
+	return slices.IndexFunc(s.epics, func(e client.Epic) bool { return e.ID == id })
+}
+
+func (s *Server) taskIndex(id string) int {
+	return slices.IndexFunc(s.tasks, func(t client.Task) bool { return t.ID == id })
+}
+
+// present returns a copy of task as the API reports it. A task is blocked
+// when it was stored as blocked or any of its dependencies is not done.
+func (s *Server) present(task client.Task) client.Task {
+	for _, depID := range task.DependsOn {
+		if i := s.taskIndex(depID); i >= 0 && s.tasks[i].Status != "done" {
+			task.Blocked = true
+			break
+		}
+	}
+	return task
+}
+
+func (s *Server) broadcastTask(eventType string, task client.Task) {
+	payload := map[string]any{"task": s.present(task)}
+	if i := s.epicIndex(task.EpicID); i >= 0 {
+		payload["epic"] = s.epics[i]
+	}
+	s.broadcast(eventType, payload)
+}
+
+func (s *Server) broadcastEpic(eventType string, epic client.Epic) {
+	s.broadcast(eventType, map[string]any{"epic": epic})
+}
+
+func (s *Server) broadcast(eventType string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	s.eventSeq++
+	event := Event{Type: eventType, Data: string(data), ID: strconv.Itoa(s.eventSeq)}
+	s.history = append(s.history, event)
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Slow subscriber, drop rather than block writers
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package fluxtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/sse"
+)
+
+func setupTest(t *testing.T) (*Server, *client.Client, string) {
+	t.Helper()
+	flux := New()
+	server := httptest.NewServer(flux)
+	t.Cleanup(func() {
+		flux.Close()
+		server.Close()
+	})
+	return flux, client.NewClient(server.URL), server.URL
+}
+
+func TestProjectEpicTaskCRUD(t *testing.T) {
+	_, c, _ := setupTest(t)
+
+	project, err := c.CreateProject("Proj", "desc")
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	epic, err := c.CreateEpic(project.ID, "Epic", "")
+	if err != nil {
+		t.Fatalf("CreateEpic: %v", err)
+	}
+	task, err := c.CreateTask(project.ID, "Task", "notes", epic.ID)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.Status != "todo" {
+		t.Errorf("expected new task status 'todo', got %q", task.Status)
+	}
+
+	tasks, err := c.ListTasks(project.ID, client.TaskFilters{EpicID: client.StringPtr(epic.ID)})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("expected [%s], got %+v", task.ID, tasks)
+	}
+
+	moved, err := c.MoveTaskStatus(task.ID, "in_progress", "claude")
+	if err != nil {
+		t.Fatalf("MoveTaskStatus: %v", err)
+	}
+	if moved.Status != "in_progress" {
+		t.Errorf("expected status 'in_progress', got %q", moved.Status)
+	}
+
+	todo, err := c.ListTasks(project.ID, client.TaskFilters{Status: client.StringPtr("todo")})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if len(todo) != 0 {
+		t.Errorf("expected no todo tasks, got %d", len(todo))
+	}
+
+	if err := c.DeleteTask(task.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if err := c.DeleteTask(task.ID); err == nil {
+		t.Error("expected error deleting a missing task")
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	_, c, _ := setupTest(t)
+
+	_, err := c.ListEpics("missing")
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 APIError, got %v", err)
+	}
+}
+
+func TestTaskComments(t *testing.T) {
+	flux, c, _ := setupTest(t)
+
+	project := flux.AddProject("Proj", "")
+	task := flux.AddTask(client.Task{Title: "Task", ProjectID: project.ID})
+
+	if _, err := c.AddTaskComment(task.ID, "Summary", "momentum"); err != nil {
+		t.Fatalf("AddTaskComment: %v", err)
+	}
+	if _, err := c.AddTaskComment(task.ID, "Please fix the typo", ""); err != nil {
+		t.Fatalf("AddTaskComment: %v", err)
+	}
+
+	got, err := c.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if len(got.Comments) != 2 || got.Comments[0].Author != "momentum" || got.Comments[1].Author != "user" {
+		t.Errorf("comments = %+v, want momentum's then the user's", got.Comments)
+	}
+	if _, err := c.AddTaskComment("missing", "Hi", ""); err == nil {
+		t.Error("expected error commenting on a missing task")
+	}
+}
+
+func TestConditionalTaskUpdate(t *testing.T) {
+	flux, c, _ := setupTest(t)
+
+	project := flux.AddProject("Proj", "")
+	task := flux.AddTask(client.Task{Title: "Task", ProjectID: project.ID})
+
+	claim := client.TaskUpdate{
+		Status:         client.StringPtr("in_progress"),
+		AgentName:      client.StringPtr("claude@host-a:1"),
+		ExpectedStatus: client.StringPtr("todo"),
+	}
+	updated, err := c.UpdateTask(task.ID, claim)
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if updated.AgentName != "claude@host-a:1" {
+		t.Errorf("agent_name = %q, want claude@host-a:1", updated.AgentName)
+	}
+
+	claim.AgentName = client.StringPtr("claude@host-b:1")
+	_, err = c.UpdateTask(task.ID, claim)
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 APIError, got %v", err)
+	}
+	if got, _ := flux.Task(task.ID); got.AgentName != "claude@host-a:1" {
+		t.Errorf("conflicting update changed agent_name to %q", got.AgentName)
+	}
+}
+
+func TestBlockedComputedFromDependencies(t *testing.T) {
+	flux, c, _ := setupTest(t)
+
+	project := flux.AddProject("Proj", "")
+	epic := flux.AddEpic(project.ID, "Epic", true)
+	first := flux.AddTask(client.Task{Title: "First", ProjectID: project.ID, EpicID: epic.ID})
+	second := flux.AddTask(client.Task{Title: "Second", ProjectID: project.ID, EpicID: epic.ID, DependsOn: []string{first.ID}})
+
+	if !second.Blocked {
+		t.Fatal("expected dependent task to be blocked")
+	}
+
+	if _, err := c.MoveTaskStatus(first.ID, "done"); err != nil {
+		t.Fatalf("MoveTaskStatus: %v", err)
+	}
+
+	got, ok := flux.Task(second.ID)
+	if !ok {
+		t.Fatal("task not found")
+	}
+	if got.Blocked {
+		t.Error("expected dependent task to be unblocked once dependency is done")
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	flux, c, url := setupTest(t)
+
+	project := flux.AddProject("Proj", "")
+	epic := flux.AddEpic(project.ID, "Epic", true)
+	task := flux.AddTask(client.Task{Title: "Task", ProjectID: project.ID, EpicID: epic.ID})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sub := sse.NewSubscriber(url)
+	events := sub.Start(ctx)
+	defer sub.Stop()
+
+	// Retry the update until the subscriber has connected and sees it.
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	status := "in_progress"
+	for {
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for task.status_changed")
+		case <-ticker.C:
+			if _, err := c.MoveTaskStatus(task.ID, status); err != nil {
+				t.Fatalf("MoveTaskStatus: %v", err)
+			}
+			if status == "in_progress" {
+				status = "todo"
+			} else {
+				status = "in_progress"
+			}
+		case event := <-events:
+			if event.Type != "task.status_changed" {
+				continue
+			}
+			var payload struct {
+				Task client.Task `json:"task"`
+				Epic client.Epic `json:"epic"`
+			}
+			if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+				t.Fatalf("invalid payload: %v", err)
+			}
+			if payload.Task.ID != task.ID {
+				t.Errorf("expected task %s, got %s", task.ID, payload.Task.ID)
+			}
+			if !payload.Epic.Auto {
+				t.Error("expected payload to include the auto epic")
+			}
+			return
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	flux, c, _ := setupTest(t)
+	flux.Seed()
+
+	projects, err := c.ListProjects()
+	if err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("expected 1 seeded project, got %d", len(projects))
+	}
+
+	epics, err := c.ListEpics(projects[0].ID)
+	if err != nil {
+		t.Fatalf("ListEpics: %v", err)
+	}
+	autoCount := 0
+	for _, e := range epics {
+		if e.Auto {
+			autoCount++
+		}
+	}
+	if autoCount != 1 {
+		t.Errorf("expected 1 auto epic, got %d", autoCount)
+	}
+}
+
+func TestEventStreamReplaysAfterLastEventID(t *testing.T) {
+	flux, _, url := setupTest(t)
+
+	project := flux.AddProject("Proj", "")
+	first := flux.AddEpic(project.ID, "First", true)
+	flux.AddEpic(project.ID, "Second", true)
+	flux.AddEpic(project.ID, "Third", true)
+
+	// Resume after the first epic.created event (the project came before it)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sub := sse.NewSubscriber(url)
+	sub.ResumeFrom("2")
+	events := sub.Start(ctx)
+	defer sub.Stop()
+
+	var titles []string
+	for len(titles) < 2 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timed out, replayed %v", titles)
+		case event := <-events:
+			var payload struct {
+				Epic client.Epic `json:"epic"`
+			}
+			if err := json.Unmarshal([]byte(event.Data), &payload); err != nil {
+				t.Fatalf("invalid payload: %v", err)
+			}
+			if payload.Epic.ID == first.ID {
+				t.Errorf("replayed event %s from before Last-Event-ID", event.ID)
+			}
+			titles = append(titles, payload.Epic.Title)
+		}
+	}
+	if titles[0] != "Second" || titles[1] != "Third" {
+		t.Errorf("expected Second and Third replayed in order, got %v", titles)
+	}
+	if got := sub.LastEventID(); got != "4" {
+		t.Errorf("expected resume position 4, got %q", got)
+	}
+}
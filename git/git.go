@@ -0,0 +1,141 @@
+// Package git runs the git commands momentum uses to report on, and
+// optionally commit, the work an agent left in its workdir.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNotRepository is returned when the directory is not inside a git
+// work tree.
+var ErrNotRepository = errors.New("not a git repository")
+
+// Repo is a git work tree.
+type Repo struct {
+	dir string
+}
+
+// Open returns the repository containing dir, or ErrNotRepository.
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	r := &Repo{dir: dir}
+	if out, err := r.run(ctx, "rev-parse", "--is-inside-work-tree"); err != nil || out != "true" {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNotRepository)
+	}
+	return r, nil
+}
+
+// Dir returns the directory git commands run in.
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// run runs git in the repository and returns its output without the
+// trailing newline, keeping the leading spaces of porcelain formats. Failures
+// carry git's own error message.
+func (r *Repo) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// Head returns the commit HEAD points at, or "" in a repository without
+// commits.
+func (r *Repo) Head(ctx context.Context) (string, error) {
+	if _, err := r.run(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return "", nil
+	}
+	return r.run(ctx, "rev-parse", "HEAD")
+}
+
+// Status returns `git status --short`: one line per changed or untracked
+// file, or "" when the work tree is clean.
+func (r *Repo) Status(ctx context.Context) (string, error) {
+	return r.run(ctx, "status", "--short")
+}
+
+// DiffStat returns `git diff --stat` of the work tree and index against
+// HEAD, or "" when nothing tracked has changed.
+func (r *Repo) DiffStat(ctx context.Context) (string, error) {
+	head, err := r.Head(ctx)
+	if err != nil || head == "" {
+		return "", err
+	}
+	return r.run(ctx, "diff", "--stat", "HEAD")
+}
+
+// CommitsSince returns the one-line summaries of commits made on top of
+// base, newest first. An empty base means none are known.
+func (r *Repo) CommitsSince(ctx context.Context, base string) ([]string, error) {
+	if base == "" {
+		return nil, nil
+	}
+	out, err := r.run(ctx, "log", "--oneline", "--no-decorate", base+"..HEAD")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// CurrentBranch returns the name of the checked-out branch, or "" when
+// HEAD is detached.
+func (r *Repo) CurrentBranch(ctx context.Context) (string, error) {
+	if _, err := r.run(ctx, "symbolic-ref", "--quiet", "HEAD"); err != nil {
+		return "", nil
+	}
+	return r.run(ctx, "symbolic-ref", "--short", "HEAD")
+}
+
+// CreateBranch checks out a new branch at HEAD, keeping the changes in the
+// work tree. An existing branch of that name is reset to HEAD.
+func (r *Repo) CreateBranch(ctx context.Context, branch string) error {
+	_, err := r.run(ctx, "checkout", "-q", "-B", branch)
+	return err
+}
+
+// Checkout checks out a branch or commit.
+func (r *Repo) Checkout(ctx context.Context, ref string) error {
+	_, err := r.run(ctx, "checkout", "-q", ref)
+	return err
+}
+
+// CommitAll stages every change in the work tree, including untracked
+// files, and commits it. It returns the new commit, or "" when there was
+// nothing to commit.
+func (r *Repo) CommitAll(ctx context.Context, message string) (string, error) {
+	if _, err := r.run(ctx, "add", "-A"); err != nil {
+		return "", err
+	}
+	if _, err := r.run(ctx, "diff", "--cached", "--quiet"); err == nil {
+		return "", nil
+	}
+	if _, err := r.run(ctx, "commit", "-q", "-m", message); err != nil {
+		return "", err
+	}
+	return r.Head(ctx)
+}
+
+// Push pushes a local branch to the branch of the same name on remote.
+// With force the remote branch is replaced as long as it is where the
+// last fetch or push left it.
+func (r *Repo) Push(ctx context.Context, remote, branch string, force bool) error {
+	args := []string{"push", "-q"}
+	if force {
+		args = append(args, "--force-with-lease")
+	}
+	ref := "refs/heads/" + branch
+	_, err := r.run(ctx, append(args, remote, ref+":"+ref)...)
+	return err
+}
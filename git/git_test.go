@@ -0,0 +1,111 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/gittest"
+)
+
+func TestOpen_NotRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	_, err := Open(context.Background(), t.TempDir())
+	if !errors.Is(err, ErrNotRepository) {
+		t.Errorf("Open() error = %v, want ErrNotRepository", err)
+	}
+}
+
+func TestRepo_Report(t *testing.T) {
+	ctx := context.Background()
+	dir := gittest.NewRepo(t)
+	repo, err := Open(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := repo.Head(ctx)
+	if err != nil || len(base) != 40 {
+		t.Fatalf("Head() = %q, %v", base, err)
+	}
+	if status, _ := repo.Status(ctx); status != "" {
+		t.Errorf("Status() of clean tree = %q", status)
+	}
+
+	gittest.WriteFile(t, dir, "main.go", "package main\n")
+	gittest.Run(t, dir, "add", "main.go")
+	gittest.Run(t, dir, "commit", "-q", "-m", "Add main")
+	gittest.WriteFile(t, dir, "README.md", "hello\nworld\n")
+	gittest.WriteFile(t, dir, "notes.txt", "new\n")
+
+	status, err := repo.Status(ctx)
+	if err != nil || !strings.Contains(status, " M README.md") || !strings.Contains(status, "?? notes.txt") {
+		t.Errorf("Status() = %q, %v", status, err)
+	}
+	stat, err := repo.DiffStat(ctx)
+	if err != nil || !strings.Contains(stat, "README.md") || !strings.Contains(stat, "1 file changed") {
+		t.Errorf("DiffStat() = %q, %v", stat, err)
+	}
+	commits, err := repo.CommitsSince(ctx, base)
+	if err != nil || len(commits) != 1 || !strings.HasSuffix(commits[0], "Add main") {
+		t.Errorf("CommitsSince() = %q, %v", commits, err)
+	}
+	if commits, _ := repo.CommitsSince(ctx, ""); commits != nil {
+		t.Errorf("CommitsSince(\"\") = %q, want none", commits)
+	}
+}
+
+func TestRepo_CommitAndPush(t *testing.T) {
+	ctx := context.Background()
+	dir := gittest.NewRepo(t)
+	remote := t.TempDir()
+	gittest.Run(t, remote, "init", "-q", "--bare")
+	gittest.Run(t, dir, "remote", "add", "origin", remote)
+	repo, err := Open(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if branch, _ := repo.CurrentBranch(ctx); branch != "main" {
+		t.Errorf("CurrentBranch() = %q, want main", branch)
+	}
+	if sha, err := repo.CommitAll(ctx, "Nothing"); err != nil || sha != "" {
+		t.Errorf("CommitAll() of clean tree = %q, %v", sha, err)
+	}
+
+	gittest.WriteFile(t, dir, "feature.go", "package feature\n")
+	if err := repo.CreateBranch(ctx, "momentum/task-1"); err != nil {
+		t.Fatal(err)
+	}
+	sha, err := repo.CommitAll(ctx, "[task-1] Add feature")
+	if err != nil || len(sha) != 40 {
+		t.Fatalf("CommitAll() = %q, %v", sha, err)
+	}
+	if err := repo.Push(ctx, "origin", "momentum/task-1", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Checkout(ctx, "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := gittest.Run(t, remote, "rev-parse", "momentum/task-1"); got != sha {
+		t.Errorf("remote branch at %q, want %q", got, sha)
+	}
+	if got := gittest.Run(t, remote, "log", "-1", "--format=%s", "momentum/task-1"); got != "[task-1] Add feature" {
+		t.Errorf("remote commit message = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "feature.go")); !os.IsNotExist(err) {
+		t.Error("expected feature.go to stay on the task branch")
+	}
+
+	gittest.Run(t, dir, "checkout", "-q", "--detach")
+	if branch, _ := repo.CurrentBranch(ctx); branch != "" {
+		t.Errorf("CurrentBranch() with detached HEAD = %q", branch)
+	}
+}
@@ -0,0 +1,64 @@
+// Package gittest creates throwaway git repositories for tests.
+//
+// Every helper fails the test on error, and those that create repositories
+// skip it when git is not installed.
+package gittest
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Init turns dir into a repository on branch main with one commit, which
+// adds README.md.
+func Init(t testing.TB, dir string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	Run(t, dir, "init", "-q", "-b", "main")
+	Run(t, dir, "config", "user.email", "test@example.com")
+	Run(t, dir, "config", "user.name", "Test")
+	WriteFile(t, dir, "README.md", "hello\n")
+	Run(t, dir, "add", "-A")
+	Run(t, dir, "commit", "-q", "-m", "Initial commit")
+}
+
+// NewRepo creates a repository as Init does in a temp directory and
+// returns its path.
+func NewRepo(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	Init(t, dir)
+	return dir
+}
+
+// BareRemote adds a bare repository as dir's origin and returns its path.
+func BareRemote(t testing.TB, dir string) string {
+	t.Helper()
+	remote := t.TempDir()
+	Run(t, remote, "init", "-q", "--bare")
+	Run(t, dir, "remote", "add", "origin", remote)
+	return remote
+}
+
+// Run runs git in dir and returns its trimmed output.
+func Run(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// WriteFile writes content to the file name in dir.
+func WriteFile(t testing.TB, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,152 @@
+package selection
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// TaskWeight controls how much each task counts towards a dependency chain
+// under the critical-path strategy.
+type TaskWeight string
+
+const (
+	// TaskWeightCount counts every task as 1 (default).
+	TaskWeightCount TaskWeight = "count"
+
+	// TaskWeightNotes counts one unit per started 500 characters of notes.
+	TaskWeightNotes TaskWeight = "notes"
+
+	// TaskWeightEstimate uses the task's estimate field, or 1 when unset.
+	TaskWeightEstimate TaskWeight = "estimate"
+)
+
+// notesPerUnit is the notes length that counts as one unit of work.
+const notesPerUnit = 500
+
+// ParseTaskWeight validates a task weight name. An empty name means
+// TaskWeightCount.
+func ParseTaskWeight(name string) (TaskWeight, error) {
+	switch w := TaskWeight(strings.ToLower(strings.TrimSpace(name))); w {
+	case "":
+		return TaskWeightCount, nil
+	case TaskWeightCount, TaskWeightNotes, TaskWeightEstimate:
+		return w, nil
+	default:
+		return "", fmt.Errorf("invalid task weight %q (use count, notes, or estimate)", name)
+	}
+}
+
+// Of returns the weight of a single task, always at least 1.
+func (w TaskWeight) Of(task client.Task) int {
+	switch w {
+	case TaskWeightNotes:
+		return max(1, (len(task.Notes)+notesPerUnit-1)/notesPerUnit)
+	case TaskWeightEstimate:
+		if task.Estimate != nil && *task.Estimate > 0 {
+			return *task.Estimate
+		}
+	}
+	return 1
+}
+
+// GraphStrategy is a Strategy that orders tasks using the dependency graph
+// of the board. The selector calls WithGraph before each sort.
+type GraphStrategy interface {
+	Strategy
+
+	// WithGraph returns a Strategy bound to g
+	WithGraph(g *Graph) Strategy
+}
+
+// CriticalPath picks the task heading the longest remaining dependency
+// chain first, so parallel agents unblock as much downstream work as
+// possible. Ties fall back to PriorityThenAge.
+var CriticalPath Strategy = NewCriticalPath(TaskWeightCount)
+
+// NewCriticalPath returns the critical-path strategy weighting tasks by w.
+func NewCriticalPath(w TaskWeight) Strategy {
+	return &criticalPath{weight: w}
+}
+
+type criticalPath struct {
+	weight TaskWeight
+	chain  map[string]int
+}
+
+func (c *criticalPath) Name() string { return "critical-path" }
+
+func (c *criticalPath) WithGraph(g *Graph) Strategy {
+	return &criticalPath{weight: c.weight, chain: g.chainLengths(c.weight.Of)}
+}
+
+func (c *criticalPath) Less(a, b client.Task) bool {
+	if ca, cb := c.chain[a.ID], c.chain[b.ID]; ca != cb {
+		return ca > cb
+	}
+	return priorityThenAge(a, b)
+}
+
+// chainLengths returns, for every task, the total weight of the longest
+// chain of unfinished work that starts at the task: the task itself plus
+// its heaviest chain of dependents. Tasks in epics that depend on the
+// task's epic count as dependents too. Cycles are cut where they close.
+func (g *Graph) chainLengths(weight func(client.Task) int) map[string]int {
+	dependents := make(map[string][]string)
+	for _, id := range g.taskOrder {
+		task := g.tasks[id]
+		if task.Status == g.statuses.Done {
+			continue
+		}
+		for _, dep := range task.DependsOn {
+			dependents[dep] = append(dependents[dep], id)
+		}
+	}
+	epicDependents := make(map[string][]string)
+	for _, id := range g.epicOrder {
+		for _, dep := range g.epics[id].DependsOn {
+			epicDependents[dep] = append(epicDependents[dep], id)
+		}
+	}
+
+	chain := make(map[string]int, len(g.tasks))
+	visiting := make(map[string]bool)
+
+	var visit func(id string) int
+	visit = func(id string) int {
+		if n, ok := chain[id]; ok {
+			return n
+		}
+		if visiting[id] {
+			return 0
+		}
+		visiting[id] = true
+		task := g.tasks[id]
+
+		longest := 0
+		follow := func(next string) {
+			if g.tasks[next].Status == g.statuses.Done {
+				return
+			}
+			longest = max(longest, visit(next))
+		}
+		for _, next := range dependents[id] {
+			follow(next)
+		}
+		for _, epicID := range epicDependents[task.EpicID] {
+			for _, next := range g.epicTasks[epicID] {
+				follow(next)
+			}
+		}
+
+		visiting[id] = false
+		chain[id] = weight(task) + longest
+		return chain[id]
+	}
+
+	for _, id := range g.taskOrder {
+		visit(id)
+	}
+	return chain
+}
@@ -0,0 +1,126 @@
+package selection
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+func TestParseTaskWeight(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected TaskWeight
+		wantErr  bool
+	}{
+		{"", TaskWeightCount, false},
+		{"count", TaskWeightCount, false},
+		{" Notes ", TaskWeightNotes, false},
+		{"estimate", TaskWeightEstimate, false},
+		{"lines", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTaskWeight(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParseTaskWeight(%q) = %q, %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestTaskWeightOf(t *testing.T) {
+	tests := []struct {
+		weight   TaskWeight
+		task     client.Task
+		expected int
+	}{
+		{TaskWeightCount, client.Task{Estimate: client.IntPtr(8)}, 1},
+		{TaskWeightEstimate, client.Task{Estimate: client.IntPtr(8)}, 8},
+		{TaskWeightEstimate, client.Task{}, 1},
+		{TaskWeightEstimate, client.Task{Estimate: client.IntPtr(0)}, 1},
+		{TaskWeightNotes, client.Task{}, 1},
+		{TaskWeightNotes, client.Task{Notes: strings.Repeat("x", 500)}, 1},
+		{TaskWeightNotes, client.Task{Notes: strings.Repeat("x", 1201)}, 3},
+	}
+	for _, tt := range tests {
+		if got := tt.weight.Of(tt.task); got != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.weight, tt.expected, got)
+		}
+	}
+}
+
+func TestChainLengths(t *testing.T) {
+	// a → b → c, d alone, e in epic-2 which depends on epic-1 (a's epic),
+	// done tasks do not count.
+	epics := []client.Epic{
+		{ID: "epic-1"},
+		{ID: "epic-2", DependsOn: []string{"epic-1"}},
+	}
+	tasks := []client.Task{
+		{ID: "a", Status: "todo", EpicID: "epic-1"},
+		{ID: "b", Status: "todo", EpicID: "epic-1", DependsOn: []string{"a"}},
+		{ID: "c", Status: "todo", EpicID: "epic-1", DependsOn: []string{"b"}},
+		{ID: "d", Status: "todo", EpicID: "epic-1"},
+		{ID: "e", Status: "todo", EpicID: "epic-2", Estimate: client.IntPtr(5)},
+		{ID: "f", Status: "done", EpicID: "epic-2"},
+	}
+	g := NewGraph(tasks, epics)
+
+	count := g.chainLengths(TaskWeightCount.Of)
+	expected := map[string]int{"a": 4, "b": 3, "c": 2, "d": 2, "e": 1}
+	for id, want := range expected {
+		if count[id] != want {
+			t.Errorf("count %s: expected %d, got %d", id, want, count[id])
+		}
+	}
+
+	estimate := g.chainLengths(TaskWeightEstimate.Of)
+	if estimate["a"] != 8 || estimate["d"] != 6 {
+		t.Errorf("estimate: expected a=8 d=6, got a=%d d=%d", estimate["a"], estimate["d"])
+	}
+}
+
+func TestChainLengthsSurvivesCycles(t *testing.T) {
+	g := NewGraph([]client.Task{
+		{ID: "a", Status: "todo", DependsOn: []string{"b"}},
+		{ID: "b", Status: "todo", DependsOn: []string{"a"}},
+	}, nil)
+	chain := g.chainLengths(TaskWeightCount.Of)
+	if chain["a"] == 0 || chain["b"] == 0 {
+		t.Errorf("expected finite chain lengths, got %v", chain)
+	}
+}
+
+func TestSelectorCriticalPath(t *testing.T) {
+	// t-short is newest and alone; t-head unblocks a chain of two more tasks.
+	m := newMockServer()
+	m.projects = []client.Project{{ID: "proj-1"}}
+	m.epics["proj-1"] = []client.Epic{{ID: "epic-1", ProjectID: "proj-1", Auto: true}}
+	m.tasks["proj-1"] = []client.Task{
+		{ID: "t-head", Status: "todo", ProjectID: "proj-1", EpicID: "epic-1"},
+		{ID: "t-mid", Status: "todo", ProjectID: "proj-1", EpicID: "epic-1", DependsOn: []string{"t-head"}, Blocked: true},
+		{ID: "t-tail", Status: "todo", ProjectID: "proj-1", EpicID: "epic-1", DependsOn: []string{"t-mid"}, Blocked: true},
+		{ID: "t-short", Status: "todo", ProjectID: "proj-1", EpicID: "epic-1", Estimate: client.IntPtr(10)},
+	}
+	server, c := setupTest(m)
+	defer server.Close()
+
+	tests := []struct {
+		strategy Strategy
+		expected string
+	}{
+		{Newest, "t-short"},
+		{CriticalPath, "t-head"},
+		{NewCriticalPath(TaskWeightEstimate), "t-short"},
+	}
+	for _, tt := range tests {
+		selector := NewSelector(c, "proj-1", "", "")
+		selector.SetStrategy(tt.strategy)
+		task, err := selector.SelectTask()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if task.ID != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.strategy.Name(), tt.expected, task.ID)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package selection
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// Fairness controls how candidates from different projects share agents.
+type Fairness string
+
+const (
+	// FairnessNone merges all candidates and lets the strategy decide (default).
+	FairnessNone Fairness = "none"
+
+	// FairnessRoundRobin serves the project that was picked least recently.
+	FairnessRoundRobin Fairness = "round-robin"
+
+	// FairnessWeighted serves projects in proportion to their weights.
+	// Projects without a configured weight get weight 1.
+	FairnessWeighted Fairness = "weighted"
+)
+
+// ParseFairness validates a fairness name. An empty name means FairnessNone.
+func ParseFairness(name string) (Fairness, error) {
+	switch f := Fairness(strings.ToLower(strings.TrimSpace(name))); f {
+	case "":
+		return FairnessNone, nil
+	case FairnessNone, FairnessRoundRobin, FairnessWeighted:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid fairness %q (use none, round-robin, or weighted)", name)
+	}
+}
+
+// fairScheduler tracks how often each project has been served.
+type fairScheduler struct {
+	mu       sync.Mutex
+	mode     Fairness
+	weights  map[string]int
+	served   map[string]int
+	lastPick map[string]uint64
+	picks    uint64
+}
+
+func newFairScheduler() *fairScheduler {
+	return &fairScheduler{
+		mode:     FairnessNone,
+		served:   make(map[string]int),
+		lastPick: make(map[string]uint64),
+	}
+}
+
+// clone returns a copy of f that records picks independently.
+func (f *fairScheduler) clone() *fairScheduler {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &fairScheduler{
+		mode:     f.mode,
+		weights:  f.weights,
+		served:   maps.Clone(f.served),
+		lastPick: maps.Clone(f.lastPick),
+		picks:    f.picks,
+	}
+}
+
+func (f *fairScheduler) configure(mode Fairness, weights map[string]int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mode = mode
+	f.weights = weights
+}
+
+func (f *fairScheduler) weight(projectID string) int {
+	if w, ok := f.weights[projectID]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// record notes that a task from projectID was handed to an agent.
+func (f *fairScheduler) record(projectID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.picks++
+	f.served[projectID]++
+	f.lastPick[projectID] = f.picks
+}
+
+// pick chooses among candidates that are already ordered by strategy.
+// It returns the index of the first candidate from the project that is
+// owed a turn, or 0 when fairness is disabled.
+func (f *fairScheduler) pick(candidates []client.Task) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.mode == FairnessNone || len(candidates) == 0 {
+		return 0
+	}
+
+	best := -1
+	seen := make(map[string]bool)
+	for i, task := range candidates {
+		if seen[task.ProjectID] {
+			continue
+		}
+		seen[task.ProjectID] = true
+		if best < 0 || f.owedBefore(task.ProjectID, candidates[best].ProjectID) {
+			best = i
+		}
+	}
+	return best
+}
+
+// owedBefore reports whether project a should be served before project b.
+// Ties keep the strategy order.
+func (f *fairScheduler) owedBefore(a, b string) bool {
+	switch f.mode {
+	case FairnessRoundRobin:
+		return f.lastPick[a] < f.lastPick[b]
+	case FairnessWeighted:
+		// Compare served/weight without floating point.
+		return f.served[a]*f.weight(b) < f.served[b]*f.weight(a)
+	}
+	return false
+}
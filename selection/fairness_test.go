@@ -0,0 +1,140 @@
+package selection
+
+import (
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// setupFairnessTest creates two auto projects. proj-big has many tasks with
+// lexically larger IDs, so without fairness it always wins under Newest.
+func setupFairnessTest(t *testing.T) *Selector {
+	t.Helper()
+	m := newMockServer()
+	m.projects = []client.Project{{ID: "proj-big"}, {ID: "proj-small"}}
+	m.epics["proj-big"] = []client.Epic{{ID: "epic-big", ProjectID: "proj-big", Auto: true}}
+	m.epics["proj-small"] = []client.Epic{{ID: "epic-small", ProjectID: "proj-small", Auto: true}}
+	m.tasks["proj-big"] = []client.Task{
+		{ID: "z-1", Status: "todo", ProjectID: "proj-big", EpicID: "epic-big"},
+		{ID: "z-2", Status: "todo", ProjectID: "proj-big", EpicID: "epic-big"},
+		{ID: "z-3", Status: "todo", ProjectID: "proj-big", EpicID: "epic-big"},
+		{ID: "z-4", Status: "todo", ProjectID: "proj-big", EpicID: "epic-big"},
+	}
+	m.tasks["proj-small"] = []client.Task{
+		{ID: "a-1", Status: "todo", ProjectID: "proj-small", EpicID: "epic-small"},
+		{ID: "a-2", Status: "todo", ProjectID: "proj-small", EpicID: "epic-small"},
+	}
+	server, c := setupTest(m)
+	t.Cleanup(server.Close)
+	return NewSelector(c, "", "", "")
+}
+
+// drain selects n tasks, recording each pick and excluding it afterwards.
+func drain(t *testing.T, selector *Selector, n int) []string {
+	t.Helper()
+	excluded := make(map[string]bool)
+	var projects []string
+	for i := 0; i < n; i++ {
+		task, err := selector.SelectTaskExcluding(excluded)
+		if err != nil {
+			t.Fatalf("pick %d: unexpected error: %v", i, err)
+		}
+		selector.RecordPick(task)
+		excluded[task.ID] = true
+		projects = append(projects, task.ProjectID)
+	}
+	return projects
+}
+
+func assertProjects(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFairnessNone(t *testing.T) {
+	selector := setupFairnessTest(t)
+	got := drain(t, selector, 4)
+	assertProjects(t, got, []string{"proj-big", "proj-big", "proj-big", "proj-big"})
+}
+
+func TestFairnessRoundRobin(t *testing.T) {
+	selector := setupFairnessTest(t)
+	selector.SetFairness(FairnessRoundRobin, nil)
+	got := drain(t, selector, 5)
+	assertProjects(t, got, []string{"proj-big", "proj-small", "proj-big", "proj-small", "proj-big"})
+}
+
+func TestFairnessWeighted(t *testing.T) {
+	selector := setupFairnessTest(t)
+	selector.SetFairness(FairnessWeighted, map[string]int{"proj-big": 1, "proj-small": 2})
+	got := drain(t, selector, 3)
+	assertProjects(t, got, []string{"proj-big", "proj-small", "proj-small"})
+}
+
+func TestFairnessUnrecordedPicksDoNotRotate(t *testing.T) {
+	selector := setupFairnessTest(t)
+	selector.SetFairness(FairnessRoundRobin, nil)
+
+	for i := 0; i < 3; i++ {
+		task, err := selector.SelectTask()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if task.ProjectID != "proj-big" {
+			t.Fatalf("expected availability checks to keep returning proj-big, got %s", task.ProjectID)
+		}
+	}
+}
+
+func TestQueue_SimulatesFairness(t *testing.T) {
+	selector := setupFairnessTest(t)
+	selector.SetFairness(FairnessRoundRobin, nil)
+
+	queue, evals, err := selector.Queue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evals) != 6 {
+		t.Errorf("expected a verdict on all 6 tasks, got %d", len(evals))
+	}
+	var got []string
+	for _, task := range queue {
+		got = append(got, task.ID)
+	}
+	assertProjects(t, got, []string{"z-4", "a-2", "z-3", "a-1", "z-2", "z-1"})
+
+	// Queueing records no picks, so selection starts afresh
+	assertProjects(t, drain(t, selector, 2), []string{"proj-big", "proj-small"})
+}
+
+func TestParseFairness(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Fairness
+		wantErr bool
+	}{
+		{"", FairnessNone, false},
+		{"none", FairnessNone, false},
+		{"Round-Robin", FairnessRoundRobin, false},
+		{"weighted", FairnessWeighted, false},
+		{"lottery", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFairness(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFairness(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseFairness(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package selection
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// Filter narrows the candidate tasks by labels, assignee and text.
+// Each non-empty criterion must hold for a task to qualify; within a
+// criterion any listed value is enough. The zero value matches everything.
+type Filter struct {
+	// Labels requires at least one of these labels (case-insensitive)
+	Labels []string
+	// ExcludeLabels rejects tasks carrying any of these labels
+	ExcludeLabels []string
+	// Assignees requires the task to be assigned to one of these users
+	Assignees []string
+	// Match requires at least one pattern to match the title or notes
+	Match []*regexp.Regexp
+}
+
+// NewFilter builds a Filter, compiling the match patterns.
+func NewFilter(labels, excludeLabels, assignees, patterns []string) (Filter, error) {
+	f := Filter{
+		Labels:        labels,
+		ExcludeLabels: excludeLabels,
+		Assignees:     assignees,
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid match pattern %q: %w", p, err)
+		}
+		f.Match = append(f.Match, re)
+	}
+	return f, nil
+}
+
+// IsEmpty reports whether the filter has no criteria.
+func (f Filter) IsEmpty() bool {
+	return len(f.Labels) == 0 && len(f.ExcludeLabels) == 0 && len(f.Assignees) == 0 && len(f.Match) == 0
+}
+
+// Matches reports whether task satisfies every criterion.
+func (f Filter) Matches(task client.Task) bool {
+	return len(f.Mismatches(task)) == 0
+}
+
+// Mismatches describes each criterion task fails, e.g.
+// "has excluded label wip". It returns nil when the task matches.
+func (f Filter) Mismatches(task client.Task) []string {
+	var out []string
+	if len(f.Labels) > 0 && !hasAnyLabel(task, f.Labels) {
+		out = append(out, "missing label "+strings.Join(f.Labels, " or "))
+	}
+	for _, label := range f.ExcludeLabels {
+		if hasAnyLabel(task, []string{label}) {
+			out = append(out, "has excluded label "+label)
+		}
+	}
+	if len(f.Assignees) > 0 && !slices.ContainsFunc(f.Assignees, func(a string) bool {
+		return strings.EqualFold(a, task.Assignee)
+	}) {
+		assignee := task.Assignee
+		if assignee == "" {
+			assignee = "nobody"
+		}
+		out = append(out, fmt.Sprintf("assigned to %s, not %s", assignee, strings.Join(f.Assignees, " or ")))
+	}
+	if len(f.Match) > 0 && !slices.ContainsFunc(f.Match, func(re *regexp.Regexp) bool {
+		return re.MatchString(task.Title) || re.MatchString(task.Notes)
+	}) {
+		patterns := make([]string, 0, len(f.Match))
+		for _, re := range f.Match {
+			patterns = append(patterns, "/"+re.String()+"/")
+		}
+		out = append(out, "title and notes do not match "+strings.Join(patterns, " or "))
+	}
+	return out
+}
+
+// String describes the filter for display, e.g. "labels: frontend · not: wip".
+func (f Filter) String() string {
+	var parts []string
+	if len(f.Labels) > 0 {
+		parts = append(parts, "labels: "+strings.Join(f.Labels, ", "))
+	}
+	if len(f.ExcludeLabels) > 0 {
+		parts = append(parts, "not: "+strings.Join(f.ExcludeLabels, ", "))
+	}
+	if len(f.Assignees) > 0 {
+		parts = append(parts, "assignee: "+strings.Join(f.Assignees, ", "))
+	}
+	if len(f.Match) > 0 {
+		patterns := make([]string, 0, len(f.Match))
+		for _, re := range f.Match {
+			patterns = append(patterns, "/"+re.String()+"/")
+		}
+		parts = append(parts, "match: "+strings.Join(patterns, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func hasAnyLabel(task client.Task, labels []string) bool {
+	for _, want := range labels {
+		for _, have := range task.Labels {
+			if strings.EqualFold(want, have) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package selection
+
+import (
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+func TestFilterMatches(t *testing.T) {
+	task := client.Task{
+		ID:       "task-1",
+		Title:    "UI: add dark mode",
+		Notes:    "Touches the settings page",
+		Labels:   []string{"Frontend", "design"},
+		Assignee: "alice",
+	}
+
+	tests := []struct {
+		name          string
+		labels        []string
+		excludeLabels []string
+		assignees     []string
+		patterns      []string
+		want          bool
+	}{
+		{name: "empty filter matches", want: true},
+		{name: "label matches case-insensitively", labels: []string{"frontend"}, want: true},
+		{name: "any listed label is enough", labels: []string{"backend", "design"}, want: true},
+		{name: "missing label rejects", labels: []string{"backend"}, want: false},
+		{name: "excluded label rejects", excludeLabels: []string{"design"}, want: false},
+		{name: "other excluded label passes", excludeLabels: []string{"wip"}, want: true},
+		{name: "assignee matches", assignees: []string{"bob", "Alice"}, want: true},
+		{name: "assignee mismatch rejects", assignees: []string{"bob"}, want: false},
+		{name: "title regex matches", patterns: []string{"^UI:"}, want: true},
+		{name: "notes regex matches", patterns: []string{"settings"}, want: true},
+		{name: "regex mismatch rejects", patterns: []string{"^API:"}, want: false},
+		{name: "all criteria must hold", labels: []string{"frontend"}, assignees: []string{"bob"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFilter(tt.labels, tt.excludeLabels, tt.assignees, tt.patterns)
+			if err != nil {
+				t.Fatalf("NewFilter: %v", err)
+			}
+			if got := f.Matches(task); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFilterInvalidPattern(t *testing.T) {
+	if _, err := NewFilter(nil, nil, nil, []string{"("}); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+func TestFilterString(t *testing.T) {
+	f, err := NewFilter([]string{"frontend"}, []string{"wip"}, nil, []string{"^UI:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "labels: frontend · not: wip · match: /^UI:/"
+	if got := f.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if !(Filter{}).IsEmpty() || f.IsEmpty() {
+		t.Error("IsEmpty() returned unexpected result")
+	}
+}
+
+func TestSelectorAppliesFilter(t *testing.T) {
+	m := newMockServer()
+	m.projects = []client.Project{{ID: "proj-1"}}
+	m.epics["proj-1"] = []client.Epic{{ID: "epic-1", ProjectID: "proj-1", Auto: true}}
+	m.tasks["proj-1"] = []client.Task{
+		{ID: "task-1", Status: "todo", EpicID: "epic-1", Labels: []string{"backend"}},
+		{ID: "task-2", Status: "todo", EpicID: "epic-1", Labels: []string{"frontend"}},
+		{ID: "task-3", Status: "todo", EpicID: "epic-1", Labels: []string{"backend", "wip"}},
+	}
+	server, c := setupTest(m)
+	defer server.Close()
+
+	selector := NewSelector(c, "proj-1", "", "")
+	f, _ := NewFilter([]string{"backend"}, []string{"wip"}, nil, nil)
+	selector.SetFilter(f)
+
+	task, err := selector.SelectTask()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.ID != "task-1" {
+		t.Errorf("expected task-1, got %s", task.ID)
+	}
+}
@@ -0,0 +1,316 @@
+package selection
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// Statuses names the board statuses selection reads. Empty fields keep
+// their defaults.
+type Statuses struct {
+	// Queued is where tasks wait to be picked up (default "todo")
+	Queued string
+	// Done is where finished tasks and epics end up (default "done")
+	Done string
+}
+
+func (s Statuses) withDefaults() Statuses {
+	if s.Queued == "" {
+		s.Queued = "todo"
+	}
+	if s.Done == "" {
+		s.Done = "done"
+	}
+	return s
+}
+
+// Graph is the dependency DAG of a set of tasks and epics.
+//
+// A task is ready when every task it depends on is done and every epic its
+// own epic depends on is done. Dependencies on IDs outside the graph cannot
+// be checked locally and are left to Flux's Blocked flag.
+type Graph struct {
+	statuses  Statuses
+	tasks     map[string]client.Task
+	epics     map[string]client.Epic
+	taskOrder []string
+	epicOrder []string
+	// epicTasks maps epic IDs to the IDs of their tasks
+	epicTasks map[string][]string
+	// inCycle marks task and epic IDs that are part of a dependency cycle
+	inCycle map[string]bool
+	cycles  [][]string
+}
+
+// NewGraph builds the dependency graph for tasks and epics. Tasks and epics
+// keep their given order for display.
+func NewGraph(tasks []client.Task, epics []client.Epic) *Graph {
+	g := &Graph{
+		statuses:  Statuses{}.withDefaults(),
+		tasks:     make(map[string]client.Task, len(tasks)),
+		epics:     make(map[string]client.Epic, len(epics)),
+		epicTasks: make(map[string][]string),
+		inCycle:   make(map[string]bool),
+	}
+	for _, epic := range epics {
+		if _, ok := g.epics[epic.ID]; ok {
+			continue
+		}
+		g.epics[epic.ID] = epic
+		g.epicOrder = append(g.epicOrder, epic.ID)
+	}
+	for _, task := range tasks {
+		if _, ok := g.tasks[task.ID]; ok {
+			continue
+		}
+		g.tasks[task.ID] = task
+		g.taskOrder = append(g.taskOrder, task.ID)
+		if task.EpicID != "" {
+			g.epicTasks[task.EpicID] = append(g.epicTasks[task.EpicID], task.ID)
+		}
+	}
+
+	g.findCycles(g.taskOrder, func(id string) []string { return g.tasks[id].DependsOn }, func(id string) bool {
+		_, ok := g.tasks[id]
+		return ok
+	})
+	g.findCycles(g.epicOrder, func(id string) []string { return g.epics[id].DependsOn }, func(id string) bool {
+		_, ok := g.epics[id]
+		return ok
+	})
+	return g
+}
+
+// SetStatuses configures which statuses count as queued and done.
+func (g *Graph) SetStatuses(s Statuses) {
+	g.statuses = s.withDefaults()
+}
+
+// Cycles returns every dependency cycle found, each as a list of IDs in
+// dependency order with the first ID repeated at the end.
+func (g *Graph) Cycles() [][]string {
+	return g.cycles
+}
+
+// Ready reports whether all of the task's local dependencies are met.
+func (g *Graph) Ready(task client.Task) bool {
+	return len(g.WaitingOn(task)) == 0
+}
+
+// WaitingOn lists why a task cannot start yet: unfinished task dependencies
+// by ID, unfinished epic dependencies as "epic <id>", and "cycle" when the
+// task or its epic is part of a dependency cycle.
+func (g *Graph) WaitingOn(task client.Task) []string {
+	var waiting []string
+	if g.inCycle[task.ID] || (task.EpicID != "" && g.inCycle[task.EpicID]) {
+		waiting = append(waiting, "cycle")
+	}
+	for _, dep := range task.DependsOn {
+		if t, ok := g.tasks[dep]; ok && t.Status != g.statuses.Done {
+			waiting = append(waiting, dep)
+		}
+	}
+	if epic, ok := g.epics[task.EpicID]; ok {
+		for _, dep := range epic.DependsOn {
+			if _, known := g.epics[dep]; known && !g.epicDone(dep) {
+				waiting = append(waiting, "epic "+dep)
+			}
+		}
+	}
+	return waiting
+}
+
+// epicDone reports whether an epic is marked done, or has tasks and all of
+// them are done.
+func (g *Graph) epicDone(epicID string) bool {
+	if g.epics[epicID].Status == g.statuses.Done {
+		return true
+	}
+	taskIDs := g.epicTasks[epicID]
+	if len(taskIDs) == 0 {
+		return false
+	}
+	for _, id := range taskIDs {
+		if g.tasks[id].Status != g.statuses.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// findCycles runs a depth-first search over ids and records each back edge
+// as a cycle. Only dependencies for which known returns true are followed.
+func (g *Graph) findCycles(ids []string, deps func(string) []string, known func(string) bool) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(ids))
+	var stack []string
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, dep := range deps(id) {
+			if !known(dep) {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := slices.Index(stack, dep)
+				cycle := append(slices.Clone(stack[start:]), dep)
+				for _, member := range stack[start:] {
+					g.inCycle[member] = true
+				}
+				g.cycles = append(g.cycles, cycle)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+	}
+
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+}
+
+// WriteText prints the graph grouped by epic, one task per line with the
+// dependencies it is still waiting on, followed by any cycles.
+func (g *Graph) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	writeTasks := func(ids []string) {
+		for _, id := range ids {
+			task := g.tasks[id]
+			fmt.Fprintf(&b, "  %s %q (%s)", task.ID, task.Title, task.Status)
+			if len(task.DependsOn) > 0 {
+				fmt.Fprintf(&b, " depends on %s", strings.Join(task.DependsOn, ", "))
+			}
+			if task.Status == g.statuses.Queued {
+				if waiting := g.WaitingOn(task); len(waiting) > 0 {
+					fmt.Fprintf(&b, " · waiting on %s", strings.Join(waiting, ", "))
+				} else {
+					b.WriteString(" · ready")
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	for _, epicID := range g.epicOrder {
+		epic := g.epics[epicID]
+		fmt.Fprintf(&b, "Epic %s %q (%s)", epic.ID, epic.Title, epic.Status)
+		if epic.Auto {
+			b.WriteString(" [auto]")
+		}
+		if len(epic.DependsOn) > 0 {
+			fmt.Fprintf(&b, " depends on %s", strings.Join(epic.DependsOn, ", "))
+		}
+		b.WriteString("\n")
+		writeTasks(g.epicTasks[epicID])
+	}
+
+	var unassigned []string
+	for _, id := range g.taskOrder {
+		if _, ok := g.epics[g.tasks[id].EpicID]; !ok {
+			unassigned = append(unassigned, id)
+		}
+	}
+	if len(unassigned) > 0 {
+		b.WriteString("No epic\n")
+		writeTasks(unassigned)
+	}
+
+	if len(g.cycles) > 0 {
+		b.WriteString("\nCycles:\n")
+		for _, cycle := range g.cycles {
+			fmt.Fprintf(&b, "  %s\n", strings.Join(cycle, " → "))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteDOT prints the graph in Graphviz DOT format. Epics are drawn as
+// clusters and edges point from a dependency to its dependent.
+func (g *Graph) WriteDOT(w io.Writer, name string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+
+	writeTask := func(indent, id string) {
+		task := g.tasks[id]
+		attrs := fmt.Sprintf("label=%s", dotQuote(task.ID+"\n"+task.Title))
+		switch {
+		case task.Status == g.statuses.Done:
+			attrs += ", color=gray"
+		case g.inCycle[id]:
+			attrs += ", color=red"
+		case task.Status == g.statuses.Queued && g.Ready(task):
+			attrs += ", color=green"
+		}
+		fmt.Fprintf(&b, "%s%s [%s];\n", indent, dotQuote(id), attrs)
+	}
+
+	for _, epicID := range g.epicOrder {
+		epic := g.epics[epicID]
+		fmt.Fprintf(&b, "  subgraph %s {\n", dotQuote("cluster_"+epicID))
+		label := epic.Title
+		if epic.Auto {
+			label += " (auto)"
+		}
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(label))
+		fmt.Fprintf(&b, "    %s [label=%s, shape=folder];\n", dotQuote(epicID), dotQuote(epicID))
+		for _, id := range g.epicTasks[epicID] {
+			writeTask("    ", id)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, id := range g.taskOrder {
+		if _, ok := g.epics[g.tasks[id].EpicID]; !ok {
+			writeTask("  ", id)
+		}
+	}
+
+	var edges []string
+	for _, id := range g.epicOrder {
+		for _, dep := range g.epics[id].DependsOn {
+			if _, ok := g.epics[dep]; ok {
+				edges = append(edges, fmt.Sprintf("  %s -> %s [style=dashed];\n", dotQuote(dep), dotQuote(id)))
+			}
+		}
+	}
+	for _, id := range g.taskOrder {
+		for _, dep := range g.tasks[id].DependsOn {
+			if _, ok := g.tasks[dep]; ok {
+				edges = append(edges, fmt.Sprintf("  %s -> %s;\n", dotQuote(dep), dotQuote(id)))
+			}
+		}
+	}
+	sort.Strings(edges)
+	b.WriteString(strings.Join(edges, ""))
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
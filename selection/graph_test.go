@@ -0,0 +1,218 @@
+package selection
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+func TestGraphWaitingOn(t *testing.T) {
+	epics := []client.Epic{
+		{ID: "epic-a", Status: "todo"},
+		{ID: "epic-b", Status: "todo", DependsOn: []string{"epic-a"}},
+		{ID: "epic-c", Status: "todo", DependsOn: []string{"epic-done", "epic-external"}},
+		{ID: "epic-done", Status: "done"},
+	}
+	tasks := []client.Task{
+		{ID: "t1", Status: "done", EpicID: "epic-a"},
+		{ID: "t2", Status: "todo", EpicID: "epic-a", DependsOn: []string{"t1"}},
+		{ID: "t3", Status: "todo", EpicID: "epic-a", DependsOn: []string{"t2", "external"}},
+		{ID: "t4", Status: "todo", EpicID: "epic-b"},
+		{ID: "t5", Status: "todo", EpicID: "epic-c"},
+	}
+	g := NewGraph(tasks, epics)
+
+	tests := []struct {
+		taskID   string
+		expected []string
+	}{
+		{"t2", nil},
+		{"t3", []string{"t2"}},
+		{"t4", []string{"epic epic-a"}},
+		{"t5", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.taskID, func(t *testing.T) {
+			got := g.WaitingOn(g.tasks[tt.taskID])
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			if g.Ready(g.tasks[tt.taskID]) != (len(tt.expected) == 0) {
+				t.Errorf("Ready disagrees with WaitingOn %v", got)
+			}
+		})
+	}
+}
+
+func TestGraphEpicDoneWhenAllTasksDone(t *testing.T) {
+	epics := []client.Epic{
+		{ID: "epic-a", Status: "todo"},
+		{ID: "epic-b", Status: "todo", DependsOn: []string{"epic-a"}},
+	}
+	tasks := []client.Task{
+		{ID: "t1", Status: "done", EpicID: "epic-a"},
+		{ID: "t2", Status: "todo", EpicID: "epic-b"},
+	}
+	g := NewGraph(tasks, epics)
+	if !g.Ready(g.tasks["t2"]) {
+		t.Errorf("expected t2 ready, waiting on %v", g.WaitingOn(g.tasks["t2"]))
+	}
+}
+
+func TestGraphCustomStatuses(t *testing.T) {
+	epics := []client.Epic{
+		{ID: "epic-a", Status: "backlog"},
+		{ID: "epic-b", Status: "backlog", DependsOn: []string{"epic-a"}},
+	}
+	tasks := []client.Task{
+		{ID: "t1", Status: "shipped", EpicID: "epic-a"},
+		{ID: "t2", Status: "backlog", EpicID: "epic-a", DependsOn: []string{"t1"}},
+		{ID: "t3", Status: "backlog", EpicID: "epic-b"},
+	}
+	g := NewGraph(tasks, epics)
+	if g.Ready(g.tasks["t2"]) {
+		t.Error("expected t2 waiting while shipped is not the done status")
+	}
+
+	g.SetStatuses(Statuses{Queued: "backlog", Done: "shipped"})
+	if !g.Ready(g.tasks["t2"]) {
+		t.Errorf("expected t2 ready, waiting on %v", g.WaitingOn(g.tasks["t2"]))
+	}
+	if got := g.WaitingOn(g.tasks["t3"]); !slices.Equal(got, []string{"epic epic-a"}) {
+		t.Errorf("expected t3 waiting on epic-a, got %v", got)
+	}
+}
+
+func TestGraphCycles(t *testing.T) {
+	epics := []client.Epic{
+		{ID: "epic-x", DependsOn: []string{"epic-y"}},
+		{ID: "epic-y", DependsOn: []string{"epic-x"}},
+		{ID: "epic-z"},
+	}
+	tasks := []client.Task{
+		{ID: "t1", Status: "todo", EpicID: "epic-z", DependsOn: []string{"t2"}},
+		{ID: "t2", Status: "todo", EpicID: "epic-z", DependsOn: []string{"t3"}},
+		{ID: "t3", Status: "todo", EpicID: "epic-z", DependsOn: []string{"t1"}},
+		{ID: "t4", Status: "todo", EpicID: "epic-z"},
+		{ID: "t5", Status: "todo", EpicID: "epic-x"},
+	}
+	g := NewGraph(tasks, epics)
+
+	cycles := g.Cycles()
+	if len(cycles) != 2 {
+		t.Fatalf("expected 2 cycles, got %v", cycles)
+	}
+	if got := strings.Join(cycles[0], " "); got != "t1 t2 t3 t1" {
+		t.Errorf("unexpected task cycle %q", got)
+	}
+	if got := strings.Join(cycles[1], " "); got != "epic-x epic-y epic-x" {
+		t.Errorf("unexpected epic cycle %q", got)
+	}
+
+	if g.Ready(g.tasks["t3"]) {
+		t.Error("expected task in a cycle not to be ready")
+	}
+	if !slices.Contains(g.WaitingOn(g.tasks["t5"]), "cycle") {
+		t.Error("expected task in a cyclic epic to report the cycle")
+	}
+	if !g.Ready(g.tasks["t4"]) {
+		t.Error("expected task outside cycles to be ready")
+	}
+}
+
+func TestGraphWriteText(t *testing.T) {
+	g := NewGraph(
+		[]client.Task{
+			{ID: "t1", Title: "First", Status: "todo", EpicID: "epic-a"},
+			{ID: "t2", Title: "Second", Status: "todo", EpicID: "epic-a", DependsOn: []string{"t1"}},
+			{ID: "t3", Title: "Loose", Status: "done"},
+		},
+		[]client.Epic{{ID: "epic-a", Title: "Alpha", Status: "todo", Auto: true}},
+	)
+
+	var b strings.Builder
+	if err := g.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected := `Epic epic-a "Alpha" (todo) [auto]
+  t1 "First" (todo) · ready
+  t2 "Second" (todo) depends on t1 · waiting on t1
+No epic
+  t3 "Loose" (done)
+`
+	if b.String() != expected {
+		t.Errorf("unexpected text output:\n%s", b.String())
+	}
+}
+
+func TestGraphWriteDOT(t *testing.T) {
+	g := NewGraph(
+		[]client.Task{
+			{ID: "t1", Title: `Say "hi"`, Status: "todo", EpicID: "epic-a"},
+			{ID: "t2", Title: "Second", Status: "todo", EpicID: "epic-b", DependsOn: []string{"t1"}},
+		},
+		[]client.Epic{
+			{ID: "epic-a", Title: "Alpha"},
+			{ID: "epic-b", Title: "Beta", DependsOn: []string{"epic-a"}},
+		},
+	)
+
+	var b strings.Builder
+	if err := g.WriteDOT(&b, "proj-1"); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`digraph "proj-1" {`,
+		`subgraph "cluster_epic-a" {`,
+		`"t1" [label="t1\nSay \"hi\"", color=green];`,
+		`"t1" -> "t2";`,
+		`"epic-a" -> "epic-b" [style=dashed];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected DOT output to contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSelectorSkipsUnmetDependencies(t *testing.T) {
+	// Flux reports nothing blocked, but t-new depends on an unfinished task
+	// and epic-late depends on an unfinished epic.
+	m := newMockServer()
+	m.projects = []client.Project{{ID: "proj-1"}}
+	m.epics["proj-1"] = []client.Epic{
+		{ID: "epic-early", ProjectID: "proj-1", Status: "todo", Auto: true},
+		{ID: "epic-late", ProjectID: "proj-1", Status: "todo", Auto: true, DependsOn: []string{"epic-early"}},
+	}
+	m.tasks["proj-1"] = []client.Task{
+		{ID: "t-old", Status: "todo", ProjectID: "proj-1", EpicID: "epic-early"},
+		{ID: "t-new", Status: "todo", ProjectID: "proj-1", EpicID: "epic-early", DependsOn: []string{"t-old"}},
+		{ID: "t-z", Status: "todo", ProjectID: "proj-1", EpicID: "epic-late"},
+	}
+	server, c := setupTest(m)
+	defer server.Close()
+
+	for _, selector := range []*Selector{
+		NewSelector(c, "proj-1", "", ""),
+		NewSelector(c, "", "", ""),
+		NewWatchSelector(c, Watch{Projects: []string{"proj-1"}, Epics: []string{"epic-late"}}),
+	} {
+		task, err := selector.SelectTaskExcluding(nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", selector.Watch(), err)
+		}
+		if task.ID != "t-old" {
+			t.Errorf("%s: expected t-old, got %s", selector.Watch(), task.ID)
+		}
+		if _, err := selector.SelectTaskExcluding(map[string]bool{"t-old": true}); !errors.Is(err, ErrNoTaskAvailable) {
+			t.Errorf("%s: expected no task once t-old is excluded, got %v", selector.Watch(), err)
+		}
+	}
+
+	if _, err := NewSelector(c, "", "epic-late", "").SelectTask(); !errors.Is(err, ErrNoTaskAvailable) {
+		t.Errorf("expected epic with unfinished dependency to yield nothing, got %v", err)
+	}
+}
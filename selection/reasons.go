@@ -0,0 +1,164 @@
+package selection
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// ReasonCode identifies why a task is not eligible for selection.
+type ReasonCode string
+
+const (
+	// ReasonNotWatched means the task is outside the watched projects,
+	// epics and tasks.
+	ReasonNotWatched ReasonCode = "not-watched"
+
+	// ReasonNoEpic means the task has no epic, or its epic was not found.
+	ReasonNoEpic ReasonCode = "no-epic"
+
+	// ReasonEpicNotAuto means the task's epic has auto=false.
+	ReasonEpicNotAuto ReasonCode = "epic-not-auto"
+
+	// ReasonNotTodo means the task is not in the queued status ("todo" by
+	// default).
+	ReasonNotTodo ReasonCode = "not-todo"
+
+	// ReasonBlocked means Flux reports the task as blocked.
+	ReasonBlocked ReasonCode = "blocked"
+
+	// ReasonWaiting means a task or epic the task depends on is not done,
+	// or the task is part of a dependency cycle.
+	ReasonWaiting ReasonCode = "waiting"
+
+	// ReasonFiltered means the task does not match the label, assignee or
+	// text filters.
+	ReasonFiltered ReasonCode = "filtered"
+
+	// ReasonExcluded means the task is already queued or running.
+	ReasonExcluded ReasonCode = "excluded"
+)
+
+// Reason is a single rejection reason with a human-readable detail.
+type Reason struct {
+	Code   ReasonCode
+	Detail string
+}
+
+func (r Reason) String() string {
+	return r.Detail
+}
+
+// Evaluation is the selector's verdict on a single task.
+type Evaluation struct {
+	Task client.Task
+	// ProjectID is the project the task was listed under
+	ProjectID string
+	// Reasons lists every reason the task is not eligible, in the order
+	// the selector checks them. Empty when the task is eligible.
+	Reasons []Reason
+}
+
+// Eligible reports whether the task can be selected.
+func (e Evaluation) Eligible() bool {
+	return len(e.Reasons) == 0
+}
+
+// Has reports whether the evaluation includes a reason with code.
+func (e Evaluation) Has(code ReasonCode) bool {
+	return slices.ContainsFunc(e.Reasons, func(r Reason) bool { return r.Code == code })
+}
+
+// Evaluate loads the board the selector draws from and returns a verdict
+// for every task on it, eligible or not. Tasks in excluded are rejected
+// with ReasonExcluded.
+func (s *Selector) Evaluate(excluded map[string]bool) ([]Evaluation, error) {
+	b, err := s.loadBoard()
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(b, excluded), nil
+}
+
+// Explain returns the selector's verdict on a single task, looking it up
+// across every project so tasks outside the watch can be explained too.
+func (s *Selector) Explain(taskID string, excluded map[string]bool) (Evaluation, error) {
+	b, err := s.loadAllProjects()
+	if err != nil {
+		return Evaluation{}, err
+	}
+	for _, task := range b.tasks {
+		if task.ID == taskID {
+			return Evaluation{
+				Task:      task,
+				ProjectID: b.projectOf[task.ID],
+				Reasons:   s.rejections(task, b, excluded),
+			}, nil
+		}
+	}
+	return Evaluation{}, fmt.Errorf("task %s not found", taskID)
+}
+
+func (s *Selector) evaluate(b *board, excluded map[string]bool) []Evaluation {
+	evals := make([]Evaluation, 0, len(b.tasks))
+	for _, task := range b.tasks {
+		evals = append(evals, Evaluation{
+			Task:      task,
+			ProjectID: b.projectOf[task.ID],
+			Reasons:   s.rejections(task, b, excluded),
+		})
+	}
+	return evals
+}
+
+// rejections lists why task cannot be selected from b.
+func (s *Selector) rejections(task client.Task, b *board, excluded map[string]bool) []Reason {
+	var reasons []Reason
+	add := func(code ReasonCode, format string, args ...any) {
+		reasons = append(reasons, Reason{Code: code, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if !s.watch.covers(task, b.projectOf[task.ID]) {
+		add(ReasonNotWatched, "outside the watched targets (%s)", s.watch)
+	}
+
+	// A lone watched task is run whatever its state
+	if s.watch.size() == 1 && len(s.watch.Tasks) == 1 && s.watch.Tasks[0] == task.ID {
+		if excluded[task.ID] {
+			add(ReasonExcluded, "already queued or running")
+		}
+		return reasons
+	}
+
+	// Explicitly watched tasks qualify regardless of their epic
+	if !slices.Contains(s.watch.Tasks, task.ID) {
+		epic, ok := b.epics[task.EpicID]
+		switch {
+		case task.EpicID == "":
+			add(ReasonNoEpic, "task has no epic")
+		case !ok:
+			add(ReasonNoEpic, "epic %s not found", task.EpicID)
+		case !epic.Auto:
+			add(ReasonEpicNotAuto, "epic %s has auto=false", task.EpicID)
+		}
+	}
+
+	if queued := s.statuses.Queued; task.Status != queued {
+		add(ReasonNotTodo, "status is %s, not %s", task.Status, queued)
+	}
+	if task.Blocked {
+		add(ReasonBlocked, "blocked in Flux")
+	}
+	if waiting := b.graph.WaitingOn(task); len(waiting) > 0 {
+		add(ReasonWaiting, "waiting on %s", strings.Join(waiting, ", "))
+	}
+	for _, mismatch := range s.filter.Mismatches(task) {
+		add(ReasonFiltered, "%s", mismatch)
+	}
+	if excluded[task.ID] {
+		add(ReasonExcluded, "already queued or running")
+	}
+	return reasons
+}
@@ -0,0 +1,105 @@
+package selection
+
+import (
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+func TestEvaluateReasons(t *testing.T) {
+	m := newMockServer()
+	m.projects = []client.Project{{ID: "proj-1"}, {ID: "proj-2"}}
+	m.epics["proj-1"] = []client.Epic{
+		{ID: "epic-auto", ProjectID: "proj-1", Auto: true},
+		{ID: "epic-manual", ProjectID: "proj-1"},
+	}
+	m.epics["proj-2"] = []client.Epic{
+		{ID: "epic-other", ProjectID: "proj-2", Auto: true},
+		{ID: "epic-held", ProjectID: "proj-2"},
+	}
+	m.tasks["proj-1"] = []client.Task{
+		{ID: "ok", Status: "todo", EpicID: "epic-auto"},
+		{ID: "manual", Status: "todo", EpicID: "epic-manual"},
+		{ID: "orphan", Status: "todo"},
+		{ID: "gone", Status: "todo", EpicID: "epic-deleted"},
+		{ID: "doing", Status: "in_progress", EpicID: "epic-auto"},
+		{ID: "blocked", Status: "todo", EpicID: "epic-auto", Blocked: true},
+		{ID: "waits", Status: "todo", EpicID: "epic-auto", DependsOn: []string{"doing"}},
+		{ID: "wip", Status: "todo", EpicID: "epic-auto", Labels: []string{"wip"}},
+		{ID: "queued", Status: "todo", EpicID: "epic-auto"},
+	}
+	// proj-2 is only loaded for the explicit task
+	m.tasks["proj-2"] = []client.Task{
+		{ID: "explicit", Status: "todo", ProjectID: "proj-2", EpicID: "epic-held"},
+		{ID: "elsewhere", Status: "todo", ProjectID: "proj-2", EpicID: "epic-other"},
+	}
+	server, c := setupTest(m)
+	defer server.Close()
+
+	selector := NewWatchSelector(c, Watch{Projects: []string{"proj-1"}, Tasks: []string{"explicit"}})
+	f, err := NewFilter(nil, []string{"wip"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector.SetFilter(f)
+
+	evals, err := selector.Evaluate(map[string]bool{"queued": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]ReasonCode{
+		"ok":        nil,
+		"manual":    {ReasonEpicNotAuto},
+		"orphan":    {ReasonNoEpic},
+		"gone":      {ReasonNoEpic},
+		"doing":     {ReasonNotTodo},
+		"blocked":   {ReasonBlocked},
+		"waits":     {ReasonWaiting},
+		"wip":       {ReasonFiltered},
+		"queued":    {ReasonExcluded},
+		"explicit":  nil,
+		"elsewhere": {ReasonNotWatched},
+	}
+	if len(evals) != len(expected) {
+		t.Fatalf("expected %d evaluations, got %d", len(expected), len(evals))
+	}
+	for _, eval := range evals {
+		want := expected[eval.Task.ID]
+		if len(eval.Reasons) != len(want) {
+			t.Errorf("%s: expected %v, got %v", eval.Task.ID, want, eval.Reasons)
+			continue
+		}
+		for i, code := range want {
+			if eval.Reasons[i].Code != code {
+				t.Errorf("%s: expected %v, got %v", eval.Task.ID, want, eval.Reasons)
+			}
+		}
+		if eval.Eligible() != (len(want) == 0) {
+			t.Errorf("%s: Eligible() = %v", eval.Task.ID, eval.Eligible())
+		}
+	}
+}
+
+func TestFilterMismatches(t *testing.T) {
+	f, err := NewFilter([]string{"frontend"}, []string{"wip"}, []string{"alice"}, []string{"^UI:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := client.Task{Title: "API: thing", Labels: []string{"wip"}}
+	got := f.Mismatches(task)
+	expected := []string{
+		"missing label frontend",
+		"has excluded label wip",
+		"assigned to nobody, not alice",
+		"title and notes do not match /^UI:/",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], got[i])
+		}
+	}
+}
@@ -4,7 +4,10 @@ package selection
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"sort"
+	"sync"
 
 	"github.com/stephenmfriend/momentum/client"
 )
@@ -13,257 +16,407 @@ import (
 var ErrNoTaskAvailable = errors.New("no task available matching the selection criteria")
 
 // Selector handles task selection logic for headless mode.
-// It supports filtering by project, epic, or specific task ID.
+// It supports filtering by projects, epics, or specific task IDs.
 type Selector struct {
-	client    *client.Client
-	projectID string
-	epicID    string
-	taskID    string
+	client   *client.Client
+	watch    Watch
+	strategy Strategy
+	fairness *fairScheduler
+	filter   Filter
+	statuses Statuses
+
+	// homes caches the project of each watched epic and task
+	homesMu sync.Mutex
+	homes   map[string]string
 }
 
 // NewSelector creates a new Selector with the given filters.
 // All filter parameters are optional - pass empty strings if not needed.
+// When several are given, taskID takes precedence over epicID, and epicID
+// over projectID. Use NewWatchSelector to combine them instead.
 func NewSelector(c *client.Client, projectID, epicID, taskID string) *Selector {
+	var w Watch
+	switch {
+	case taskID != "":
+		w.Tasks = []string{taskID}
+	case epicID != "":
+		w.Epics = []string{epicID}
+	case projectID != "":
+		w.Projects = []string{projectID}
+	}
+	return NewWatchSelector(c, w)
+}
+
+// NewWatchSelector creates a Selector that merges the candidates of every
+// project, epic and task in w.
+func NewWatchSelector(c *client.Client, w Watch) *Selector {
 	return &Selector{
-		client:    c,
-		projectID: projectID,
-		epicID:    epicID,
-		taskID:    taskID,
+		client:   c,
+		watch:    w,
+		strategy: DefaultStrategy,
+		fairness: newFairScheduler(),
+		statuses: Statuses{}.withDefaults(),
+		homes:    make(map[string]string),
+	}
+}
+
+// Watch returns the projects, epics and tasks the selector draws from.
+func (s *Selector) Watch() Watch {
+	return s.watch
+}
+
+// SetStrategy configures how qualifying tasks are ordered.
+// A nil strategy restores DefaultStrategy.
+func (s *Selector) SetStrategy(strategy Strategy) {
+	if strategy == nil {
+		strategy = DefaultStrategy
 	}
+	s.strategy = strategy
+}
+
+// Strategy returns the configured ordering strategy.
+func (s *Selector) Strategy() Strategy {
+	return s.strategy
+}
+
+// SetFilter restricts selection to tasks matching f. It does not apply when
+// the selector watches a single task ID.
+func (s *Selector) SetFilter(f Filter) {
+	s.filter = f
+}
+
+// SetStatuses configures which statuses count as queued and done, for
+// boards with their own columns.
+func (s *Selector) SetStatuses(statuses Statuses) {
+	s.statuses = statuses.withDefaults()
+}
+
+// Statuses returns the statuses that count as queued and done.
+func (s *Selector) Statuses() Statuses {
+	return s.statuses
+}
+
+// SetFairness configures how tasks from different projects share agents.
+// Weights map project IDs to their share under FairnessWeighted.
+func (s *Selector) SetFairness(mode Fairness, weights map[string]int) {
+	s.fairness.configure(mode, weights)
+}
+
+// RecordPick tells the selector that a selected task was handed to an
+// agent, so fairness can account for it. Selections that are only used to
+// check availability should not be recorded.
+func (s *Selector) RecordPick(task *client.Task) {
+	if task == nil {
+		return
+	}
+	s.fairness.record(task.ProjectID)
 }
 
 // SelectTask selects a task based on the configured filters.
 // The selection logic follows this priority:
-//  1. If taskID is provided, fetch that specific task
-//  2. If epicID is provided, get the first unblocked todo task from that epic (if epic has auto=true)
-//  3. If projectID is provided, get the first unblocked todo task from that project (only from auto epics)
-//  4. If nothing is provided, get the newest unblocked todo task across ALL projects (only from auto epics)
+//  1. If a single task is watched, fetch that specific task
+//  2. Otherwise, load the watched projects (or every project when the watch
+//     is empty, or epics and tasks are watched) and pick among the tasks
+//     that Evaluate finds eligible
 //
 // Only tasks meeting ALL of these criteria are considered:
-//   - Task belongs to an epic with auto=true
-//   - Task has status "todo"
-//   - Task is unblocked (blocked=false)
+//   - Task is watched: in a watched project or epic, or named explicitly
+//   - Task belongs to an epic with auto=true, unless named explicitly
+//   - Task is in the queued status ("todo" by default)
+//   - Task is unblocked (blocked=false) and its local dependencies are done
+//   - Task matches the selector's filter
 //
-// Within the qualifying tasks, newer tasks (by ID) come first.
+// Within the qualifying tasks, the configured Strategy decides the order
+// (newer tasks first by default).
 func (s *Selector) SelectTask() (*client.Task, error) {
 	return s.SelectTaskExcluding(nil)
 }
 
 // SelectTaskExcluding selects a task while skipping any task IDs in excluded.
 func (s *Selector) SelectTaskExcluding(excluded map[string]bool) (*client.Task, error) {
-	// Case 1: Specific task ID provided
-	if s.taskID != "" {
-		return s.fetchSpecificTask(excluded)
+	// A lone task is fetched whatever its status
+	if s.watch.size() == 1 && len(s.watch.Tasks) == 1 {
+		return s.fetchSpecificTask(s.watch.Tasks[0], excluded)
 	}
 
-	// Case 2: Epic ID provided - get tasks from that epic's project filtered by epic
-	if s.epicID != "" {
-		return s.selectFromEpic(excluded)
+	b, err := s.loadBoard()
+	if err != nil {
+		return nil, err
 	}
 
-	// Case 3: Project ID provided - get tasks from that project
-	if s.projectID != "" {
-		return s.selectFromProject(s.projectID, excluded)
+	var eligible []client.Task
+	for _, eval := range s.evaluate(b, excluded) {
+		if eval.Eligible() {
+			eligible = append(eligible, eval.Task)
+		}
 	}
 
-	// Case 4: No filters - search across all projects
-	return s.selectFromAllProjects(excluded)
-}
-
-// fetchSpecificTask fetches a task by its ID.
-// Since the client doesn't have a GetTask method, we need to find it
-// by listing tasks from all projects.
-func (s *Selector) fetchSpecificTask(excluded map[string]bool) (*client.Task, error) {
-	if excluded != nil && excluded[s.taskID] {
-		return nil, fmt.Errorf("task %s excluded: %w", s.taskID, ErrNoTaskAvailable)
+	strategy := s.strategy
+	if gs, ok := strategy.(GraphStrategy); ok {
+		strategy = gs.WithGraph(b.graph)
 	}
 
-	// We need to find the task across all projects since we don't know which project it belongs to
-	projects, err := s.client.ListProjects()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list projects: %w", err)
-	}
-
-	for _, project := range projects {
-		tasks, err := s.client.ListTasks(project.ID, client.TaskFilters{})
-		if err != nil {
-			// Log but continue searching other projects
-			continue
-		}
+	// Filter and sort tasks
+	candidates := filterAndSortTasks(eligible, excluded, s.statuses.Queued, strategy)
 
-		for i := range tasks {
-			if tasks[i].ID == s.taskID {
-				return &tasks[i], nil
-			}
-		}
+	if len(candidates) == 0 {
+		return nil, ErrNoTaskAvailable
 	}
 
-	return nil, fmt.Errorf("task %s not found: %w", s.taskID, ErrNoTaskAvailable)
+	return &candidates[s.fairness.pick(candidates)], nil
 }
 
-// selectFromEpic selects the best task from the specified epic.
-func (s *Selector) selectFromEpic(excluded map[string]bool) (*client.Task, error) {
-	// First, we need to find which project this epic belongs to
-	projects, err := s.client.ListProjects()
+// Queue returns every task the selector would hand out, in the order it
+// would hand them out if each were picked and run, along with its verdict
+// on every task on the board. It loads the board once and simulates
+// fairness without recording any picks, so it suits previews.
+func (s *Selector) Queue() ([]client.Task, []Evaluation, error) {
+	b, err := s.loadBoard()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list projects: %w", err)
+		return nil, nil, err
 	}
+	evals := s.evaluate(b, nil)
 
-	// Find the project containing the epic and check if it's auto-enabled
-	var targetProjectID string
-	var epicIsAuto bool
-	for _, project := range projects {
-		epics, err := s.client.ListEpics(project.ID)
-		if err != nil {
-			continue
-		}
-
-		for _, epic := range epics {
-			if epic.ID == s.epicID {
-				targetProjectID = project.ID
-				epicIsAuto = epic.Auto
-				break
-			}
+	var candidates []client.Task
+	for _, eval := range evals {
+		if eval.Eligible() {
+			candidates = append(candidates, eval.Task)
 		}
-		if targetProjectID != "" {
-			break
+	}
+	// A lone task is queued whatever its status
+	if !(s.watch.size() == 1 && len(s.watch.Tasks) == 1) {
+		strategy := s.strategy
+		if gs, ok := strategy.(GraphStrategy); ok {
+			strategy = gs.WithGraph(b.graph)
 		}
+		candidates = filterAndSortTasks(candidates, nil, s.statuses.Queued, strategy)
 	}
 
-	if targetProjectID == "" {
-		return nil, fmt.Errorf("epic %s not found: %w", s.epicID, ErrNoTaskAvailable)
+	fairness := s.fairness.clone()
+	queue := make([]client.Task, 0, len(candidates))
+	for len(candidates) > 0 {
+		i := fairness.pick(candidates)
+		fairness.record(candidates[i].ProjectID)
+		queue = append(queue, candidates[i])
+		candidates = slices.Delete(candidates, i, i+1)
 	}
+	return queue, evals, nil
+}
 
-	// Only process epics with auto=true
-	if !epicIsAuto {
-		return nil, fmt.Errorf("epic %s has auto=false: %w", s.epicID, ErrNoTaskAvailable)
+// fetchSpecificTask fetches a task by its ID.
+func (s *Selector) fetchSpecificTask(taskID string, excluded map[string]bool) (*client.Task, error) {
+	if excluded != nil && excluded[taskID] {
+		return nil, fmt.Errorf("task %s excluded: %w", taskID, ErrNoTaskAvailable)
 	}
 
-	// Get tasks filtered by epic
-	filters := client.TaskFilters{
-		EpicID: client.StringPtr(s.epicID),
+	task, err := s.client.GetTask(taskID)
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("task %s not found: %w", taskID, ErrNoTaskAvailable)
 	}
-	tasks, err := s.client.ListTasks(targetProjectID, filters)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list tasks for epic %s: %w", s.epicID, err)
+		return nil, err
 	}
+	return task, nil
+}
 
-	// Build auto epic IDs map (just this epic since we already verified it's auto)
-	autoEpicIDs := map[string]bool{s.epicID: true}
-
-	return s.selectBestTask(tasks, autoEpicIDs, excluded)
+// board is a snapshot of the projects a selection looks at.
+type board struct {
+	tasks []client.Task
+	epics map[string]client.Epic
+	// projectOf maps task IDs to the project they were listed under
+	projectOf map[string]string
+	graph     *Graph
 }
 
-// selectFromProject selects the best task from the specified project.
-func (s *Selector) selectFromProject(projectID string, excluded map[string]bool) (*client.Task, error) {
-	tasks, err := s.client.ListTasks(projectID, client.TaskFilters{})
+// loadBoard lists the tasks and epics of every project the watch can draw
+// from: the watched projects and those holding the watched epics and tasks.
+// When a task there depends on a task or epic in another project, the whole
+// board is loaded so the dependency can be checked. Projects that fail to
+// load are skipped, unless a single project is watched.
+func (s *Selector) loadBoard() (*board, error) {
+	if s.watch.IsEmpty() {
+		return s.loadAllProjects()
+	}
+	if len(s.watch.Epics) == 0 && len(s.watch.Tasks) == 0 {
+		strict := len(s.watch.Projects) == 1
+		return s.loadProjects(s.watch.Projects, strict)
+	}
+	projectIDs, err := s.watchedProjects()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list tasks for project %s: %w", projectID, err)
+		return nil, err
 	}
-
-	// Get auto epic IDs for this project
-	autoEpicIDs, err := s.getAutoEpicIDs(projectID)
+	b, err := s.loadProjects(projectIDs, false)
 	if err != nil {
 		return nil, err
 	}
-
-	return s.selectBestTask(tasks, autoEpicIDs, excluded)
+	if s.dependsElsewhere(b) {
+		return s.loadAllProjects()
+	}
+	return b, nil
 }
 
-// selectFromAllProjects selects the best task across all projects.
-func (s *Selector) selectFromAllProjects(excluded map[string]bool) (*client.Task, error) {
-	projects, err := s.client.ListProjects()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list projects: %w", err)
+// watchedProjects returns the watched projects followed by those holding
+// the watched epics and tasks. Epics and tasks that cannot be found are
+// left out.
+func (s *Selector) watchedProjects() ([]string, error) {
+	s.homesMu.Lock()
+	defer s.homesMu.Unlock()
+
+	projectIDs := slices.Clone(s.watch.Projects)
+	add := func(projectID string) {
+		if projectID != "" && !slices.Contains(projectIDs, projectID) {
+			projectIDs = append(projectIDs, projectID)
+		}
 	}
 
-	if len(projects) == 0 {
-		return nil, fmt.Errorf("no projects found: %w", ErrNoTaskAvailable)
+	for _, taskID := range s.watch.Tasks {
+		if _, ok := s.homes[taskID]; !ok {
+			task, err := s.client.GetTask(taskID)
+			var apiErr *client.APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			s.homes[taskID] = task.ProjectID
+		}
+		add(s.homes[taskID])
 	}
 
-	var allTasks []client.Task
-	allAutoEpicIDs := make(map[string]bool)
-
-	for _, project := range projects {
-		tasks, err := s.client.ListTasks(project.ID, client.TaskFilters{})
+	// Flux has no epic lookup, so epics are found by listing each
+	// project's epics until they all turn up
+	var missing []string
+	for _, epicID := range s.watch.Epics {
+		if _, ok := s.homes[epicID]; !ok {
+			missing = append(missing, epicID)
+		}
+	}
+	if len(missing) > 0 {
+		projects, err := s.client.ListProjects()
 		if err != nil {
-			// Log but continue with other projects
-			continue
+			return nil, fmt.Errorf("failed to list projects: %w", err)
 		}
-		allTasks = append(allTasks, tasks...)
+		for _, project := range projects {
+			if len(missing) == 0 {
+				break
+			}
+			epics, err := s.client.ListEpics(project.ID)
+			if err != nil {
+				continue
+			}
+			for _, epic := range epics {
+				if slices.Contains(missing, epic.ID) {
+					s.homes[epic.ID] = project.ID
+					missing = slices.DeleteFunc(missing, func(id string) bool { return id == epic.ID })
+				}
+			}
+		}
+	}
+	for _, epicID := range s.watch.Epics {
+		add(s.homes[epicID])
+	}
+	return projectIDs, nil
+}
 
-		// Get auto epic IDs for this project
-		autoEpicIDs, err := s.getAutoEpicIDs(project.ID)
-		if err != nil {
+// dependsElsewhere reports whether a watched task on b depends on a task,
+// or sits in an epic that depends on an epic, that b does not hold.
+func (s *Selector) dependsElsewhere(b *board) bool {
+	for _, task := range b.tasks {
+		if !s.watch.covers(task, b.projectOf[task.ID]) {
 			continue
 		}
-		for epicID := range autoEpicIDs {
-			allAutoEpicIDs[epicID] = true
+		for _, dep := range task.DependsOn {
+			if _, ok := b.projectOf[dep]; !ok {
+				return true
+			}
+		}
+		for _, dep := range b.epics[task.EpicID].DependsOn {
+			if _, ok := b.epics[dep]; !ok {
+				return true
+			}
 		}
 	}
-
-	return s.selectBestTask(allTasks, allAutoEpicIDs, excluded)
+	return false
 }
 
-// getAutoEpicIDs returns a map of epic IDs that have auto=true for the given project.
-func (s *Selector) getAutoEpicIDs(projectID string) (map[string]bool, error) {
-	epics, err := s.client.ListEpics(projectID)
+func (s *Selector) loadAllProjects() (*board, error) {
+	projects, err := s.client.ListProjects()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list epics for project %s: %w", projectID, err)
+		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
-
-	autoEpicIDs := make(map[string]bool)
-	for _, epic := range epics {
-		if epic.Auto {
-			autoEpicIDs[epic.ID] = true
-		}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no projects found: %w", ErrNoTaskAvailable)
+	}
+	projectIDs := make([]string, 0, len(projects))
+	for _, project := range projects {
+		projectIDs = append(projectIDs, project.ID)
 	}
-	return autoEpicIDs, nil
+	return s.loadProjects(projectIDs, false)
 }
 
-// selectBestTask selects the best task from a list.
-// Only tasks belonging to auto-enabled epics with status "todo" and unblocked are considered.
-// Tasks are sorted by ID descending (newer first).
-func (s *Selector) selectBestTask(tasks []client.Task, autoEpicIDs map[string]bool, excluded map[string]bool) (*client.Task, error) {
-	if len(tasks) == 0 {
-		return nil, ErrNoTaskAvailable
+// loadProjects builds a board from the given projects. When strict is set,
+// the first project that fails to load fails the whole board.
+func (s *Selector) loadProjects(projectIDs []string, strict bool) (*board, error) {
+	b := &board{
+		epics:     make(map[string]client.Epic),
+		projectOf: make(map[string]string),
 	}
-
-	// Filter to only tasks belonging to auto-enabled epics
-	var autoTasks []client.Task
-	for _, task := range tasks {
-		if task.EpicID != "" && autoEpicIDs[task.EpicID] {
-			autoTasks = append(autoTasks, task)
+	var allEpics []client.Epic
+	for _, projectID := range projectIDs {
+		tasks, err := s.client.ListTasks(projectID, client.TaskFilters{})
+		if err != nil {
+			if strict {
+				return nil, fmt.Errorf("failed to list tasks for project %s: %w", projectID, err)
+			}
+			continue
+		}
+		epics, err := s.listEpics(projectID)
+		if err != nil {
+			if strict {
+				return nil, err
+			}
+			continue
+		}
+		for _, task := range tasks {
+			b.projectOf[task.ID] = projectID
 		}
+		for _, epic := range epics {
+			b.epics[epic.ID] = epic
+		}
+		b.tasks = append(b.tasks, tasks...)
+		allEpics = append(allEpics, epics...)
 	}
+	b.graph = NewGraph(b.tasks, allEpics)
+	b.graph.SetStatuses(s.statuses)
+	return b, nil
+}
 
-	// Filter and sort tasks
-	candidates := filterAndSortTasks(autoTasks, excluded)
-
-	if len(candidates) == 0 {
-		return nil, ErrNoTaskAvailable
+func (s *Selector) listEpics(projectID string) ([]client.Epic, error) {
+	epics, err := s.client.ListEpics(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list epics for project %s: %w", projectID, err)
 	}
-
-	return &candidates[0], nil
+	return epics, nil
 }
 
-// filterAndSortTasks filters tasks to only include unblocked tasks with status "todo",
-// sorted by ID descending (newer first).
-func filterAndSortTasks(tasks []client.Task, excluded map[string]bool) []client.Task {
+// filterAndSortTasks filters tasks to only include unblocked tasks in the
+// queued status, ordered by strategy.
+func filterAndSortTasks(tasks []client.Task, excluded map[string]bool, queued string, strategy Strategy) []client.Task {
 	var unblockedTodos []client.Task
 
 	for _, task := range tasks {
 		if excluded != nil && excluded[task.ID] {
 			continue
 		}
-		if !task.Blocked && task.Status == "todo" {
+		if !task.Blocked && task.Status == queued {
 			unblockedTodos = append(unblockedTodos, task)
 		}
 	}
 
-	// Sort by ID descending (newer first)
-	sort.Slice(unblockedTodos, func(i, j int) bool {
-		return unblockedTodos[i].ID > unblockedTodos[j].ID
+	sort.SliceStable(unblockedTodos, func(i, j int) bool {
+		return strategy.Less(unblockedTodos[i], unblockedTodos[j])
 	})
 
 	return unblockedTodos
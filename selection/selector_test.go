@@ -15,6 +15,7 @@ type mockServer struct {
 	projects []client.Project
 	epics    map[string][]client.Epic // projectID -> epics
 	tasks    map[string][]client.Task // projectID -> tasks
+	requests []string                 // paths requested, in order
 }
 
 func newMockServer() *mockServer {
@@ -31,11 +32,24 @@ func (m *mockServer) handler() http.Handler {
 
 		// Parse the path
 		path := r.URL.Path
+		m.requests = append(m.requests, path)
 
 		switch {
 		case path == "/api/projects" && r.Method == http.MethodGet:
 			json.NewEncoder(w).Encode(m.projects)
 
+		case hasPrefix(path, "/api/tasks/") && r.Method == http.MethodGet:
+			taskID := path[len("/api/tasks/"):]
+			for _, tasks := range m.tasks {
+				for _, task := range tasks {
+					if task.ID == taskID {
+						json.NewEncoder(w).Encode(task)
+						return
+					}
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+
 		case len(path) > len("/api/projects/") && r.Method == http.MethodGet:
 			// Extract project ID and check for epics/tasks
 			remaining := path[len("/api/projects/"):]
@@ -729,7 +743,7 @@ func TestFilterAndSortTasks(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := filterAndSortTasks(tt.tasks, nil)
+			result := filterAndSortTasks(tt.tasks, nil, "todo", Newest)
 
 			if len(result) != tt.expectedLength {
 				t.Errorf("expected %d tasks, got %d", tt.expectedLength, len(result))
@@ -749,7 +763,7 @@ func TestFilterAndSortTasks(t *testing.T) {
 }
 
 func TestFilterAndSortTasksEmpty(t *testing.T) {
-	result := filterAndSortTasks([]client.Task{}, nil)
+	result := filterAndSortTasks([]client.Task{}, nil, "todo", Newest)
 	if len(result) != 0 {
 		t.Errorf("expected empty result, got %d tasks", len(result))
 	}
@@ -0,0 +1,106 @@
+package selection
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// Strategy orders candidate tasks. Candidates are sorted so that the task
+// for which Less reports true against every other comes first.
+type Strategy interface {
+	// Name returns the identifier used in flags and .momentum.yaml
+	Name() string
+
+	// Less reports whether task a should be picked before task b
+	Less(a, b client.Task) bool
+}
+
+// strategyFunc adapts a comparison function to the Strategy interface.
+type strategyFunc struct {
+	name string
+	less func(a, b client.Task) bool
+}
+
+func (s *strategyFunc) Name() string               { return s.name }
+func (s *strategyFunc) Less(a, b client.Task) bool { return s.less(a, b) }
+
+// Built-in strategies.
+var (
+	// Newest picks the most recently created task first. This is the default.
+	Newest Strategy = &strategyFunc{name: "newest", less: func(a, b client.Task) bool {
+		return olderFirst(b, a)
+	}}
+
+	// Oldest picks the least recently created task first (FIFO).
+	Oldest Strategy = &strategyFunc{name: "oldest", less: olderFirst}
+
+	// PriorityThenAge picks the most urgent task first, breaking ties by
+	// age (oldest first). Tasks without a priority come last.
+	PriorityThenAge Strategy = &strategyFunc{name: "priority", less: priorityThenAge}
+
+	// DueDate picks the task with the earliest due date first. Tasks
+	// without a due date come last and fall back to PriorityThenAge.
+	DueDate Strategy = &strategyFunc{name: "due-date", less: func(a, b client.Task) bool {
+		aDue, aOK := a.Due()
+		bDue, bOK := b.Due()
+		switch {
+		case aOK && bOK && !aDue.Equal(bDue):
+			return aDue.Before(bDue)
+		case aOK != bOK:
+			return aOK
+		}
+		return priorityThenAge(a, b)
+	}}
+)
+
+// DefaultStrategy is used when no strategy is configured.
+var DefaultStrategy = Newest
+
+var builtinStrategies = []Strategy{Newest, Oldest, PriorityThenAge, DueDate, CriticalPath}
+
+// StrategyNames returns the names of the built-in strategies.
+func StrategyNames() []string {
+	names := make([]string, 0, len(builtinStrategies))
+	for _, s := range builtinStrategies {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// StrategyByName returns the built-in strategy with the given name.
+// An empty name returns DefaultStrategy.
+func StrategyByName(name string) (Strategy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return DefaultStrategy, nil
+	}
+	for _, s := range builtinStrategies {
+		if s.Name() == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown selection strategy %q (use %s)", name, strings.Join(StrategyNames(), ", "))
+}
+
+// olderFirst orders by creation time when both tasks have one, falling
+// back to ID order (IDs increase over time).
+func olderFirst(a, b client.Task) bool {
+	aCreated, aOK := a.Created()
+	bCreated, bOK := b.Created()
+	if aOK && bOK && !aCreated.Equal(bCreated) {
+		return aCreated.Before(bCreated)
+	}
+	return a.ID < b.ID
+}
+
+func priorityThenAge(a, b client.Task) bool {
+	switch {
+	case a.Priority != nil && b.Priority != nil && *a.Priority != *b.Priority:
+		return *a.Priority < *b.Priority
+	case (a.Priority != nil) != (b.Priority != nil):
+		return a.Priority != nil
+	}
+	return olderFirst(a, b)
+}
@@ -0,0 +1,108 @@
+package selection
+
+import (
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+func TestStrategyOrdering(t *testing.T) {
+	tasks := []client.Task{
+		{ID: "task-1", Status: "todo", CreatedAt: "2026-01-01T00:00:00Z", Priority: client.IntPtr(2)},
+		{ID: "task-2", Status: "todo", CreatedAt: "2026-01-02T00:00:00Z", DueDate: "2026-03-01"},
+		{ID: "task-3", Status: "todo", CreatedAt: "2026-01-03T00:00:00Z", Priority: client.IntPtr(0)},
+		{ID: "task-4", Status: "todo", CreatedAt: "2026-01-04T00:00:00Z", Priority: client.IntPtr(2), DueDate: "2026-02-01T12:00:00Z"},
+	}
+
+	tests := []struct {
+		strategy Strategy
+		expected []string
+	}{
+		{Newest, []string{"task-4", "task-3", "task-2", "task-1"}},
+		{Oldest, []string{"task-1", "task-2", "task-3", "task-4"}},
+		{PriorityThenAge, []string{"task-3", "task-1", "task-4", "task-2"}},
+		{DueDate, []string{"task-4", "task-2", "task-3", "task-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy.Name(), func(t *testing.T) {
+			result := filterAndSortTasks(tasks, nil, "todo", tt.strategy)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %d tasks, got %d", len(tt.expected), len(result))
+			}
+			for i, id := range tt.expected {
+				if result[i].ID != id {
+					t.Errorf("position %d: expected %s, got %s", i, id, result[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestStrategyFallsBackToIDWithoutCreatedAt(t *testing.T) {
+	tasks := []client.Task{
+		{ID: "task-b", Status: "todo"},
+		{ID: "task-a", Status: "todo"},
+		{ID: "task-c", Status: "todo"},
+	}
+
+	result := filterAndSortTasks(tasks, nil, "todo", Oldest)
+	expected := []string{"task-a", "task-b", "task-c"}
+	for i, id := range expected {
+		if result[i].ID != id {
+			t.Errorf("position %d: expected %s, got %s", i, id, result[i].ID)
+		}
+	}
+}
+
+func TestStrategyByName(t *testing.T) {
+	for _, name := range StrategyNames() {
+		s, err := StrategyByName(name)
+		if err != nil {
+			t.Errorf("StrategyByName(%q): unexpected error %v", name, err)
+			continue
+		}
+		if s.Name() != name {
+			t.Errorf("StrategyByName(%q) returned %q", name, s.Name())
+		}
+	}
+
+	s, err := StrategyByName("")
+	if err != nil || s != DefaultStrategy {
+		t.Errorf("expected default strategy for empty name, got %v, %v", s, err)
+	}
+
+	if _, err := StrategyByName("random"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestSelectorUsesStrategy(t *testing.T) {
+	m := newMockServer()
+	m.projects = []client.Project{{ID: "proj-1"}}
+	m.epics["proj-1"] = []client.Epic{{ID: "epic-1", ProjectID: "proj-1", Auto: true}}
+	m.tasks["proj-1"] = []client.Task{
+		{ID: "task-1", Status: "todo", EpicID: "epic-1", Priority: client.IntPtr(1)},
+		{ID: "task-2", Status: "todo", EpicID: "epic-1"},
+	}
+	server, c := setupTest(m)
+	defer server.Close()
+
+	selector := NewSelector(c, "proj-1", "", "")
+	task, err := selector.SelectTask()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.ID != "task-2" {
+		t.Errorf("default strategy: expected task-2, got %s", task.ID)
+	}
+
+	selector.SetStrategy(PriorityThenAge)
+	task, err = selector.SelectTask()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.ID != "task-1" {
+		t.Errorf("priority strategy: expected task-1, got %s", task.ID)
+	}
+}
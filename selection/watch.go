@@ -0,0 +1,58 @@
+package selection
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// Watch lists the projects, epics and specific tasks a Selector draws
+// candidates from. An empty Watch covers all projects.
+type Watch struct {
+	Projects []string
+	Epics    []string
+	Tasks    []string
+}
+
+// IsEmpty reports whether the watch has no entries.
+func (w Watch) IsEmpty() bool {
+	return len(w.Projects) == 0 && len(w.Epics) == 0 && len(w.Tasks) == 0
+}
+
+// size returns the total number of entries.
+func (w Watch) size() int {
+	return len(w.Projects) + len(w.Epics) + len(w.Tasks)
+}
+
+// String describes the watch for display, e.g. "Project: p1" or
+// "Projects: p1, p2 · Epics: e1, e2, e3".
+func (w Watch) String() string {
+	if w.IsEmpty() {
+		return "All projects"
+	}
+	var parts []string
+	add := func(singular, plural string, ids []string) {
+		switch len(ids) {
+		case 0:
+		case 1:
+			parts = append(parts, fmt.Sprintf("%s: %s", singular, ids[0]))
+		default:
+			parts = append(parts, fmt.Sprintf("%s: %s", plural, strings.Join(ids, ", ")))
+		}
+	}
+	add("Project", "Projects", w.Projects)
+	add("Epic", "Epics", w.Epics)
+	add("Task", "Tasks", w.Tasks)
+	return strings.Join(parts, " · ")
+}
+
+// covers reports whether a task listed under projectID is watched.
+// An empty watch covers every task.
+func (w Watch) covers(task client.Task, projectID string) bool {
+	return w.IsEmpty() ||
+		slices.Contains(w.Tasks, task.ID) ||
+		(task.EpicID != "" && slices.Contains(w.Epics, task.EpicID)) ||
+		slices.Contains(w.Projects, projectID)
+}
@@ -0,0 +1,234 @@
+package selection
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// setupWatchTest creates three projects: web and api with auto epics, and
+// ops with one auto and one manual epic.
+func setupWatchTest(t *testing.T) *client.Client {
+	t.Helper()
+	m := newMockServer()
+	m.projects = []client.Project{{ID: "web"}, {ID: "api"}, {ID: "ops"}}
+	m.epics["web"] = []client.Epic{{ID: "epic-web", ProjectID: "web", Auto: true}}
+	m.epics["api"] = []client.Epic{{ID: "epic-api", ProjectID: "api", Auto: true}}
+	m.epics["ops"] = []client.Epic{
+		{ID: "epic-ops", ProjectID: "ops", Auto: true},
+		{ID: "epic-manual", ProjectID: "ops", Auto: false},
+	}
+	m.tasks["web"] = []client.Task{
+		{ID: "task-1", Status: "todo", ProjectID: "web", EpicID: "epic-web"},
+	}
+	m.tasks["api"] = []client.Task{
+		{ID: "task-2", Status: "todo", ProjectID: "api", EpicID: "epic-api"},
+	}
+	m.tasks["ops"] = []client.Task{
+		{ID: "task-3", Status: "todo", ProjectID: "ops", EpicID: "epic-ops"},
+		{ID: "task-4", Status: "todo", ProjectID: "ops", EpicID: "epic-manual"},
+		{ID: "task-5", Status: "todo", ProjectID: "ops", EpicID: "epic-manual", Blocked: true},
+	}
+	server, c := setupTest(m)
+	t.Cleanup(server.Close)
+	return c
+}
+
+// selectAll drains the selector and returns task IDs in selection order.
+func selectAll(t *testing.T, selector *Selector) []string {
+	t.Helper()
+	excluded := make(map[string]bool)
+	var ids []string
+	for {
+		task, err := selector.SelectTaskExcluding(excluded)
+		if errors.Is(err, ErrNoTaskAvailable) {
+			return ids
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		excluded[task.ID] = true
+		ids = append(ids, task.ID)
+	}
+}
+
+func TestWatchSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		watch    Watch
+		expected []string
+	}{
+		{
+			name:     "two projects merged under strategy",
+			watch:    Watch{Projects: []string{"web", "api"}},
+			expected: []string{"task-2", "task-1"},
+		},
+		{
+			name:     "project plus epic from another project",
+			watch:    Watch{Projects: []string{"web"}, Epics: []string{"epic-ops"}},
+			expected: []string{"task-3", "task-1"},
+		},
+		{
+			name:     "overlapping entries are deduplicated",
+			watch:    Watch{Projects: []string{"ops"}, Epics: []string{"epic-ops"}},
+			expected: []string{"task-3"},
+		},
+		{
+			name:     "non-auto epic is skipped",
+			watch:    Watch{Epics: []string{"epic-manual", "epic-api"}},
+			expected: []string{"task-2"},
+		},
+		{
+			name:     "explicit task qualifies outside auto epics",
+			watch:    Watch{Projects: []string{"web"}, Tasks: []string{"task-4"}},
+			expected: []string{"task-4", "task-1"},
+		},
+		{
+			name:     "explicit blocked task is still skipped",
+			watch:    Watch{Projects: []string{"web"}, Tasks: []string{"task-5"}},
+			expected: []string{"task-1"},
+		},
+		{
+			name:     "unknown entries are ignored",
+			watch:    Watch{Projects: []string{"missing", "api"}, Epics: []string{"epic-missing"}},
+			expected: []string{"task-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector := NewWatchSelector(setupWatchTest(t), tt.watch)
+			got := selectAll(t, selector)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchSelector_LoadsOnlyWatchedProjects(t *testing.T) {
+	newServer := func() *mockServer {
+		m := newMockServer()
+		m.projects = []client.Project{{ID: "web"}, {ID: "api"}, {ID: "ops"}}
+		m.epics["web"] = []client.Epic{{ID: "epic-web", ProjectID: "web", Auto: true}}
+		m.epics["api"] = []client.Epic{{ID: "epic-api", ProjectID: "api", Auto: true}}
+		m.epics["ops"] = []client.Epic{
+			{ID: "epic-ops", ProjectID: "ops", Auto: true},
+			{ID: "epic-deps", ProjectID: "ops", Auto: true},
+		}
+		m.tasks["web"] = []client.Task{{ID: "task-1", Status: "done", ProjectID: "web", EpicID: "epic-web"}}
+		m.tasks["api"] = []client.Task{{ID: "task-2", Status: "todo", ProjectID: "api", EpicID: "epic-api"}}
+		m.tasks["ops"] = []client.Task{
+			{ID: "task-3", Status: "todo", ProjectID: "ops", EpicID: "epic-ops"},
+			{ID: "task-4", Status: "todo", ProjectID: "ops", EpicID: "epic-deps", DependsOn: []string{"task-1"}},
+		}
+		return m
+	}
+	loaded := func(m *mockServer) []string {
+		var projects []string
+		for _, path := range m.requests {
+			for _, p := range m.projects {
+				if path == "/api/projects/"+p.ID+"/tasks" && !slices.Contains(projects, p.ID) {
+					projects = append(projects, p.ID)
+				}
+			}
+		}
+		slices.Sort(projects)
+		return projects
+	}
+
+	tests := []struct {
+		name   string
+		watch  Watch
+		loaded []string
+	}{
+		{name: "epic", watch: Watch{Epics: []string{"epic-ops"}}, loaded: []string{"ops"}},
+		{name: "task", watch: Watch{Projects: []string{"web"}, Tasks: []string{"task-2"}}, loaded: []string{"api", "web"}},
+		{name: "dependency in another project", watch: Watch{Epics: []string{"epic-deps"}}, loaded: []string{"api", "ops", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newServer()
+			server, c := setupTest(m)
+			defer server.Close()
+			selector := NewWatchSelector(c, tt.watch)
+
+			if _, err := selector.SelectTask(); err != nil {
+				t.Fatal(err)
+			}
+			if got := loaded(m); !slices.Equal(got, tt.loaded) {
+				t.Errorf("loaded tasks of %v, want %v", got, tt.loaded)
+			}
+
+			// The projects of watched epics and tasks are looked up once
+			m.requests = nil
+			if _, err := selector.SelectTask(); err != nil {
+				t.Fatal(err)
+			}
+			for _, path := range m.requests {
+				if path == "/api/projects" && len(tt.loaded) < len(m.projects) {
+					t.Errorf("expected projects not to be listed again, got %v", m.requests)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchSelector_AppliesFilter(t *testing.T) {
+	selector := NewWatchSelector(setupWatchTest(t), Watch{Projects: []string{"web", "api"}})
+	f, err := NewFilter(nil, nil, nil, []string{"never-matches"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector.SetFilter(f)
+
+	if _, err := selector.SelectTask(); !errors.Is(err, ErrNoTaskAvailable) {
+		t.Errorf("expected ErrNoTaskAvailable, got %v", err)
+	}
+}
+
+func TestNewSelector_KeepsPrecedence(t *testing.T) {
+	c := setupWatchTest(t)
+	tests := []struct {
+		name                    string
+		projectID, epicID, task string
+		expected                string
+	}{
+		{"task wins", "web", "epic-api", "task-4", "Task: task-4"},
+		{"epic over project", "web", "epic-api", "", "Epic: epic-api"},
+		{"project only", "web", "", "", "Project: web"},
+		{"nothing", "", "", "", "All projects"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewSelector(c, tt.projectID, tt.epicID, tt.task).Watch().String()
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWatchString(t *testing.T) {
+	tests := []struct {
+		watch    Watch
+		expected string
+	}{
+		{Watch{}, "All projects"},
+		{Watch{Projects: []string{"p1"}}, "Project: p1"},
+		{Watch{Projects: []string{"p1", "p2"}, Epics: []string{"e1"}}, "Projects: p1, p2 · Epic: e1"},
+		{Watch{Tasks: []string{"t1", "t2"}}, "Tasks: t1, t2"},
+	}
+	for _, tt := range tests {
+		if got := tt.watch.String(); got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// poller detects board changes by listing projects, epics and tasks and
+// diffing them against the previous snapshot. It is used while SSE is down,
+// and emits the same event types the Flux server would have sent.
+type poller struct {
+	baseURL string
+	client  *http.Client
+	// cache holds the last response for each polled URL
+	cache map[string]*pollResponse
+	// epics and tasks are the last snapshot, keyed by ID
+	epics map[string]polledItem
+	tasks map[string]polledItem
+	// primed is set once the first snapshot has been taken
+	primed bool
+}
+
+// pollResponse is a cached list response used for conditional requests.
+type pollResponse struct {
+	etag         string
+	lastModified string
+	hash         [sha256.Size]byte
+	body         []byte
+}
+
+// polledItem is one epic or task from a list response.
+type polledItem struct {
+	raw    json.RawMessage
+	status string
+	epicID string
+}
+
+func newPoller(baseURL string, client *http.Client) *poller {
+	return &poller{
+		baseURL: baseURL,
+		client:  client,
+		cache:   make(map[string]*pollResponse),
+	}
+}
+
+// poll fetches the board and returns an event for every epic and task that
+// was created, changed or deleted since the last poll. The first poll only
+// records a snapshot and returns no events.
+func (p *poller) poll(ctx context.Context) ([]Event, error) {
+	body, changed, err := p.fetch(ctx, "/api/projects")
+	if err != nil {
+		return nil, err
+	}
+	var projects []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &projects); err != nil {
+		return nil, fmt.Errorf("failed to decode projects: %w", err)
+	}
+
+	var epicBodies, taskBodies [][]byte
+	paths := map[string]bool{"/api/projects": true}
+	for _, project := range projects {
+		id := url.PathEscape(project.ID)
+		epicsPath := fmt.Sprintf("/api/projects/%s/epics", id)
+		epicBody, epicsChanged, err := p.fetch(ctx, epicsPath)
+		if err != nil {
+			return nil, err
+		}
+		tasksPath := fmt.Sprintf("/api/projects/%s/tasks", id)
+		taskBody, tasksChanged, err := p.fetch(ctx, tasksPath)
+		if err != nil {
+			return nil, err
+		}
+		epicBodies = append(epicBodies, epicBody)
+		taskBodies = append(taskBodies, taskBody)
+		paths[epicsPath], paths[tasksPath] = true, true
+		changed = changed || epicsChanged || tasksChanged
+	}
+	// Forget projects that no longer exist
+	for path := range p.cache {
+		if !paths[path] {
+			delete(p.cache, path)
+		}
+	}
+
+	if p.primed && !changed {
+		return nil, nil
+	}
+
+	epics, epicOrder, err := decodeItems(epicBodies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode epics: %w", err)
+	}
+	tasks, taskOrder, err := decodeItems(taskBodies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tasks: %w", err)
+	}
+
+	var events []Event
+	if p.primed {
+		events = append(events, diffItems(p.epics, epics, epicOrder, "epic", func(item polledItem) string {
+			return fmt.Sprintf(`{"epic":%s}`, item.raw)
+		})...)
+		events = append(events, diffItems(p.tasks, tasks, taskOrder, "task", func(item polledItem) string {
+			if epic, ok := epics[item.epicID]; ok {
+				return fmt.Sprintf(`{"epic":%s,"task":%s}`, epic.raw, item.raw)
+			}
+			if epic, ok := p.epics[item.epicID]; ok {
+				return fmt.Sprintf(`{"epic":%s,"task":%s}`, epic.raw, item.raw)
+			}
+			return fmt.Sprintf(`{"task":%s}`, item.raw)
+		})...)
+	}
+	p.epics, p.tasks, p.primed = epics, tasks, true
+	return events, nil
+}
+
+// fetch GETs path, using the ETag and Last-Modified of the previous response
+// for a conditional request. It reports whether the body differs from the
+// previous one; servers without validators are compared by content hash.
+func (p *poller) fetch(ctx context.Context, path string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	cached := p.cache[path]
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to poll %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.body, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status code polling %s: %d", path, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	next := &pollResponse{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		hash:         sha256.Sum256(body),
+		body:         body,
+	}
+	p.cache[path] = next
+	return body, cached == nil || cached.hash != next.hash, nil
+}
+
+// decodeItems decodes JSON arrays of epics or tasks into a map keyed by ID,
+// plus the IDs in list order.
+func decodeItems(bodies [][]byte) (map[string]polledItem, []string, error) {
+	items := make(map[string]polledItem)
+	var order []string
+	for _, body := range bodies {
+		var raws []json.RawMessage
+		if err := json.Unmarshal(body, &raws); err != nil {
+			return nil, nil, err
+		}
+		for _, raw := range raws {
+			var fields struct {
+				ID     string `json:"id"`
+				Status string `json:"status"`
+				EpicID string `json:"epic_id"`
+			}
+			if err := json.Unmarshal(raw, &fields); err != nil {
+				return nil, nil, err
+			}
+			if _, ok := items[fields.ID]; ok {
+				continue
+			}
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, raw); err != nil {
+				return nil, nil, err
+			}
+			items[fields.ID] = polledItem{raw: compact.Bytes(), status: fields.Status, epicID: fields.EpicID}
+			order = append(order, fields.ID)
+		}
+	}
+	return items, order, nil
+}
+
+// diffItems returns <kind>.created, <kind>.updated and <kind>.deleted events
+// for the differences between two snapshots. As with Flux, a task whose
+// status changed gets task.status_changed instead of task.updated.
+func diffItems(prev, next map[string]polledItem, order []string, kind string, payload func(polledItem) string) []Event {
+	var events []Event
+	for _, id := range order {
+		item := next[id]
+		old, ok := prev[id]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: kind + ".created", Data: payload(item)})
+		case kind == "task" && old.status != item.status:
+			events = append(events, Event{Type: kind + ".status_changed", Data: payload(item)})
+		case !bytes.Equal(old.raw, item.raw):
+			events = append(events, Event{Type: kind + ".updated", Data: payload(item)})
+		}
+	}
+
+	var deleted []string
+	for id := range prev {
+		if _, ok := next[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+	sort.Strings(deleted)
+	for _, id := range deleted {
+		events = append(events, Event{Type: kind + ".deleted", Data: payload(prev[id])})
+	}
+	return events
+}
@@ -0,0 +1,172 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/fluxtest"
+)
+
+func TestPollerEmitsOnlyRealChanges(t *testing.T) {
+	flux := fluxtest.New()
+	server := httptest.NewServer(flux)
+	defer server.Close()
+	c := client.NewClient(server.URL)
+
+	project := flux.AddProject("Demo", "")
+	epic := flux.AddEpic(project.ID, "Auto", true)
+	existing := flux.AddTask(client.Task{Title: "Existing", ProjectID: project.ID, EpicID: epic.ID})
+
+	p := newPoller(server.URL, http.DefaultClient)
+	poll := func() []Event {
+		t.Helper()
+		events, err := p.poll(context.Background())
+		if err != nil {
+			t.Fatalf("poll() error = %v", err)
+		}
+		return events
+	}
+
+	if events := poll(); len(events) != 0 {
+		t.Fatalf("first poll returned %d events, want none", len(events))
+	}
+	if events := poll(); len(events) != 0 {
+		t.Fatalf("unchanged poll returned %v, want none", events)
+	}
+
+	created := flux.AddTask(client.Task{Title: "New", ProjectID: project.ID, EpicID: epic.ID})
+	events := poll()
+	if len(events) != 1 || events[0].Type != "task.created" {
+		t.Fatalf("events after create = %v, want one task.created", events)
+	}
+	var data struct {
+		Task client.Task `json:"task"`
+		Epic client.Epic `json:"epic"`
+	}
+	if err := json.Unmarshal([]byte(events[0].Data), &data); err != nil {
+		t.Fatalf("invalid payload %q: %v", events[0].Data, err)
+	}
+	if data.Task.ID != created.ID || data.Epic.ID != epic.ID || !data.Epic.Auto {
+		t.Errorf("payload = %+v, want task %s in auto epic %s", data, created.ID, epic.ID)
+	}
+
+	if _, err := c.MoveTaskStatus(existing.ID, "in_progress"); err != nil {
+		t.Fatal(err)
+	}
+	title := "Renamed"
+	if _, err := c.UpdateTask(created.ID, client.TaskUpdate{Title: &title}); err != nil {
+		t.Fatal(err)
+	}
+	if err := flux.SetEpicAuto(epic.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, event := range poll() {
+		types = append(types, event.Type)
+	}
+	want := "epic.updated task.status_changed task.updated"
+	if got := strings.Join(types, " "); got != want {
+		t.Errorf("event types = %q, want %q", got, want)
+	}
+
+	if err := c.DeleteTask(existing.ID); err != nil {
+		t.Fatal(err)
+	}
+	events = poll()
+	if len(events) != 1 || events[0].Type != "task.deleted" || !strings.Contains(events[0].Data, existing.ID) {
+		t.Errorf("events after delete = %v, want one task.deleted for %s", events, existing.ID)
+	}
+}
+
+func TestPollerConditionalRequests(t *testing.T) {
+	var mu sync.Mutex
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional = append(conditional, r.URL.Path)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		switch r.URL.Path {
+		case "/api/projects":
+			w.Write([]byte(`[{"id":"p1"}]`))
+		case "/api/projects/p1/tasks":
+			w.Write([]byte(`[{"id":"t1","status":"todo"}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	p := newPoller(server.URL, http.DefaultClient)
+	for i := range 2 {
+		events, err := p.poll(context.Background())
+		if err != nil {
+			t.Fatalf("poll %d error = %v", i, err)
+		}
+		if len(events) != 0 {
+			t.Errorf("poll %d returned %v, want none", i, events)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conditional) != 3 {
+		t.Errorf("conditional requests = %v, want projects, epics and tasks", conditional)
+	}
+}
+
+func TestPollingFallbackEmitsTaskEvents(t *testing.T) {
+	flux := fluxtest.New()
+	project := flux.AddProject("Demo", "")
+	epic := flux.AddEpic(project.ID, "Auto", true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/events" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		flux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	sub := NewSubscriber(server.URL)
+	sub.maxFailuresBeforePolling = 1
+	sub.pollingInterval = 20 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	events := sub.Start(ctx)
+	defer sub.Stop()
+
+	// Let the first poll take its snapshot
+	time.Sleep(100 * time.Millisecond)
+	task := flux.AddTask(client.Task{Title: "New", ProjectID: project.ID, EpicID: epic.ID})
+
+	for {
+		select {
+		case event := <-events:
+			if event.Type != "task.created" {
+				t.Fatalf("got %q event, want task.created only", event.Type)
+			}
+			if !strings.Contains(event.Data, task.ID) {
+				t.Fatalf("event data %q does not mention %s", event.Data, task.ID)
+			}
+			if got := sub.State(); got != StatePolling {
+				t.Errorf("State() = %v, want polling", got)
+			}
+			return
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for task.created from polling")
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package sse
+
+import "time"
+
+// ConnState is the health of the subscriber's link to Flux.
+type ConnState int
+
+const (
+	// StateIdle means the subscriber has not started yet.
+	StateIdle ConnState = iota
+	// StateConnecting means an SSE connection is being opened.
+	StateConnecting
+	// StateLive means events are streaming over SSE.
+	StateLive
+	// StatePolling means SSE is unavailable and the task lists are polled.
+	StatePolling
+	// StateDown means neither SSE nor polling reached the server.
+	StateDown
+)
+
+// String returns the state name: "idle", "connecting", "live", "polling"
+// or "down".
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateLive:
+		return "live"
+	case StatePolling:
+		return "polling"
+	case StateDown:
+		return "down"
+	}
+	return "idle"
+}
+
+// Transport returns how events currently arrive: "SSE", "polling", or ""
+// when they do not.
+func (s ConnState) Transport() string {
+	switch s {
+	case StateLive:
+		return "SSE"
+	case StatePolling:
+		return "polling"
+	}
+	return ""
+}
+
+// OnStateChange registers fn to be called with each new connection state.
+// Call it before Start. fn runs on the subscriber's goroutine and must not
+// block for long.
+func (s *Subscriber) OnStateChange(fn func(ConnState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onState = fn
+}
+
+// SetIdleTimeout sets how long a connection may stay silent, heartbeat
+// comments included, before it is dropped and reopened. Zero disables the
+// check. Call it before Start.
+func (s *Subscriber) SetIdleTimeout(d time.Duration) {
+	s.idleTimeout = d
+}
+
+// State returns the current connection state.
+func (s *Subscriber) State() ConnState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// LastEventAt returns when the last event was received, or the zero time
+// if none has been.
+func (s *Subscriber) LastEventAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEventAt
+}
+
+// setState records a state change and notifies the OnStateChange handler.
+func (s *Subscriber) setState(state ConnState) {
+	s.mu.Lock()
+	if s.state == state {
+		s.mu.Unlock()
+		return
+	}
+	s.state = state
+	fn := s.onState
+	s.mu.Unlock()
+
+	if fn != nil {
+		fn(state)
+	}
+}
@@ -0,0 +1,187 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestIdleTimeoutForcesReconnect tests that a silent connection is dropped
+// without reporting the connection as down.
+func TestIdleTimeoutForcesReconnect(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": connected\n\n")
+		w.(http.Flusher).Flush()
+		// Then go silent, like a half-open connection
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var states []ConnState
+	sub := NewSubscriber(server.URL)
+	sub.SetIdleTimeout(50 * time.Millisecond)
+	sub.reconnectDelay = 10 * time.Millisecond
+	sub.baseReconnectDelay = 10 * time.Millisecond
+	sub.OnStateChange(func(state ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, state)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	sub.Start(ctx)
+	defer sub.Stop()
+
+	for connections.Load() < 3 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("connections = %d, want reconnects after the idle timeout", connections.Load())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if slices.Contains(states, StateDown) {
+		t.Errorf("states = %v, want idle reconnects not to report down", states)
+	}
+	if !slices.Contains(states[1:], StateConnecting) {
+		t.Errorf("states = %v, want connecting before each reconnect", states)
+	}
+}
+
+// TestHeartbeatKeepsConnectionAlive tests that comments reset the idle timer.
+func TestHeartbeatKeepsConnectionAlive(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	defer server.Close()
+
+	sub := NewSubscriber(server.URL)
+	sub.SetIdleTimeout(100 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub.Start(ctx)
+	defer sub.Stop()
+
+	time.Sleep(400 * time.Millisecond)
+	if n := connections.Load(); n != 1 {
+		t.Errorf("connections = %d, want 1", n)
+	}
+	if got := sub.State(); got != StateLive {
+		t.Errorf("State() = %v, want live", got)
+	}
+}
+
+// TestStateChanges tests the states reported through OnStateChange.
+func TestStateChanges(t *testing.T) {
+	var fail atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: task.created\ndata: {}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var states []ConnState
+	waitFor := func(state ConnState) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			seen := slices.Contains(states, state)
+			mu.Unlock()
+			if seen {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		t.Fatalf("states = %v, never reached %v", states, state)
+	}
+
+	sub := NewSubscriber(server.URL)
+	sub.SetIdleTimeout(50 * time.Millisecond)
+	sub.reconnectDelay = 10 * time.Millisecond
+	sub.baseReconnectDelay = 10 * time.Millisecond
+	sub.maxFailuresBeforePolling = 2
+	sub.pollingInterval = 20 * time.Millisecond
+	sub.OnStateChange(func(state ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, state)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sub.Start(ctx)
+	defer sub.Stop()
+
+	waitFor(StateLive)
+	if sub.LastEventAt().IsZero() {
+		t.Error("LastEventAt() is zero after an event")
+	}
+	mu.Lock()
+	if states[0] != StateConnecting {
+		t.Errorf("first state = %v, want connecting", states[0])
+	}
+	mu.Unlock()
+
+	// Every request now fails, so SSE and polling are both down
+	fail.Store(true)
+	waitFor(StateDown)
+}
+
+func TestConnStateTransport(t *testing.T) {
+	tests := []struct {
+		state     ConnState
+		name      string
+		transport string
+	}{
+		{StateIdle, "idle", ""},
+		{StateConnecting, "connecting", ""},
+		{StateLive, "live", "SSE"},
+		{StatePolling, "polling", "polling"},
+		{StateDown, "down", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.name {
+			t.Errorf("String() = %q, want %q", got, tt.name)
+		}
+		if got := tt.state.Transport(); got != tt.transport {
+			t.Errorf("%s Transport() = %q, want %q", tt.name, got, tt.transport)
+		}
+	}
+}
@@ -1,25 +1,30 @@
 // Package sse provides Server-Sent Events (SSE) subscription functionality
 // for the Flux API. It handles automatic reconnection with exponential backoff
-// and falls back to polling if SSE connections fail repeatedly.
+// and falls back to polling the task lists if SSE connections fail repeatedly.
 package sse
 
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Event represents a Server-Sent Event received from the Flux API.
 type Event struct {
-	// Type is the event type (e.g., "data-changed", "message")
+	// Type is the event type (e.g., "task.created", "message")
 	Type string
 	// Data is the event payload
 	Data string
+	// ID is the event ID set by the server, if any
+	ID string
 }
 
 // Subscriber manages an SSE connection to the Flux API.
@@ -30,22 +35,45 @@ type Subscriber struct {
 	url string
 	// reconnectDelay is the current delay before attempting reconnection
 	reconnectDelay time.Duration
+	// baseReconnectDelay is the delay after a successful connection; the
+	// server can change it with a retry: field
+	baseReconnectDelay time.Duration
 	// maxReconnectDelay is the maximum delay between reconnection attempts
 	maxReconnectDelay time.Duration
 	// events is the channel where received events are sent
 	events chan Event
 	// done is used to signal graceful shutdown
 	done chan struct{}
-	// mu protects the running state
+	// mu protects the running state, the last event ID and subscriptions
 	mu sync.Mutex
 	// running indicates whether the subscriber is active
 	running bool
+	// lastEventID is the ID of the last event received, sent as
+	// Last-Event-ID on reconnect so the server can replay missed events
+	lastEventID string
 	// consecutiveFailures tracks SSE connection failures for fallback logic
 	consecutiveFailures int
 	// maxFailuresBeforePolling is the threshold before falling back to polling
 	maxFailuresBeforePolling int
 	// pollingInterval is the interval for polling fallback
 	pollingInterval time.Duration
+	// polling is set while the subscriber is in polling fallback
+	polling bool
+	// poller diffs the task lists while polling
+	poller *poller
+	// subscriptions are the typed consumers registered with Subscribe
+	subscriptions []*subscription
+	// closed is set once the subscriber has shut down
+	closed bool
+	// idleTimeout forces a reconnect when a connection sends nothing,
+	// not even a comment, for this long. Zero disables it.
+	idleTimeout time.Duration
+	// state is the current connection state
+	state ConnState
+	// onState is called on every state change
+	onState func(ConnState)
+	// lastEventAt is when the last event was received
+	lastEventAt time.Time
 	// client is the HTTP client used for connections
 	client *http.Client
 }
@@ -59,19 +87,46 @@ func NewSubscriber(baseURL string) *Subscriber {
 	return &Subscriber{
 		url:                      fmt.Sprintf("%s/api/events", baseURL),
 		reconnectDelay:           1 * time.Second,
+		baseReconnectDelay:       1 * time.Second,
 		maxReconnectDelay:        30 * time.Second,
 		events:                   make(chan Event, 100),
 		done:                     make(chan struct{}),
 		maxFailuresBeforePolling: 5,
 		pollingInterval:          5 * time.Second,
+		poller:                   newPoller(baseURL, &http.Client{Timeout: 10 * time.Second}),
+		idleTimeout:              45 * time.Second,
 		client: &http.Client{
 			Timeout: 0, // No timeout for SSE connections
 		},
 	}
 }
 
+// LastEventID returns the ID of the last event received. Persist it and
+// pass it to ResumeFrom to continue from the same position after a restart.
+func (s *Subscriber) LastEventID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEventID
+}
+
+// ResumeFrom sets the event ID to resume from. The first connection sends it
+// as Last-Event-ID so the server can replay events missed while stopped.
+// Call it before Start.
+func (s *Subscriber) ResumeFrom(eventID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastEventID = eventID
+}
+
+func (s *Subscriber) setLastEventID(eventID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastEventID = eventID
+}
+
 // Start begins the SSE subscription and returns a channel for receiving events.
-// The subscription will automatically reconnect on connection loss.
+// The subscription will automatically reconnect on connection loss. Callers
+// that consume events through Subscribe may ignore the returned channel.
 // Use the provided context or call Stop() to terminate the subscription.
 func (s *Subscriber) Start(ctx context.Context) <-chan Event {
 	s.mu.Lock()
@@ -103,6 +158,7 @@ func (s *Subscriber) Stop() {
 // run is the main loop that manages the SSE connection or polling fallback.
 func (s *Subscriber) run(ctx context.Context) {
 	defer close(s.events)
+	defer s.closeSubscriptions()
 
 	for {
 		select {
@@ -120,14 +176,33 @@ func (s *Subscriber) run(ctx context.Context) {
 				continue
 			}
 
-			// Attempt SSE connection
+			// Attempt SSE connection. While polling, the retry after each
+			// poll is not announced so the state does not flicker.
+			if !s.polling {
+				s.setState(StateConnecting)
+			}
 			err := s.connect(ctx)
+			if errors.Is(err, errIdle) {
+				// The server answered and then went quiet; reconnecting is
+				// routine rather than a failure
+				log.Printf("SSE subscriber: %v", err)
+				s.waitWithContext(ctx, s.reconnectDelay)
+				continue
+			}
 			if err != nil {
 				s.consecutiveFailures++
 				log.Printf("SSE subscriber: connection error (attempt %d): %v", s.consecutiveFailures, err)
+				if !s.polling && ctx.Err() == nil {
+					s.setState(StateDown)
+				}
 
 				if s.consecutiveFailures >= s.maxFailuresBeforePolling {
-					log.Printf("SSE subscriber: falling back to polling (every %v)", s.pollingInterval)
+					// The polling interval paces retries from here on
+					if !s.polling {
+						log.Printf("SSE subscriber: falling back to polling (every %v)", s.pollingInterval)
+						s.polling = true
+					}
+					continue
 				}
 
 				s.handleReconnect(ctx)
@@ -136,10 +211,28 @@ func (s *Subscriber) run(ctx context.Context) {
 	}
 }
 
+// errIdle is returned by connect when a live connection sends nothing for
+// the idle timeout.
+var errIdle = errors.New("connection idle")
+
 // connect establishes an SSE connection and processes incoming events.
 // It returns when the connection is closed or an error occurs.
 func (s *Subscriber) connect(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	connCtx, cancelConn := context.WithCancel(ctx)
+	defer cancelConn()
+
+	// A half-open connection never errors; drop it when it goes quiet
+	var idle atomic.Bool
+	var watchdog *time.Timer
+	if s.idleTimeout > 0 {
+		watchdog = time.AfterFunc(s.idleTimeout, func() {
+			idle.Store(true)
+			cancelConn()
+		})
+		defer watchdog.Stop()
+	}
+
+	req, err := http.NewRequestWithContext(connCtx, http.MethodGet, s.url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -148,9 +241,15 @@ func (s *Subscriber) connect(ctx context.Context) error {
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
+	if lastEventID := s.LastEventID(); lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
+		if idle.Load() {
+			return fmt.Errorf("no response for %v", s.idleTimeout)
+		}
 		return fmt.Errorf("failed to connect: %w", err)
 	}
 	defer resp.Body.Close()
@@ -161,6 +260,7 @@ func (s *Subscriber) connect(ctx context.Context) error {
 
 	// Reset backoff on successful connection
 	s.resetBackoff()
+	s.setState(StateLive)
 	log.Printf("SSE subscriber: connected to %s", s.url)
 
 	// Read and parse SSE events
@@ -176,10 +276,19 @@ func (s *Subscriber) connect(ctx context.Context) error {
 		default:
 		}
 
+		// Any line, including comments, shows the connection is alive
+		if watchdog != nil {
+			watchdog.Reset(s.idleTimeout)
+		}
+
 		line := scanner.Text()
 
 		// Empty line indicates end of event
 		if line == "" {
+			// The ID counts as the resume position even without data
+			if currentEvent.ID != "" {
+				s.setLastEventID(currentEvent.ID)
+			}
 			if currentEvent.Data != "" {
 				// Default event type if none specified
 				if currentEvent.Type == "" {
@@ -203,16 +312,23 @@ func (s *Subscriber) connect(ctx context.Context) error {
 		} else if strings.HasPrefix(line, "event:") {
 			currentEvent.Type = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
 		} else if strings.HasPrefix(line, "id:") {
-			// Event ID - could be used for Last-Event-ID header on reconnect
-			// Currently not implemented
+			// IDs containing NULL are ignored, as in the SSE spec
+			if id := strings.TrimSpace(strings.TrimPrefix(line, "id:")); !strings.ContainsRune(id, 0) {
+				currentEvent.ID = id
+			}
 		} else if strings.HasPrefix(line, "retry:") {
-			// Server-suggested retry interval - could be parsed and used
-			// Currently not implemented
+			// Server-suggested reconnect delay in milliseconds
+			if ms, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "retry:"))); err == nil && ms >= 0 {
+				s.setRetry(time.Duration(ms) * time.Millisecond)
+			}
 		} else if strings.HasPrefix(line, ":") {
 			// Comment line, ignore
 		}
 	}
 
+	if idle.Load() {
+		return fmt.Errorf("no data for %v, reconnecting: %w", s.idleTimeout, errIdle)
+	}
 	if err := scanner.Err(); err != nil {
 		return fmt.Errorf("scanner error: %w", err)
 	}
@@ -225,7 +341,7 @@ func (s *Subscriber) handleReconnect(ctx context.Context) {
 	s.waitWithContext(ctx, s.reconnectDelay)
 
 	// Exponential backoff: double the delay up to max
-	s.reconnectDelay *= 2
+	s.reconnectDelay = max(s.reconnectDelay*2, time.Millisecond)
 	if s.reconnectDelay > s.maxReconnectDelay {
 		s.reconnectDelay = s.maxReconnectDelay
 	}
@@ -233,8 +349,19 @@ func (s *Subscriber) handleReconnect(ctx context.Context) {
 
 // resetBackoff resets the reconnection delay and failure counter.
 func (s *Subscriber) resetBackoff() {
-	s.reconnectDelay = 1 * time.Second
+	s.reconnectDelay = s.baseReconnectDelay
 	s.consecutiveFailures = 0
+	s.polling = false
+}
+
+// setRetry applies a server retry: hint. It becomes the delay before the
+// next reconnect and the base for backoff after repeated failures.
+func (s *Subscriber) setRetry(delay time.Duration) {
+	s.baseReconnectDelay = delay
+	s.reconnectDelay = delay
+	if delay > s.maxReconnectDelay {
+		s.maxReconnectDelay = delay
+	}
 }
 
 // waitWithContext waits for the specified duration or until context is cancelled.
@@ -246,51 +373,45 @@ func (s *Subscriber) waitWithContext(ctx context.Context, duration time.Duration
 	}
 }
 
-// sendEvent sends an event to the events channel without blocking.
+// sendEvent sends an event to the events channel and to matching
+// subscriptions without blocking.
 func (s *Subscriber) sendEvent(event Event) {
+	s.mu.Lock()
+	s.lastEventAt = time.Now()
+	s.mu.Unlock()
+
+	subscribed := s.publish(event)
 	select {
 	case s.events <- event:
 		// Successfully sent
 	default:
-		// Channel full, log warning but don't block
-		log.Printf("SSE subscriber: event channel full, dropping event of type %q", event.Type)
+		// Channel full. Callers using only Subscribe never drain it, so
+		// only warn when it is the sole consumer.
+		if !subscribed {
+			log.Printf("SSE subscriber: event channel full, dropping event of type %q", event.Type)
+		}
 	}
 }
 
-// pollOnce performs a single polling request to check for data changes.
-// This is used as a fallback when SSE connections fail repeatedly.
+// pollOnce polls the task lists once and emits an event for each real
+// change. This is used as a fallback when SSE connections fail repeatedly.
 func (s *Subscriber) pollOnce(ctx context.Context) {
-	// Create a polling request to a health or status endpoint
-	// Since we're simulating data-changed events, we just emit a synthetic event
-	// In a real implementation, this might check a specific API endpoint
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
-	if err != nil {
-		log.Printf("SSE subscriber: polling request creation failed: %v", err)
-		return
-	}
-
-	// Use a short timeout for polling
-	pollClient := &http.Client{Timeout: 10 * time.Second}
-	resp, err := pollClient.Do(req)
+	events, err := s.poller.poll(ctx)
 	if err != nil {
-		log.Printf("SSE subscriber: polling request failed: %v", err)
-		// Try to reconnect via SSE after a successful poll might indicate server is back
+		log.Printf("SSE subscriber: polling failed: %v", err)
+		if ctx.Err() == nil {
+			s.setState(StateDown)
+		}
 		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusOK {
-		// Server is responding - try switching back to SSE
-		log.Println("SSE subscriber: server responding, attempting to resume SSE")
-		s.resetBackoff()
+	s.setState(StatePolling)
+	for _, event := range events {
+		s.sendEvent(event)
 	}
 
-	// Emit a synthetic data-changed event to trigger a refresh
-	s.sendEvent(Event{
-		Type: "data-changed",
-		Data: `{"source":"polling","message":"periodic refresh"}`,
-	})
+	// The server is responding, so give SSE another try. If it fails
+	// again we are straight back to polling.
+	s.consecutiveFailures = s.maxFailuresBeforePolling - 1
 }
 
 // IsRunning returns whether the subscriber is currently active.
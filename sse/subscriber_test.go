@@ -503,8 +503,8 @@ func TestPollingFallback(t *testing.T) {
 	}
 }
 
-// TestIDAndRetryFieldsIgnored tests that id: and retry: fields don't cause errors.
-func TestIDAndRetryFieldsIgnored(t *testing.T) {
+// TestIDAndRetryFields tests that id: is tracked and retry: sets the reconnect delay.
+func TestIDAndRetryFields(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/event-stream")
 
@@ -536,13 +536,110 @@ func TestIDAndRetryFieldsIgnored(t *testing.T) {
 		if event.Data != "test" {
 			t.Errorf("expected data 'test', got %q", event.Data)
 		}
+		if event.ID != "12345" {
+			t.Errorf("expected ID '12345', got %q", event.ID)
+		}
 	case <-ctx.Done():
 		t.Error("timed out waiting for event")
 	}
 
+	if got := sub.LastEventID(); got != "12345" {
+		t.Errorf("expected last event ID '12345', got %q", got)
+	}
+	if sub.baseReconnectDelay != 5*time.Second {
+		t.Errorf("expected retry hint of 5s, got %v", sub.baseReconnectDelay)
+	}
+
 	sub.Stop()
 }
 
+// TestReconnectSendsLastEventID tests that reconnects resume from the last
+// event ID and wait for the server's retry hint.
+func TestReconnectSendsLastEventID(t *testing.T) {
+	var mu sync.Mutex
+	var headers []string
+	var times []time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Get("Last-Event-ID"))
+		times = append(times, time.Now())
+		n := len(headers)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return
+		}
+
+		// Each connection sends one event, then drops
+		fmt.Fprint(w, "retry: 150\n\n")
+		fmt.Fprintf(w, "id: evt-%d\ndata: event %d\n\n", n, n)
+		flusher.Flush()
+	}))
+	defer server.Close()
+
+	sub := NewSubscriber(server.URL)
+	sub.ResumeFrom("evt-0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	events := sub.Start(ctx)
+	for i := 1; i <= 3; i++ {
+		select {
+		case event := <-events:
+			if want := fmt.Sprintf("evt-%d", i); event.ID != want {
+				t.Errorf("event %d: expected ID %q, got %q", i, want, event.ID)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	sub.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, want := range []string{"evt-0", "evt-1", "evt-2"} {
+		if headers[i] != want {
+			t.Errorf("connection %d: expected Last-Event-ID %q, got %q", i+1, want, headers[i])
+		}
+	}
+	if gap := times[2].Sub(times[1]); gap < 150*time.Millisecond || gap > time.Second {
+		t.Errorf("expected reconnect after the 150ms retry hint, got %v", gap)
+	}
+}
+
+// TestNoLastEventIDOnFirstConnect tests that a fresh subscriber sends no
+// Last-Event-ID header.
+func TestNoLastEventIDOnFirstConnect(t *testing.T) {
+	got := make(chan []string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case got <- r.Header.Values("Last-Event-ID"):
+		default:
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sub := NewSubscriber(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub.Start(ctx)
+	defer sub.Stop()
+
+	select {
+	case values := <-got:
+		if len(values) != 0 {
+			t.Errorf("expected no Last-Event-ID header, got %v", values)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for connection")
+	}
+}
+
 // TestEmptyDataNotSent tests that events with empty data are not sent.
 func TestEmptyDataNotSent(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,228 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// TaskPayload is the data of task.* events.
+type TaskPayload struct {
+	Task client.Task `json:"task"`
+	// Epic is the task's epic, when the server includes it
+	Epic *client.Epic `json:"epic,omitempty"`
+}
+
+// EpicPayload is the data of epic.* events.
+type EpicPayload struct {
+	Epic client.Epic `json:"epic"`
+}
+
+// ProjectPayload is the data of project.* events.
+type ProjectPayload struct {
+	Project client.Project `json:"project"`
+}
+
+// EventLagged is the type of the event a subscription gets in place of the
+// events it missed because its consumer fell behind. Those events are gone,
+// so consumers should resync whatever they track from the API.
+const EventLagged = "subscription.lagged"
+
+// TypedEvent is an Event with its data decoded. At most one of Task, Epic
+// and Project is set, depending on the event type's prefix. Other event
+// types, such as "message", only carry the raw Event.
+type TypedEvent struct {
+	Event
+	Task    *TaskPayload
+	Epic    *EpicPayload
+	Project *ProjectPayload
+}
+
+// Decode decodes the data of task.*, epic.* and project.* events into
+// their payload structs.
+func Decode(event Event) (TypedEvent, error) {
+	typed := TypedEvent{Event: event}
+	var target any
+	switch {
+	case strings.HasPrefix(event.Type, "task."):
+		typed.Task = &TaskPayload{}
+		target = typed.Task
+	case strings.HasPrefix(event.Type, "epic."):
+		typed.Epic = &EpicPayload{}
+		target = typed.Epic
+	case strings.HasPrefix(event.Type, "project."):
+		typed.Project = &ProjectPayload{}
+		target = typed.Project
+	default:
+		return typed, nil
+	}
+	if err := json.Unmarshal([]byte(event.Data), target); err != nil {
+		return TypedEvent{Event: event}, fmt.Errorf("invalid %s payload: %w", event.Type, err)
+	}
+	return typed, nil
+}
+
+// EpicID returns the ID of the epic the event is about, or of the task's
+// epic for task events.
+func (e TypedEvent) EpicID() string {
+	switch {
+	case e.Task != nil:
+		return e.Task.Task.EpicID
+	case e.Epic != nil:
+		return e.Epic.Epic.ID
+	}
+	return ""
+}
+
+// ProjectID returns the ID of the project the event belongs to, or "" if
+// the payload does not say.
+func (e TypedEvent) ProjectID() string {
+	switch {
+	case e.Task != nil && e.Task.Task.ProjectID != "":
+		return e.Task.Task.ProjectID
+	case e.Task != nil && e.Task.Epic != nil:
+		return e.Task.Epic.ProjectID
+	case e.Epic != nil:
+		return e.Epic.Epic.ProjectID
+	case e.Project != nil:
+		return e.Project.Project.ID
+	}
+	return ""
+}
+
+// Lagged reports whether the event stands for events the subscription
+// missed (see EventLagged).
+func (e TypedEvent) Lagged() bool {
+	return e.Type == EventLagged
+}
+
+// AutoEpic reports whether the event carries an epic with auto enabled.
+func (e TypedEvent) AutoEpic() bool {
+	switch {
+	case e.Task != nil:
+		return e.Task.Epic != nil && e.Task.Epic.Auto
+	case e.Epic != nil:
+		return e.Epic.Epic.Auto
+	}
+	return false
+}
+
+// Filter selects the events delivered to a subscription. Empty fields
+// match everything.
+type Filter struct {
+	// Types lists event types; "task.*" matches every task event
+	Types []string
+	// ProjectIDs limits events to these projects
+	ProjectIDs []string
+	// EpicIDs limits events to these epics
+	EpicIDs []string
+}
+
+// Matches reports whether the event passes the filter. Events whose
+// project or epic is unknown do not match a project or epic filter.
+func (f Filter) Matches(e TypedEvent) bool {
+	if len(f.Types) > 0 && !slices.ContainsFunc(f.Types, func(t string) bool {
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			return strings.HasPrefix(e.Type, prefix)
+		}
+		return t == e.Type
+	}) {
+		return false
+	}
+	if len(f.ProjectIDs) > 0 && !slices.Contains(f.ProjectIDs, e.ProjectID()) {
+		return false
+	}
+	if len(f.EpicIDs) > 0 && !slices.Contains(f.EpicIDs, e.EpicID()) {
+		return false
+	}
+	return true
+}
+
+// subscription is a typed consumer registered with Subscribe.
+type subscription struct {
+	filter Filter
+	ch     chan TypedEvent
+}
+
+// Subscribe returns a channel of decoded events matching filter. Any
+// number of subscriptions share the subscriber's one connection; events
+// start flowing once Start has been called. A consumer that falls 100
+// events behind gets an EventLagged event in place of the ones it missed.
+// The channel is closed by Unsubscribe or when the subscriber shuts down.
+func (s *Subscriber) Subscribe(filter Filter) <-chan TypedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan TypedEvent, 100)
+	if s.closed {
+		close(ch)
+		return ch
+	}
+	s.subscriptions = append(s.subscriptions, &subscription{filter: filter, ch: ch})
+	return ch
+}
+
+// Unsubscribe stops delivery to a channel returned by Subscribe and
+// closes it.
+func (s *Subscriber) Unsubscribe(ch <-chan TypedEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, sub := range s.subscriptions {
+		if sub.ch == ch {
+			close(sub.ch)
+			s.subscriptions = slices.Delete(s.subscriptions, i, i+1)
+			return
+		}
+	}
+}
+
+// publish decodes the event once and delivers it to every matching
+// subscription without blocking. A subscription whose buffer is one short
+// of full gets an EventLagged event in the last slot, and further events
+// are dropped until its consumer catches up. It reports whether any
+// subscription exists.
+func (s *Subscriber) publish(event Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.subscriptions) == 0 {
+		return false
+	}
+	typed, err := Decode(event)
+	if err != nil {
+		log.Printf("SSE subscriber: %v", err)
+	}
+	for _, sub := range s.subscriptions {
+		if !sub.filter.Matches(typed) {
+			continue
+		}
+		// Only publish sends, under s.mu, so none of these block
+		switch len(sub.ch) {
+		case cap(sub.ch):
+		case cap(sub.ch) - 1:
+			log.Printf("SSE subscriber: subscription channel full, dropping events from %q on", event.Type)
+			sub.ch <- TypedEvent{Event: Event{Type: EventLagged}}
+		default:
+			sub.ch <- typed
+		}
+	}
+	return true
+}
+
+// closeSubscriptions closes every subscription channel when the subscriber
+// shuts down.
+func (s *Subscriber) closeSubscriptions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for _, sub := range s.subscriptions {
+		close(sub.ch)
+	}
+	s.subscriptions = nil
+}
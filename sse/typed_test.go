@@ -0,0 +1,207 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDecode_AutoEpic(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   Event
+		want    bool
+		wantErr bool
+	}{
+		{name: "auto true", event: Event{Type: "task.created", Data: `{"epic": {"auto": true}}`}, want: true},
+		{name: "auto false", event: Event{Type: "task.created", Data: `{"epic": {"auto": false}}`}},
+		{name: "no epic field", event: Event{Type: "task.created", Data: `{"task": {"id": "123"}}`}},
+		{name: "empty epic", event: Event{Type: "task.created", Data: `{"epic": {}}`}},
+		{name: "null epic", event: Event{Type: "task.created", Data: `{"epic": null}`}},
+		{name: "invalid JSON", event: Event{Type: "task.created", Data: `not valid json`}, wantErr: true},
+		{name: "empty data", event: Event{Type: "task.created", Data: ""}, wantErr: true},
+		{name: "epic event", event: Event{Type: "epic.updated", Data: `{"epic": {"id": "e1", "auto": true}}`}, want: true},
+		{name: "untyped event", event: Event{Type: "data-changed", Data: `{"epic": {"auto": true}}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typed, err := Decode(tt.event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := typed.AutoEpic(); got != tt.want {
+				t.Errorf("AutoEpic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode_IDs(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       Event
+		wantProject string
+		wantEpic    string
+	}{
+		{
+			name:        "task with project",
+			event:       Event{Type: "task.updated", Data: `{"task": {"id": "t1", "project_id": "p1", "epic_id": "e1"}}`},
+			wantProject: "p1",
+			wantEpic:    "e1",
+		},
+		{
+			name:        "task project from epic",
+			event:       Event{Type: "task.updated", Data: `{"task": {"id": "t1", "epic_id": "e1"}, "epic": {"id": "e1", "project_id": "p2"}}`},
+			wantProject: "p2",
+			wantEpic:    "e1",
+		},
+		{
+			name:        "epic",
+			event:       Event{Type: "epic.created", Data: `{"epic": {"id": "e2", "project_id": "p1"}}`},
+			wantProject: "p1",
+			wantEpic:    "e2",
+		},
+		{
+			name:        "project",
+			event:       Event{Type: "project.deleted", Data: `{"project": {"id": "p3"}}`},
+			wantProject: "p3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typed, err := Decode(tt.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := typed.ProjectID(); got != tt.wantProject {
+				t.Errorf("ProjectID() = %q, want %q", got, tt.wantProject)
+			}
+			if got := typed.EpicID(); got != tt.wantEpic {
+				t.Errorf("EpicID() = %q, want %q", got, tt.wantEpic)
+			}
+		})
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	event, err := Decode(Event{Type: "task.status_changed", Data: `{"task": {"id": "t1", "project_id": "p1", "epic_id": "e1"}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{name: "empty", filter: Filter{}, want: true},
+		{name: "exact type", filter: Filter{Types: []string{"task.created", "task.status_changed"}}, want: true},
+		{name: "type prefix", filter: Filter{Types: []string{"task.*"}}, want: true},
+		{name: "other type", filter: Filter{Types: []string{"epic.*"}}},
+		{name: "project", filter: Filter{ProjectIDs: []string{"p1"}}, want: true},
+		{name: "other project", filter: Filter{ProjectIDs: []string{"p2"}}},
+		{name: "epic", filter: Filter{Types: []string{"task.*"}, EpicIDs: []string{"e1"}}, want: true},
+		{name: "other epic", filter: Filter{EpicIDs: []string{"e2"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(event); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribe_SharedConnection(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: epic.updated\ndata: {\"epic\": {\"id\": \"e1\", \"project_id\": \"p1\"}}\n\n"))
+		w.Write([]byte("event: task.created\ndata: {\"task\": {\"id\": \"t1\", \"project_id\": \"p1\", \"epic_id\": \"e1\"}}\n\n"))
+		w.Write([]byte("event: task.created\ndata: {\"task\": {\"id\": \"t2\", \"project_id\": \"p2\"}}\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	sub := NewSubscriber(server.URL)
+	tasksInP1 := sub.Subscribe(Filter{Types: []string{"task.*"}, ProjectIDs: []string{"p1"}})
+	epics := sub.Subscribe(Filter{Types: []string{"epic.*"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	sub.Start(ctx)
+
+	select {
+	case event := <-tasksInP1:
+		if event.Task == nil || event.Task.Task.ID != "t1" {
+			t.Errorf("task subscription got %+v, want task t1", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for task event")
+	}
+	select {
+	case event := <-epics:
+		if event.Epic == nil || event.Epic.Epic.ID != "e1" {
+			t.Errorf("epic subscription got %+v, want epic e1", event)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for epic event")
+	}
+
+	sub.Unsubscribe(epics)
+	if _, ok := <-epics; ok {
+		t.Error("epic subscription still open after Unsubscribe")
+	}
+	select {
+	case event := <-tasksInP1:
+		t.Errorf("task subscription got unexpected %+v", event)
+	default:
+	}
+
+	sub.Stop()
+	cancel()
+	for range tasksInP1 {
+	}
+	if n := connections.Load(); n != 1 {
+		t.Errorf("connections = %d, want 1", n)
+	}
+}
+
+func TestSubscribe_Lagged(t *testing.T) {
+	sub := NewSubscriber("http://flux.invalid")
+	ch := sub.Subscribe(Filter{})
+	event := func(id string) Event {
+		return Event{Type: "task.updated", Data: `{"task": {"id": "` + id + `"}}`}
+	}
+
+	// Nobody reads while the buffer fills and overflows
+	for i := range cap(ch) + 10 {
+		sub.publish(event(fmt.Sprint(i)))
+	}
+	if len(ch) != cap(ch) {
+		t.Fatalf("buffered %d events, want %d", len(ch), cap(ch))
+	}
+	for range cap(ch) - 1 {
+		if e := <-ch; e.Lagged() {
+			t.Fatal("got the lagged event before the ones that fitted")
+		}
+	}
+	if e := <-ch; !e.Lagged() {
+		t.Fatalf("last buffered event = %+v, want the lagged event", e)
+	}
+
+	// Delivery resumes once the consumer catches up
+	sub.publish(event("next"))
+	if e := <-ch; e.Task == nil || e.Task.Task.ID != "next" {
+		t.Errorf("got %+v, want task next", e)
+	}
+}
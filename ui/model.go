@@ -41,6 +41,9 @@ type AgentPanel struct {
 	Closed    bool
 	Stopping  bool // Set when stop is requested but process hasn't exited yet
 	PID       int
+	// Upstream describes a change made to the task in Flux while the
+	// agent ran, shown as a badge. Empty when there is none.
+	Upstream string
 }
 
 // IsRunning returns whether the agent is still running
@@ -72,6 +75,16 @@ type Model struct {
 	taskCount    int
 	lastTaskTime time.Time
 	mode         ExecutionMode
+	fairness     string
+	// connState is the Flux connection state ("connecting", "live",
+	// "polling" or "down"); empty until the first report
+	connState   string
+	lastEventAt time.Time
+	// notice is a one-off report shown under the status line, such as
+	// orphaned tasks recovered at startup
+	notice string
+	// modeLock, when set, keeps the execution mode fixed and says why
+	modeLock string
 
 	// Agent panels
 	panels       []*AgentPanel
@@ -103,6 +116,15 @@ type Model struct {
 	promptPreviewOpen bool
 	claudeMdFiles     []claudeMdFile
 	promptViewport    viewport.Model
+
+	// Task lookup ("why is this task not picked up?")
+	whyRequests  chan<- string
+	whyInputMode bool
+	whyInput     textinput.Model
+	whyOpen      bool
+	whyTaskID    string
+	whyLines     []string
+	whyErr       error
 }
 
 // claudeMdFile represents a CLAUDE.md file and its content
@@ -128,6 +150,10 @@ func NewModel(criteria string, mode ExecutionMode, workDir string, modeUpdates c
 	ti.Placeholder = "Enter path..."
 	ti.CharLimit = 256
 
+	whyTi := textinput.New()
+	whyTi.Placeholder = "Task ID..."
+	whyTi.CharLimit = 128
+
 	return Model{
 		criteria:       criteria,
 		mode:           mode,
@@ -137,6 +163,7 @@ func NewModel(criteria string, mode ExecutionMode, workDir string, modeUpdates c
 		viewport:       vp,
 		promptViewport: promptVp,
 		workDirInput:   ti,
+		whyInput:       whyTi,
 		agentUpdates:   make(chan AgentUpdate, 100),
 		modeUpdates:    modeUpdates,
 		stopUpdates:    stopUpdates,
@@ -155,6 +182,17 @@ type versionCheckMsg struct {
 // ListenerConnectedMsg signals the listener is connected
 type ListenerConnectedMsg struct{}
 
+// ConnectionStateMsg reports a change in the Flux connection: "connecting",
+// "live" (SSE), "polling" or "down".
+type ConnectionStateMsg struct{ State string }
+
+// FluxEventMsg reports that an event arrived from Flux.
+type FluxEventMsg struct{ At time.Time }
+
+// NoticeMsg shows a report under the status line until replaced. An empty
+// Text clears it.
+type NoticeMsg struct{ Text string }
+
 // ListenerErrorMsg signals a listener error
 type ListenerErrorMsg struct{ Err error }
 
@@ -178,6 +216,22 @@ type AgentCompletedMsg struct {
 	Result agent.Result
 }
 
+// TaskChangedUpstreamMsg reports that a running agent's task was changed in
+// Flux by someone else. Cancelled is set when the agent is being stopped
+// because of it.
+type TaskChangedUpstreamMsg struct {
+	TaskID    string
+	Note      string
+	Cancelled bool
+}
+
+// WhyResultMsg carries the answer to a task lookup requested with ?.
+type WhyResultMsg struct {
+	TaskID string
+	Lines  []string
+	Err    error
+}
+
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
 	return tea.Batch(
@@ -234,6 +288,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.lastError = msg.Err
 		return m, nil
 
+	case ConnectionStateMsg:
+		m.connState = msg.State
+		return m, nil
+
+	case FluxEventMsg:
+		m.lastEventAt = msg.At
+		return m, nil
+
+	case NoticeMsg:
+		m.notice = msg.Text
+		m.updateLayoutDimensions()
+		return m, nil
+
 	case AddAgentMsg:
 		m.addAgentPanel(msg.TaskID, msg.TaskTitle, msg.AgentName, msg.Runner)
 		return m, nil
@@ -246,6 +313,23 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.completeAgent(msg.TaskID, msg.Result)
 		return m, nil
 
+	case TaskChangedUpstreamMsg:
+		if _, panel := m.panelForTask(msg.TaskID); panel != nil {
+			panel.Upstream = msg.Note
+			if msg.Cancelled {
+				panel.Stopping = true
+			}
+			m.updateConsoleContent()
+		}
+		return m, nil
+
+	case WhyResultMsg:
+		if msg.TaskID == m.whyTaskID {
+			m.whyLines = msg.Lines
+			m.whyErr = msg.Err
+		}
+		return m, nil
+
 	case versionCheckMsg:
 		m.updateAvailable = msg.updateAvailable
 		m.latestVersion = msg.latestVersion
@@ -288,45 +372,55 @@ func (m *Model) addAgentPanel(taskID, taskTitle, agentName string, runner *agent
 	m.updateConsoleContent()
 }
 
+// panelForTask returns the most recent panel for a task, or nil. Earlier
+// panels belong to finished runs, such as one restarted with a new prompt.
+func (m *Model) panelForTask(taskID string) (int, *AgentPanel) {
+	for i := len(m.panels) - 1; i >= 0; i-- {
+		if m.panels[i].TaskID == taskID {
+			return i, m.panels[i]
+		}
+	}
+	return -1, nil
+}
+
 func (m *Model) appendAgentOutput(taskID string, line agent.OutputLine) {
-	for i, panel := range m.panels {
-		if panel.TaskID == taskID {
-			// Parse JSON output to extract meaningful content
-			parsed := parseClaudeOutput(line.Text)
-			if parsed == "" {
-				return // Skip empty/uninteresting messages
-			}
+	i, panel := m.panelForTask(taskID)
+	if panel == nil {
+		return
+	}
 
-			parsedLine := agent.OutputLine{
-				Text:      parsed,
-				IsStderr:  line.IsStderr,
-				Timestamp: line.Timestamp,
-			}
+	// Parse JSON output to extract meaningful content
+	parsed := parseClaudeOutput(line.Text)
+	if parsed == "" {
+		return // Skip empty/uninteresting messages
+	}
 
-			panel.Output = append(panel.Output, parsedLine)
+	parsedLine := agent.OutputLine{
+		Text:      parsed,
+		IsStderr:  line.IsStderr,
+		Timestamp: line.Timestamp,
+	}
 
-			// Update viewport if this is the selected panel
-			if i == m.focusedPanel {
-				m.updateConsoleContent()
-			}
-			return
-		}
+	panel.Output = append(panel.Output, parsedLine)
+
+	// Update viewport if this is the selected panel
+	if i == m.focusedPanel {
+		m.updateConsoleContent()
 	}
 }
 
 func (m *Model) completeAgent(taskID string, result agent.Result) {
-	for _, panel := range m.panels {
-		if panel.TaskID == taskID {
-			panel.Result = &result
-			panel.EndTime = time.Now()
-			panel.Runner = nil
-			m.taskCount++
-			m.lastTaskTime = time.Now()
-			m.clampSelection()
-			m.updateConsoleContent()
-			return
-		}
+	_, panel := m.panelForTask(taskID)
+	if panel == nil {
+		return
 	}
+	panel.Result = &result
+	panel.EndTime = time.Now()
+	panel.Runner = nil
+	m.taskCount++
+	m.lastTaskTime = time.Now()
+	m.clampSelection()
+	m.updateConsoleContent()
 }
 
 func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -344,6 +438,43 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Handle task lookup result
+	if m.whyOpen {
+		if msg.String() == "esc" || msg.String() == "enter" {
+			m.whyOpen = false
+		}
+		return m, nil
+	}
+
+	// Handle task lookup input
+	if m.whyInputMode {
+		switch msg.String() {
+		case "esc":
+			m.whyInputMode = false
+			m.whyInput.Reset()
+			return m, nil
+		case "enter":
+			taskID := strings.TrimSpace(m.whyInput.Value())
+			m.whyInputMode = false
+			m.whyInput.Reset()
+			if taskID != "" && m.whyRequests != nil {
+				select {
+				case m.whyRequests <- taskID:
+					m.whyOpen = true
+					m.whyTaskID = taskID
+					m.whyLines = nil
+					m.whyErr = nil
+				default:
+				}
+			}
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.whyInput, cmd = m.whyInput.Update(msg)
+			return m, cmd
+		}
+	}
+
 	// Handle workdir text input mode
 	if m.workDirInputMode {
 		switch msg.String() {
@@ -463,6 +594,10 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "m":
+		if m.modeLock != "" {
+			m.notice = m.modeLock
+			return m, nil
+		}
 		m.mode = m.mode.Toggle()
 		if m.modeUpdates != nil {
 			select {
@@ -476,6 +611,17 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.workDirMenuOpen = true
 		return m, nil
 
+	case "?":
+		if m.whyRequests == nil {
+			return m, nil
+		}
+		m.whyInputMode = true
+		if m.focusedPanel >= 0 && m.focusedPanel < len(m.panels) {
+			m.whyInput.SetValue(m.panels[m.focusedPanel].TaskID)
+		}
+		m.whyInput.Focus()
+		return m, nil
+
 	case "p":
 		m.loadClaudeMdFiles()
 		m.promptPreviewOpen = true
@@ -651,6 +797,12 @@ func (m *Model) View() string {
 	if m.workDirInputMode {
 		return m.renderWorkDirInput()
 	}
+	if m.whyInputMode {
+		return m.renderWhyInput()
+	}
+	if m.whyOpen {
+		return m.renderWhyResult()
+	}
 
 	var b strings.Builder
 
@@ -715,6 +867,54 @@ func (m *Model) renderWorkDirInput() string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
 }
 
+func (m *Model) renderWhyInput() string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(GlowGreen).Render("Why not picked up?")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	b.WriteString("Task ID: ")
+	b.WriteString(m.whyInput.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(HelpStyle.Render("Press enter to look up or esc to cancel"))
+
+	content := PanelStyle.Width(60).Render(b.String())
+
+	// Center in screen
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m *Model) renderWhyResult() string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(GlowGreen).Render("Why: " + m.whyTaskID)
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	switch {
+	case m.whyErr != nil:
+		b.WriteString(StatusError.Render(m.whyErr.Error()))
+	case m.whyLines == nil:
+		b.WriteString(m.spinner.View() + " Looking up task...")
+	default:
+		b.WriteString(strings.Join(m.whyLines, "\n"))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(HelpStyle.Render("Press esc to close"))
+
+	width := 80
+	if m.width > 0 && m.width-4 < width {
+		width = m.width - 4
+	}
+	content := PanelStyle.Width(width).Render(b.String())
+
+	// Center in screen
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
 func (m *Model) renderPromptPreview() string {
 	var b strings.Builder
 
@@ -749,11 +949,16 @@ func (m *Model) renderListenerPanel() string {
 	var status string
 	if m.lastError != nil {
 		status = StatusError.Render(fmt.Sprintf("Error: %v", m.lastError))
+	} else if m.connState != "" {
+		status = m.renderConnectionStatus()
 	} else if m.connected {
 		status = StatusConnected.Render("Connected and watching for tasks...") + " " + m.spinner.View()
 	} else {
 		status = m.spinner.View() + " " + StatusWaiting.Render("Connecting...")
 	}
+	if m.notice != "" {
+		status += "\n" + NoticeStyle.Render(m.notice)
+	}
 
 	labelWidth := 16
 	labelStyle := lipgloss.NewStyle().Foreground(Gray).Width(labelWidth)
@@ -765,12 +970,17 @@ func (m *Model) renderListenerPanel() string {
 		displayWorkDir = "..." + displayWorkDir[len(displayWorkDir)-37:]
 	}
 
+	modeText := m.mode.String()
+	if m.fairness != "" {
+		modeText += " · fairness: " + m.fairness
+	}
+
 	content := fmt.Sprintf("%s\n%s %s\n%s %s\n%s %s\n%s %d\n\n%s",
 		status,
 		labelStyle.Render("Filter:"),
 		m.criteria,
 		labelStyle.Render("Mode:"),
-		m.mode.String(),
+		modeText,
 		labelStyle.Render("WorkDir:"),
 		displayWorkDir,
 		labelStyle.Render("Tasks completed:"),
@@ -781,6 +991,37 @@ func (m *Model) renderListenerPanel() string {
 	return PanelStyle.Width(m.width - 4).Render(content)
 }
 
+// renderConnectionStatus describes the Flux connection: the transport in
+// use and the time since the last event.
+func (m *Model) renderConnectionStatus() string {
+	lastEvent := "no events yet"
+	if !m.lastEventAt.IsZero() {
+		lastEvent = "last event " + formatAgo(time.Since(m.lastEventAt))
+	}
+	hint := lipgloss.NewStyle().Foreground(Gray).Render(" · " + lastEvent)
+
+	switch m.connState {
+	case "live":
+		return StatusConnected.Render("Live via SSE, watching for tasks...") + hint + " " + m.spinner.View()
+	case "polling":
+		return StatusWaiting.Render("SSE unavailable, polling for changes...") + hint + " " + m.spinner.View()
+	case "down":
+		return StatusError.Render("Flux unreachable, retrying...") + hint
+	}
+	return m.spinner.View() + " " + StatusWaiting.Render("Connecting...") + hint
+}
+
+// formatAgo renders a duration coarsely, e.g. "5s ago" or "3m ago".
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh ago", int(d.Hours()))
+}
+
 func (m *Model) renderHeader() string {
 	var b strings.Builder
 
@@ -807,6 +1048,7 @@ func (m *Model) renderHelp() string {
 		HelpKeyStyle.Render("m") + HelpStyle.Render(" mode  ") +
 		HelpKeyStyle.Render("w") + HelpStyle.Render(" workdir  ") +
 		HelpKeyStyle.Render("p") + HelpStyle.Render(" prompt  ") +
+		HelpKeyStyle.Render("?") + HelpStyle.Render(" why  ") +
 		HelpKeyStyle.Render("s") + HelpStyle.Render(" stop  ") +
 		HelpKeyStyle.Render("x") + HelpStyle.Render(" remove  ") +
 		HelpKeyStyle.Render("q") + HelpStyle.Render(" quit")
@@ -980,19 +1222,34 @@ func renderMetaLine(panel *AgentPanel, width int) string {
 		nameMax = 8
 	}
 
+	badgeText := ""
+	if panel.Upstream != "" {
+		badgeText = "[" + panel.Upstream + "]"
+		nameMax -= lipgloss.Width(badgeText) + 2
+		if nameMax < 8 {
+			nameMax = 8
+		}
+	}
+
 	nameText := truncate(panel.TaskTitle, nameMax)
 
 	baseRaw := fmt.Sprintf("%s  %s  %s", pidText, taskIDText, nameText)
+	badge := ""
+	if badgeText != "" {
+		baseRaw += "  " + badgeText
+		badge = "  " + UpstreamBadgeStyle.Render(badgeText)
+	}
 	padding := width - lipgloss.Width(baseRaw) - timeWidth
 	if padding < 1 {
 		padding = 1
 	}
 
 	return fmt.Sprintf(
-		"%s  %s  %s%s%s",
+		"%s  %s  %s%s%s%s",
 		PidStyle.Render(pidText),
 		TaskIDStyle.Render(taskIDText),
 		TaskNameStyle.Render(nameText),
+		badge,
 		strings.Repeat(" ", padding),
 		TimeStyle.Render(elapsed),
 	)
@@ -1113,6 +1370,24 @@ func (m *Model) SetConnected(connected bool) {
 	m.connected = connected
 }
 
+// SetFairness sets the project fairness description shown next to the mode.
+// An empty string hides it.
+func (m *Model) SetFairness(fairness string) {
+	m.fairness = fairness
+}
+
+// SetModeLock stops m from switching the execution mode, showing reason
+// instead. An empty reason allows switching again.
+func (m *Model) SetModeLock(reason string) {
+	m.modeLock = reason
+}
+
+// SetWhyRequests enables the ? task lookup. Task IDs typed by the user are
+// sent on ch; answers come back as WhyResultMsg.
+func (m *Model) SetWhyRequests(ch chan<- string) {
+	m.whyRequests = ch
+}
+
 // SetError sets the last error
 func (m *Model) SetError(err error) {
 	m.lastError = err
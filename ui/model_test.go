@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -126,6 +127,52 @@ func TestModel_Update_ListenerErrorMsg(t *testing.T) {
 	}
 }
 
+func TestModel_Update_ConnectionStateMsg(t *testing.T) {
+	model := NewModel("test", ExecutionModeAsync, ".", nil, nil, nil)
+	model.width = 120
+	model.height = 50
+
+	tests := []struct {
+		state string
+		want  string
+	}{
+		{"connecting", "Connecting..."},
+		{"live", "Live via SSE"},
+		{"polling", "polling for changes"},
+		{"down", "Flux unreachable"},
+	}
+	for _, tt := range tests {
+		model.Update(ConnectionStateMsg{State: tt.state})
+		if got := model.renderListenerPanel(); !strings.Contains(got, tt.want) {
+			t.Errorf("state %q: header missing %q:\n%s", tt.state, tt.want, got)
+		}
+	}
+
+	if got := model.renderListenerPanel(); !strings.Contains(got, "no events yet") {
+		t.Errorf("header missing %q before any event", "no events yet")
+	}
+	model.Update(FluxEventMsg{At: time.Now().Add(-90 * time.Second)})
+	if got := model.renderListenerPanel(); !strings.Contains(got, "last event 1m ago") {
+		t.Errorf("header missing time since last event:\n%s", got)
+	}
+}
+
+func TestModel_Update_NoticeMsg(t *testing.T) {
+	model := NewModel("test", ExecutionModeAsync, ".", nil, nil, nil)
+	model.width = 120
+	model.height = 50
+
+	model.Update(NoticeMsg{Text: "Recovered 1 orphaned task"})
+	if got := model.renderListenerPanel(); !strings.Contains(got, "Recovered 1 orphaned task") {
+		t.Errorf("header missing notice:\n%s", got)
+	}
+
+	model.Update(NoticeMsg{})
+	if got := model.renderListenerPanel(); strings.Contains(got, "Recovered") {
+		t.Errorf("notice should be cleared:\n%s", got)
+	}
+}
+
 func TestModel_Update_AddAgentMsg(t *testing.T) {
 	model := NewModel("test", ExecutionModeAsync, ".", nil, nil, nil)
 
@@ -235,6 +282,30 @@ func TestModel_Update_AgentCompletedMsg(t *testing.T) {
 	}
 }
 
+func TestModel_Update_TaskChangedUpstreamMsg(t *testing.T) {
+	model := NewModel("test", ExecutionModeAsync, ".", nil, nil, nil)
+	model.width = 100
+	model.height = 50
+
+	// A restarted task gets a second panel; messages go to the latest one
+	model.Update(AddAgentMsg{TaskID: "task-1", TaskTitle: "Task 1", AgentName: "Claude"})
+	model.Update(AgentCompletedMsg{TaskID: "task-1", Result: agent.Result{ExitCode: 1}})
+	model.Update(AddAgentMsg{TaskID: "task-1", TaskTitle: "Task 1", AgentName: "Claude"})
+
+	newModel, _ := model.Update(TaskChangedUpstreamMsg{TaskID: "task-1", Note: "moved to backlog upstream", Cancelled: true})
+	m := newModel.(*Model)
+
+	if m.panels[0].Upstream != "" {
+		t.Errorf("finished panel got badge %q", m.panels[0].Upstream)
+	}
+	if m.panels[1].Upstream != "moved to backlog upstream" || !m.panels[1].Stopping {
+		t.Errorf("latest panel = upstream %q, stopping %v", m.panels[1].Upstream, m.panels[1].Stopping)
+	}
+	if line := renderMetaLine(m.panels[1], 100); !strings.Contains(line, "[moved to backlog upstream]") {
+		t.Errorf("meta line %q missing upstream badge", line)
+	}
+}
+
 func TestModel_HandleKeyPress_Quit(t *testing.T) {
 	model := NewModel("test", ExecutionModeAsync, ".", nil, nil, nil)
 
@@ -318,6 +389,86 @@ func TestModel_SetConnected(t *testing.T) {
 	}
 }
 
+func TestModel_SetFairness(t *testing.T) {
+	model := NewModel("test", ExecutionModeAsync, ".", nil, nil, nil)
+	model.width = 120
+
+	if strings.Contains(model.renderListenerPanel(), "fairness") {
+		t.Error("expected fairness to be hidden by default")
+	}
+
+	model.SetFairness("weighted (a=3, b=1)")
+	if !strings.Contains(model.renderListenerPanel(), "fairness: weighted (a=3, b=1)") {
+		t.Error("expected listener panel to show fairness and weights")
+	}
+}
+
+func TestModel_SetModeLock(t *testing.T) {
+	modes := make(chan ExecutionMode, 1)
+	model := NewModel("test", ExecutionModeSync, ".", modes, nil, nil)
+	model.SetModeLock("git.commit needs sync mode")
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if model.mode != ExecutionModeSync || len(modes) != 0 {
+		t.Errorf("expected the mode to stay sync, got %s", model.mode.String())
+	}
+	if model.notice != "git.commit needs sync mode" {
+		t.Errorf("expected the lock reason as a notice, got %q", model.notice)
+	}
+
+	model.SetModeLock("")
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if model.mode != ExecutionModeAsync || len(modes) != 1 {
+		t.Errorf("expected the mode to switch once unlocked, got %s", model.mode.String())
+	}
+}
+
+func TestModel_WhyLookup(t *testing.T) {
+	model := NewModel("test", ExecutionModeAsync, ".", nil, nil, nil)
+	model.width, model.height = 120, 40
+
+	// Disabled until a request channel is set
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if model.whyInputMode {
+		t.Fatal("expected lookup to be disabled without a request channel")
+	}
+
+	requests := make(chan string, 1)
+	model.SetWhyRequests(requests)
+	model.AddAgent("task-7", "Task 7", "Fake", nil)
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	if !model.whyInputMode || model.whyInput.Value() != "task-7" {
+		t.Fatalf("expected input prefilled with focused task, got %q", model.whyInput.Value())
+	}
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+	select {
+	case id := <-requests:
+		if id != "task-7" {
+			t.Errorf("expected request for task-7, got %q", id)
+		}
+	default:
+		t.Fatal("expected a lookup request")
+	}
+	if !strings.Contains(model.View(), "Looking up task") {
+		t.Error("expected pending lookup to be shown")
+	}
+
+	// Stale answers are ignored
+	model.Update(WhyResultMsg{TaskID: "task-1", Lines: []string{"old"}})
+	model.Update(WhyResultMsg{TaskID: "task-7", Lines: []string{"Not picked up because:", "  - epic e has auto=false"}})
+	view := model.View()
+	if strings.Contains(view, "old") || !strings.Contains(view, "epic e has auto=false") {
+		t.Errorf("expected lookup answer in view:\n%s", view)
+	}
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.whyOpen {
+		t.Error("expected esc to close the lookup")
+	}
+}
+
 func TestModel_SetError(t *testing.T) {
 	model := NewModel("test", ExecutionModeAsync, ".", nil, nil, nil)
 
@@ -87,6 +87,15 @@ var (
 			Foreground(Red).
 			Bold(true)
 
+	// UpstreamBadgeStyle marks panels whose task was changed in Flux
+	UpstreamBadgeStyle = lipgloss.NewStyle().
+				Foreground(Orange).
+				Bold(true)
+
+	// NoticeStyle renders one-off reports under the status line
+	NoticeStyle = lipgloss.NewStyle().
+			Foreground(Orange)
+
 	ProgressTrackStyle = lipgloss.NewStyle().
 				Foreground(DarkGray)
 
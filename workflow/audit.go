@@ -0,0 +1,153 @@
+package workflow
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Reason says why a task changed status. Its value is what the REASON
+// column of `momentum history` shows.
+type Reason string
+
+const (
+	// ReasonClaimed is written by Claim when this instance wins a queued
+	// task and moves it to the picked-up status. Every run starts with one.
+	ReasonClaimed Reason = "claimed"
+	// ReasonStarted is written by StartWorking, which moves tasks to the
+	// picked-up status without a claim.
+	ReasonStarted Reason = "started"
+	// ReasonSucceeded is written by MarkComplete when an agent exits
+	// cleanly, including SubmitForReview; the task went to the succeeded
+	// status, which is the review status in review mode.
+	ReasonSucceeded Reason = "agent success"
+	// ReasonFailed is written by MarkFailed when an agent exits with an
+	// error and a failed status is configured. Without one nothing is
+	// recorded, as the task stays picked up.
+	ReasonFailed Reason = "agent failure"
+	// ReasonStopped is written by MarkStopped when a user stops the agent
+	// from the TUI.
+	ReasonStopped Reason = "user stop"
+	// ReasonTimedOut is written by MarkTimedOut when an agent runs past
+	// the configured timeout.
+	ReasonTimedOut Reason = "timeout"
+	// ReasonBlocked is written by MarkBlocked when an agent reported
+	// blockers: the task went back to the queued status to wait for the
+	// tasks created for them.
+	ReasonBlocked Reason = "agent blocked"
+	// ReasonReset is written by ResetTask and ResetToPlanning, which send
+	// a task back to the queued or planning status, e.g. when its agent
+	// could not start.
+	ReasonReset Reason = "reset"
+	// ReasonReconcile is written by RecoverOrphan when a task held by a
+	// momentum process that is no longer running is put back at startup.
+	// The ACTOR and INSTANCE columns name the process doing the recovery.
+	ReasonReconcile Reason = "reconcile"
+)
+
+// Transition is one status change recorded in the audit log.
+type Transition struct {
+	Time   time.Time `json:"time"`
+	TaskID string    `json:"task_id"`
+	// From is empty when the previous status is unknown
+	From   string `json:"from,omitempty"`
+	To     string `json:"to"`
+	Reason Reason `json:"reason"`
+	// Actor is the user momentum runs as
+	Actor string `json:"actor,omitempty"`
+	// Instance is the momentum process, as sent in agent_name
+	Instance string `json:"instance,omitempty"`
+}
+
+// AuditLog is an append-only JSON lines file of status transitions. Each
+// record is written with a single append, so several momentum processes
+// can share one log.
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewAuditLog returns an audit log writing to path. The file and its
+// directory are created on the first append.
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// DefaultAuditLogPath returns where the audit log is kept when no path is
+// configured: momentum/audit.jsonl under the user cache directory.
+func DefaultAuditLogPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "momentum", "audit.jsonl"), nil
+}
+
+// Path returns the file the log writes to.
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Append adds a transition to the log.
+func (l *AuditLog) Append(t Transition) error {
+	line, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	_, err = f.Write(line)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// ReadHistory returns the transitions in the audit log at path, oldest
+// first, limited to one task unless taskID is empty. A missing log has no
+// history. Lines that cannot be parsed, such as one cut short by a crash,
+// are skipped.
+func ReadHistory(path, taskID string) ([]Transition, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var history []Transition
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var t Transition
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			continue
+		}
+		if taskID == "" || t.TaskID == taskID {
+			history = append(history, t)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return history, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return history, nil
+}
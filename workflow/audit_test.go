@@ -0,0 +1,135 @@
+package workflow
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLog_AppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	log := NewAuditLog(path)
+
+	for _, tr := range []Transition{
+		{TaskID: "task-1", From: "todo", To: "in_progress", Reason: ReasonClaimed},
+		{TaskID: "task-2", From: "todo", To: "in_progress", Reason: ReasonClaimed},
+		{TaskID: "task-1", From: "in_progress", To: "done", Reason: ReasonSucceeded},
+	} {
+		if err := log.Append(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A line cut short by a crash is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"task_id":"task-1","to":`)
+	f.Close()
+
+	history, err := ReadHistory(path, "task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Reason != ReasonClaimed || history[1].To != "done" {
+		t.Errorf("history = %+v, want claimed then done", history)
+	}
+
+	all, err := ReadHistory(path, "")
+	if err != nil || len(all) != 3 {
+		t.Errorf("ReadHistory(all) = %d transitions, %v; want 3", len(all), err)
+	}
+
+	missing, err := ReadHistory(filepath.Join(t.TempDir(), "none.jsonl"), "task-1")
+	if err != nil || len(missing) != 0 {
+		t.Errorf("missing log: history = %v, err = %v", missing, err)
+	}
+}
+
+func TestWorkflow_RecordsTransitions(t *testing.T) {
+	status := "in_progress"
+	server, c := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			status = body["status"]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "status": status})
+	})
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	wf := NewWorkflow(c)
+	wf.SetOutput(nil)
+	wf.SetAgentName("claude@host:42")
+	wf.SetAuditLog(NewAuditLog(path))
+
+	if err := wf.MarkTimedOut([]string{"task-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wf.RecoverOrphan("task-1", "todo"); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := ReadHistory(path, "task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history = %+v, want two transitions", history)
+	}
+	first, second := history[0], history[1]
+	if first.From != "in_progress" || first.To != "planning" || first.Reason != ReasonTimedOut {
+		t.Errorf("first transition = %+v", first)
+	}
+	if second.From != "planning" || second.To != "todo" || second.Reason != ReasonReconcile {
+		t.Errorf("second transition = %+v", second)
+	}
+	if first.Instance != "claude@host:42" || first.Time.IsZero() {
+		t.Errorf("transition missing instance or time: %+v", first)
+	}
+}
+
+func TestWorkflow_KnownStatusSkipsLookup(t *testing.T) {
+	var gets int
+	server, c := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "status": "done"})
+	})
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	wf := NewWorkflow(c)
+	wf.SetOutput(nil)
+	wf.SetAuditLog(NewAuditLog(path))
+
+	wf.Known("task-1", "in_progress")
+	if err := wf.MarkComplete([]string{"task-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if gets != 0 {
+		t.Errorf("looked the task up %d times, want none", gets)
+	}
+
+	// The known status is used once; later changes look the task up again
+	if err := wf.ResetTask([]string{"task-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if gets != 1 {
+		t.Errorf("looked the task up %d times, want once", gets)
+	}
+
+	history, err := ReadHistory(path, "task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].From != "in_progress" || history[1].From != "done" {
+		t.Errorf("history = %+v, want moves from in_progress then done", history)
+	}
+}
@@ -0,0 +1,104 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// ErrClaimLost is returned by Claim when another instance took the task
+// first.
+var ErrClaimLost = errors.New("claim lost")
+
+// defaultClaimSettle is how long Claim waits before reading a task back to
+// check that its claim stuck.
+const defaultClaimSettle = 250 * time.Millisecond
+
+// SetAgentName sets the name recorded as agent_name on every status change
+// (default "claude"). Claim uses it as the claim token, so instances
+// sharing a board need distinct names.
+func (w *Workflow) SetAgentName(name string) {
+	w.agentName = name
+}
+
+// AgentName returns the name recorded on status changes.
+func (w *Workflow) AgentName() string {
+	return w.agentName
+}
+
+// Claim moves a queued task to the picked-up status on behalf of this
+// instance only, so several momentum processes can share a board. The move
+// is sent with the queued status as expected_status and the task is then read back to
+// check that it carries this instance's agent name. If another instance got
+// there first, Claim returns an error wrapping ErrClaimLost and leaves the
+// task to them.
+//
+// expected_status is not part of the Flux API; only servers that enforce it,
+// such as fluxtest, make the move a true compare-and-set. Elsewhere the last
+// writer wins and the read-back after the settle delay is what catches a
+// concurrent claim. That leaves a window: an instance that read the task as
+// queued but whose move lands after the other's read-back takes the task
+// without the first noticing. Claim blocks for the settle delay, so callers
+// should not run it on a loop that must stay responsive.
+func (w *Workflow) Claim(taskID string) error {
+	w.printf("Claiming task %s...\n", taskID)
+
+	task, err := w.client.GetTask(taskID)
+	if err != nil {
+		return fmt.Errorf("failed to claim task %s: %w", taskID, err)
+	}
+	if task.Status != w.statuses.Queued {
+		return w.lostClaim(task)
+	}
+	from, err := w.checkTransition(taskID, task.Status, w.statuses.PickedUp)
+	if err != nil {
+		w.printf("  Refusing to claim task %s: %v\n", taskID, err)
+		return fmt.Errorf("failed to claim task %s: %w", taskID, err)
+	}
+
+	_, err = w.client.UpdateTask(taskID, client.TaskUpdate{
+		Status:         client.StringPtr(w.statuses.PickedUp),
+		AgentName:      client.StringPtr(w.agentName),
+		ExpectedStatus: client.StringPtr(from),
+	})
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		if task, err := w.client.GetTask(taskID); err == nil {
+			return w.lostClaim(task)
+		}
+		return fmt.Errorf("task %s: %w", taskID, ErrClaimLost)
+	}
+	if err != nil {
+		w.printf("  Failed to claim task %s: %v\n", taskID, err)
+		return fmt.Errorf("failed to claim task %s: %w", taskID, err)
+	}
+
+	// Servers without expected_status let the last writer win, so give a
+	// concurrent claim time to land and check whose name stuck
+	time.Sleep(w.claimSettle)
+	task, err = w.client.GetTask(taskID)
+	if err != nil {
+		return fmt.Errorf("failed to confirm claim on task %s: %w", taskID, err)
+	}
+	if task.Status != w.statuses.PickedUp || (task.AgentName != "" && task.AgentName != w.agentName) {
+		return w.lostClaim(task)
+	}
+
+	w.printf("  Task %s (%s) -> %s\n", taskID, task.Title, w.statuses.PickedUp)
+	w.record(taskID, from, w.statuses.PickedUp, ReasonClaimed)
+	w.Known(taskID, w.statuses.PickedUp)
+	return nil
+}
+
+// lostClaim reports that task belongs to someone else now.
+func (w *Workflow) lostClaim(task *client.Task) error {
+	owner := task.AgentName
+	if owner == "" {
+		owner = "another instance"
+	}
+	w.printf("  Lost claim on task %s to %s (now %s)\n", task.ID, owner, task.Status)
+	return fmt.Errorf("task %s is %s, held by %s: %w", task.ID, task.Status, owner, ErrClaimLost)
+}
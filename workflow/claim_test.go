@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/fluxtest"
+)
+
+func TestWorkflow_ClaimIsExclusive(t *testing.T) {
+	flux := fluxtest.New()
+	server := httptest.NewServer(flux)
+	defer server.Close()
+	project := flux.AddProject("Proj", "")
+	task := flux.AddTask(client.Task{Title: "Task", ProjectID: project.ID})
+
+	const instances = 5
+	var wg sync.WaitGroup
+	errs := make([]error, instances)
+	for i := range instances {
+		wf := NewWorkflow(client.NewClient(server.URL))
+		wf.SetOutput(nil)
+		wf.SetAgentName(string(rune('a' + i)))
+		wf.claimSettle = 0
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = wf.Claim(task.ID)
+		}()
+	}
+	wg.Wait()
+
+	var winners []string
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			winners = append(winners, string(rune('a'+i)))
+		case !errors.Is(err, ErrClaimLost):
+			t.Errorf("instance %d: unexpected error %v", i, err)
+		}
+	}
+	if len(winners) != 1 {
+		t.Fatalf("winners = %v, want exactly one", winners)
+	}
+	got, _ := flux.Task(task.ID)
+	if got.Status != "in_progress" || got.AgentName != winners[0] {
+		t.Errorf("task = %s by %q, want in_progress by %q", got.Status, got.AgentName, winners[0])
+	}
+}
+
+func TestWorkflow_ClaimDetectsLastWriterWins(t *testing.T) {
+	// A server without expected_status support: our update is accepted but
+	// another instance's lands right after it
+	var mu sync.Mutex
+	status, agentName := "todo", ""
+	var patches int
+	server, c := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPatch {
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			patches++
+			status, agentName = body["status"], "other-instance"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "status": status, "agent_name": agentName})
+	})
+	defer server.Close()
+
+	wf := NewWorkflow(c)
+	wf.SetOutput(nil)
+	wf.SetAgentName("me")
+	wf.claimSettle = 0
+
+	err := wf.Claim("task-1")
+	if !errors.Is(err, ErrClaimLost) {
+		t.Fatalf("Claim() error = %v, want ErrClaimLost", err)
+	}
+
+	// Already taken: no update is attempted
+	if err := wf.Claim("task-1"); !errors.Is(err, ErrClaimLost) {
+		t.Errorf("second Claim() error = %v, want ErrClaimLost", err)
+	}
+	if patches != 1 {
+		t.Errorf("patches = %d, want 1", patches)
+	}
+}
+
+func TestWorkflow_ClaimChecksTransitions(t *testing.T) {
+	flux := fluxtest.New()
+	server := httptest.NewServer(flux)
+	defer server.Close()
+	project := flux.AddProject("Proj", "")
+	task := flux.AddTask(client.Task{Title: "Task", ProjectID: project.ID})
+
+	wf := NewWorkflow(client.NewClient(server.URL))
+	wf.SetOutput(nil)
+	wf.claimSettle = 0
+	wf.SetStatuses(Statuses{PickedUp: "doing"})
+	wf.SetTransitions(Transitions{"todo": {"in_progress"}})
+
+	if err := wf.Claim(task.ID); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("Claim() error = %v, want ErrIllegalTransition", err)
+	}
+	if got, _ := flux.Task(task.ID); got.Status != "todo" {
+		t.Errorf("refused claim moved task to %s", got.Status)
+	}
+}
@@ -0,0 +1,60 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// CompleteEpic moves an auto epic to the done status once every one of its
+// tasks is in it and, with disableAuto, turns its auto flag off so
+// momentum stops watching it. It returns the completed epic, or nil when
+// the epic has no tasks or still has unfinished ones, is not auto, or is
+// already complete.
+func (w *Workflow) CompleteEpic(projectID, epicID string, disableAuto bool) (*client.Epic, error) {
+	// Not the succeeded status, which in review mode is where tasks wait
+	// for approval
+	done := w.statuses.Done
+
+	// Most tasks finish with others still open, which the epic's own
+	// tasks show without loading the rest of the project
+	tasks, err := w.client.ListTasks(projectID, client.TaskFilters{EpicID: &epicID})
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	for _, task := range tasks {
+		if task.Status != done {
+			return nil, nil
+		}
+	}
+
+	epics, err := w.client.ListEpics(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var epic *client.Epic
+	for i := range epics {
+		if epics[i].ID == epicID {
+			epic = &epics[i]
+		}
+	}
+	if epic == nil || !epic.Auto || epic.Status == done {
+		return nil, nil
+	}
+
+	w.printf("Completing epic %s (%s)...\n", epic.ID, epic.Title)
+	updates := client.EpicUpdate{Status: client.StringPtr(done)}
+	if disableAuto {
+		updates.Auto = client.BoolPtr(false)
+	}
+	updated, err := w.client.UpdateEpic(epic.ID, updates)
+	if err != nil {
+		w.printf("  Failed to complete epic %s: %v\n", epic.ID, err)
+		return nil, fmt.Errorf("failed to complete epic %s: %w", epic.ID, err)
+	}
+	w.printf("  Epic %s (%s) -> %s\n", updated.ID, updated.Title, done)
+	return updated, nil
+}
@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/fluxtest"
+)
+
+func TestWorkflow_CompleteEpic(t *testing.T) {
+	tests := []struct {
+		name        string
+		auto        bool
+		statuses    []string
+		done        string
+		disableAuto bool
+		wantDone    bool
+	}{
+		{name: "all done", auto: true, statuses: []string{"done", "done"}, wantDone: true},
+		{name: "all done, auto turned off", auto: true, statuses: []string{"done"}, disableAuto: true, wantDone: true},
+		{name: "custom done status", auto: true, statuses: []string{"shipped", "shipped"}, done: "shipped", wantDone: true},
+		{name: "done is not the done status", auto: true, statuses: []string{"done"}, done: "shipped"},
+		{name: "waiting for review", auto: true, statuses: []string{"done", "review"}},
+		{name: "unfinished task", auto: true, statuses: []string{"done", "in_progress"}},
+		{name: "no tasks", auto: true},
+		{name: "not auto", statuses: []string{"done"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flux := fluxtest.New()
+			server := httptest.NewServer(flux)
+			defer server.Close()
+			project := flux.AddProject("Proj", "")
+			epic := flux.AddEpic(project.ID, "Epic", tt.auto)
+			for _, status := range tt.statuses {
+				flux.AddTask(client.Task{Title: "Task", ProjectID: project.ID, EpicID: epic.ID, Status: status})
+			}
+
+			wf := NewWorkflow(client.NewClient(server.URL))
+			wf.SetOutput(nil)
+			wf.SetStatuses(Statuses{Done: tt.done, Succeeded: "review"})
+			done := wf.Statuses().Done
+
+			completed, err := wf.CompleteEpic(project.ID, epic.ID, tt.disableAuto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (completed != nil) != tt.wantDone {
+				t.Fatalf("CompleteEpic() = %+v, want completed %v", completed, tt.wantDone)
+			}
+			got, _ := flux.Epic(epic.ID)
+			if (got.Status == done) != tt.wantDone {
+				t.Errorf("epic status = %q", got.Status)
+			}
+			if wantAuto := tt.auto && !tt.disableAuto; got.Auto != wantAuto {
+				t.Errorf("epic auto = %v, want %v", got.Auto, wantAuto)
+			}
+
+			// Completing it again is a no-op
+			if again, err := wf.CompleteEpic(project.ID, epic.ID, tt.disableAuto); again != nil || err != nil {
+				t.Errorf("second CompleteEpic() = %+v, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestWorkflow_CompleteEpicChecksTasksFirst(t *testing.T) {
+	flux := fluxtest.New()
+	project := flux.AddProject("Proj", "")
+	epic := flux.AddEpic(project.ID, "Epic", true)
+	flux.AddTask(client.Task{Title: "Task", ProjectID: project.ID, EpicID: epic.ID, Status: "done"})
+	flux.AddTask(client.Task{Title: "Task", ProjectID: project.ID, EpicID: epic.ID, Status: "todo"})
+
+	var epicLists int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/projects/"+project.ID+"/epics" {
+			epicLists++
+		}
+		flux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	wf := NewWorkflow(client.NewClient(server.URL))
+	wf.SetOutput(nil)
+
+	if completed, err := wf.CompleteEpic(project.ID, epic.ID, false); completed != nil || err != nil {
+		t.Fatalf("CompleteEpic() = %+v, %v; want nothing completed", completed, err)
+	}
+	if epicLists != 0 {
+		t.Errorf("listed the project's epics %d times with a task still open, want none", epicLists)
+	}
+}
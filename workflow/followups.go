@@ -0,0 +1,141 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// NewTask is a task an agent asks momentum to create.
+type NewTask struct {
+	Title string `json:"title"`
+	Notes string `json:"notes"`
+}
+
+// Discovered is work an agent found while on a task and reported in its
+// final message. Blockers must be done before the task can be finished;
+// follow-ups come after it.
+type Discovered struct {
+	Blockers  []NewTask `json:"blockers"`
+	FollowUps []NewTask `json:"follow_ups"`
+}
+
+// Empty reports whether nothing was discovered.
+func (d Discovered) Empty() bool {
+	return len(d.Blockers) == 0 && len(d.FollowUps) == 0
+}
+
+// ParseDiscovered collects the blockers and follow-ups from the fenced
+// ```json blocks in an agent's message, e.g.
+//
+//	```json
+//	{"blockers": [{"title": "Add a staging database", "notes": "..."}],
+//	 "follow_ups": [{"title": "Remove the old endpoint"}]}
+//	```
+//
+// Blocks that are not valid JSON objects, and entries without a title, are
+// ignored.
+func ParseDiscovered(message string) Discovered {
+	var found Discovered
+	rest := message
+	for {
+		_, after, ok := strings.Cut(rest, "```")
+		if !ok {
+			break
+		}
+		lang, after, _ := strings.Cut(after, "\n")
+		body, after, ok := strings.Cut(after, "```")
+		if !ok {
+			break
+		}
+		rest = after
+
+		switch strings.TrimSpace(lang) {
+		case "json", "momentum":
+		default:
+			continue
+		}
+		var block Discovered
+		if err := json.Unmarshal([]byte(body), &block); err != nil {
+			continue
+		}
+		found.Blockers = append(found.Blockers, withTitles(block.Blockers)...)
+		found.FollowUps = append(found.FollowUps, withTitles(block.FollowUps)...)
+	}
+	return found
+}
+
+func withTitles(tasks []NewTask) []NewTask {
+	return slices.DeleteFunc(tasks, func(t NewTask) bool {
+		return strings.TrimSpace(t.Title) == ""
+	})
+}
+
+// CreateDiscovered creates the work an agent discovered on task as new
+// tasks in the same project and epic. Blockers become dependencies of
+// task, and follow-ups depend on it. It returns every task it created,
+// including those whose dependencies could not be set when it also returns
+// an error, and whether task now waits on any blockers.
+func (w *Workflow) CreateDiscovered(task *client.Task, found Discovered) (created []client.Task, blocked bool, err error) {
+	var errs []error
+
+	create := func(t NewTask, dependsOn []string) *client.Task {
+		w.printf("Creating task %q discovered on task %s...\n", t.Title, task.ID)
+		notes := strings.TrimSpace(t.Notes + "\n\nDiscovered by an agent working on " + task.ID + " (" + task.Title + ").")
+		newTask, err := w.client.CreateTask(task.ProjectID, t.Title, notes, task.EpicID)
+		if err != nil {
+			w.printf("  Failed to create task %q: %v\n", t.Title, err)
+			errs = append(errs, err)
+			return nil
+		}
+		w.printf("  Created task %s (%s)\n", newTask.ID, newTask.Title)
+		created = append(created, *newTask)
+		// The task exists either way, so it is reported even when its
+		// dependencies could not be set
+		if len(dependsOn) > 0 {
+			if _, err := w.client.UpdateTask(newTask.ID, client.TaskUpdate{DependsOn: &dependsOn}); err != nil {
+				w.printf("  Failed to make task %s depend on %s: %v\n", newTask.ID, strings.Join(dependsOn, ", "), err)
+				errs = append(errs, err)
+			}
+		}
+		return newTask
+	}
+
+	var blockers []string
+	for _, t := range found.Blockers {
+		if newTask := create(t, nil); newTask != nil {
+			blockers = append(blockers, newTask.ID)
+		}
+	}
+	if len(blockers) > 0 {
+		current, err := w.client.GetTask(task.ID)
+		if err == nil {
+			dependsOn := append(slices.Clone(current.DependsOn), blockers...)
+			_, err = w.client.UpdateTask(task.ID, client.TaskUpdate{DependsOn: &dependsOn})
+		}
+		if err != nil {
+			w.printf("  Failed to make task %s depend on its blockers: %v\n", task.ID, err)
+			errs = append(errs, err)
+		}
+		blocked = err == nil
+	}
+	for _, t := range found.FollowUps {
+		create(t, []string{task.ID})
+	}
+
+	if len(errs) > 0 {
+		return created, blocked, fmt.Errorf("failed to create discovered tasks: %w", errors.Join(errs...))
+	}
+	return created, blocked, nil
+}
+
+// MarkBlocked puts a task back in the queued status after its agent
+// reported blockers. The task waits there until the tasks it now depends
+// on are done.
+func (w *Workflow) MarkBlocked(taskIDs []string) error {
+	return w.updateTasksStatus(taskIDs, w.statuses.Queued, "Blocking", ReasonBlocked)
+}
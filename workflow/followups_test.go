@@ -0,0 +1,148 @@
+package workflow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+	"github.com/stephenmfriend/momentum/fluxtest"
+)
+
+func TestParseDiscovered(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		blockers  []string
+		followUps []string
+	}{
+		{name: "no blocks", message: "All done."},
+		{
+			name: "json block",
+			message: "Blocked on the schema.\n\n```json\n" +
+				`{"blockers": [{"title": "Add users table", "notes": "Needs a migration"}], "follow_ups": [{"title": "Drop legacy table"}]}` +
+				"\n```\n",
+			blockers:  []string{"Add users table"},
+			followUps: []string{"Drop legacy table"},
+		},
+		{
+			name: "other code blocks are ignored",
+			message: "```go\n{\"blockers\": [{\"title\": \"no\"}]}\n```\n" +
+				"```json\nnot json\n```\n" +
+				"```json\n{\"follow_ups\": [{\"title\": \"\"}, {\"title\": \"Docs\"}]}\n```",
+			followUps: []string{"Docs"},
+		},
+		{
+			name:     "momentum block",
+			message:  "```momentum\n{\"blockers\": [{\"title\": \"Get API key\"}]}\n```",
+			blockers: []string{"Get API key"},
+		},
+		{name: "unterminated block", message: "```json\n{\"blockers\": [{\"title\": \"x\"}]}"},
+	}
+	titles := func(tasks []NewTask) []string {
+		var out []string
+		for _, t := range tasks {
+			out = append(out, t.Title)
+		}
+		return out
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDiscovered(tt.message)
+			if !slices.Equal(titles(got.Blockers), tt.blockers) || !slices.Equal(titles(got.FollowUps), tt.followUps) {
+				t.Errorf("ParseDiscovered() = %+v, want blockers %v and follow-ups %v", got, tt.blockers, tt.followUps)
+			}
+			if got.Empty() != (len(tt.blockers)+len(tt.followUps) == 0) {
+				t.Errorf("Empty() = %v", got.Empty())
+			}
+		})
+	}
+}
+
+func TestWorkflow_CreateDiscovered(t *testing.T) {
+	flux := fluxtest.New()
+	server := httptest.NewServer(flux)
+	defer server.Close()
+	project := flux.AddProject("Proj", "")
+	epic := flux.AddEpic(project.ID, "Epic", true)
+	dep := flux.AddTask(client.Task{Title: "Earlier", ProjectID: project.ID, EpicID: epic.ID, Status: "done"})
+	task := flux.AddTask(client.Task{Title: "Feature", ProjectID: project.ID, EpicID: epic.ID, DependsOn: []string{dep.ID}})
+
+	wf := NewWorkflow(client.NewClient(server.URL))
+	wf.SetOutput(nil)
+
+	created, blocked, err := wf.CreateDiscovered(&task, Discovered{
+		Blockers:  []NewTask{{Title: "Add users table", Notes: "Needs a migration"}},
+		FollowUps: []NewTask{{Title: "Drop legacy table"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !blocked || len(created) != 2 {
+		t.Fatalf("created %d tasks (blocked %v), want 2 and blocked", len(created), blocked)
+	}
+	blocker, followUp := created[0], created[1]
+
+	for _, c := range created {
+		if c.ProjectID != project.ID || c.EpicID != epic.ID {
+			t.Errorf("task %s created in %s/%s, want %s/%s", c.ID, c.ProjectID, c.EpicID, project.ID, epic.ID)
+		}
+	}
+	if got, _ := flux.Task(blocker.ID); !strings.HasPrefix(got.Notes, "Needs a migration\n\nDiscovered by an agent working on "+task.ID) {
+		t.Errorf("blocker notes = %q", got.Notes)
+	}
+	if got, _ := flux.Task(task.ID); !slices.Equal(got.DependsOn, []string{dep.ID, blocker.ID}) {
+		t.Errorf("task depends on %v, want %v", got.DependsOn, []string{dep.ID, blocker.ID})
+	}
+	if got, _ := flux.Task(followUp.ID); !slices.Equal(got.DependsOn, []string{task.ID}) {
+		t.Errorf("follow-up depends on %v, want %v", got.DependsOn, []string{task.ID})
+	}
+}
+
+func TestWorkflow_CreateDiscoveredFollowUpsOnly(t *testing.T) {
+	flux := fluxtest.New()
+	server := httptest.NewServer(flux)
+	defer server.Close()
+	project := flux.AddProject("Proj", "")
+	task := flux.AddTask(client.Task{Title: "Feature", ProjectID: project.ID})
+
+	wf := NewWorkflow(client.NewClient(server.URL))
+	wf.SetOutput(nil)
+
+	created, blocked, err := wf.CreateDiscovered(&task, Discovered{FollowUps: []NewTask{{Title: "Docs"}}})
+	if err != nil || blocked || len(created) != 1 {
+		t.Errorf("CreateDiscovered() = %d tasks, blocked %v, %v; want 1 task, not blocked", len(created), blocked, err)
+	}
+}
+
+func TestWorkflow_CreateDiscoveredReportsTasksWithoutDependencies(t *testing.T) {
+	flux := fluxtest.New()
+	project := flux.AddProject("Proj", "")
+	task := flux.AddTask(client.Task{Title: "Feature", ProjectID: project.ID})
+
+	// Creating works, but the follow-up's dependencies cannot be set
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		flux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	wf := NewWorkflow(client.NewClient(server.URL))
+	wf.SetOutput(nil)
+
+	created, blocked, err := wf.CreateDiscovered(&task, Discovered{FollowUps: []NewTask{{Title: "Docs"}}})
+	if err == nil {
+		t.Error("expected an error for the missing dependencies")
+	}
+	if blocked || len(created) != 1 || created[0].Title != "Docs" {
+		t.Fatalf("CreateDiscovered() = %+v, blocked %v; want the follow-up reported", created, blocked)
+	}
+	if _, ok := flux.Task(created[0].ID); !ok {
+		t.Errorf("follow-up %s not on the board", created[0].ID)
+	}
+}
@@ -0,0 +1,227 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// ReviewHeading opens the comment momentum posts when it submits a task
+// for review.
+const ReviewHeading = "Ready for review."
+
+// notesMarker separates the outcome momentum keeps in a task's notes from
+// the notes people wrote. It renders as nothing in Markdown.
+const notesMarker = "<!-- momentum:outcome -->"
+
+// Outcome is what momentum knows about an agent run once it has ended.
+type Outcome struct {
+	// Ended says how the run ended, e.g. "succeeded" or "failed (exit 1)"
+	Ended    string
+	Duration time.Duration
+	// Result is the agent's final message
+	Result string
+	// GitStatus and DiffStat are `git status --short` and `git diff --stat`
+	// of the workdir after the run. GitError says why they are missing.
+	GitStatus string
+	DiffStat  string
+	GitError  string
+	// Commits lists the commits made during the run, newest first
+	Commits []string
+	// Shared means other agents ran in the same workdir during the run, so
+	// the changed files and commits may include their work too
+	Shared bool
+	// Created lists the tasks made from the blockers and follow-ups the
+	// agent reported, as "<id> <title>"
+	Created []string
+	// Commit is the commit momentum made of the changes, if any.
+	// CommitError says why committing or pushing failed, and
+	// CommitSkipped why momentum would not commit at all.
+	Commit        *Commit
+	CommitError   string
+	CommitSkipped string
+	// Verification is the result of the review verify command, if any
+	Verification *Verification
+}
+
+// Commit is a commit momentum made of an agent's work.
+type Commit struct {
+	SHA    string
+	Branch string
+	// Remote is where the branch was pushed; "" when it was not
+	Remote string
+}
+
+// String describes the commit, e.g. "abc123… on momentum/t1, pushed to
+// origin".
+func (c Commit) String() string {
+	s := c.SHA
+	if c.Branch != "" {
+		s += " on " + c.Branch
+	}
+	if c.Remote != "" {
+		s += ", pushed to " + c.Remote
+	}
+	return s
+}
+
+// Verification is the result of running a verify command.
+type Verification struct {
+	Command string
+	Output  string
+	// Failure says why the command failed; "" means it passed
+	Failure string
+}
+
+// Comment formats the outcome as a task comment under heading.
+func (o Outcome) Comment(heading string) string {
+	var b strings.Builder
+	b.WriteString(heading)
+	b.WriteString("\n\n")
+
+	b.WriteString("Agent summary:\n")
+	if o.Result == "" {
+		b.WriteString("(the agent did not leave a final message)")
+	} else {
+		b.WriteString(o.Result)
+	}
+	b.WriteString("\n\n")
+
+	if o.Shared {
+		b.WriteString("Changed files in the whole workdir (other agents were running too):\n")
+	} else {
+		b.WriteString("Changed files:\n")
+	}
+	switch {
+	case o.GitError != "":
+		fmt.Fprintf(&b, "(git unavailable: %s)\n", o.GitError)
+	case o.GitStatus == "":
+		b.WriteString("(no uncommitted changes)\n")
+	default:
+		writeBlock(&b, o.GitStatus)
+		if o.DiffStat != "" {
+			b.WriteString("\nDiff:\n")
+			writeBlock(&b, o.DiffStat)
+		}
+	}
+
+	if len(o.Commits) > 0 {
+		if o.Shared {
+			b.WriteString("\nCommits in the workdir during the run:\n")
+		} else {
+			b.WriteString("\nCommits:\n")
+		}
+		for _, commit := range o.Commits {
+			fmt.Fprintf(&b, "- %s\n", commit)
+		}
+	}
+
+	if len(o.Created) > 0 {
+		b.WriteString("\nCreated tasks:\n")
+		for _, task := range o.Created {
+			fmt.Fprintf(&b, "- %s\n", task)
+		}
+	}
+
+	switch {
+	case o.Commit != nil && o.CommitError != "":
+		fmt.Fprintf(&b, "\nCommitted %s; push failed: %s\n", o.Commit, o.CommitError)
+	case o.Commit != nil:
+		fmt.Fprintf(&b, "\nCommitted %s.\n", o.Commit)
+	case o.CommitError != "":
+		fmt.Fprintf(&b, "\nCommit failed: %s\n", o.CommitError)
+	case o.CommitSkipped != "":
+		fmt.Fprintf(&b, "\nNot committed: %s.\n", o.CommitSkipped)
+	}
+
+	if v := o.Verification; v != nil {
+		result := "passed"
+		if v.Failure != "" {
+			result = "failed: " + v.Failure
+		}
+		fmt.Fprintf(&b, "\nVerification (`%s`) %s:\n", v.Command, result)
+		writeBlock(&b, v.Output)
+	}
+	return b.String()
+}
+
+// NotesLine is the one-line summary of the outcome kept in task notes.
+func (o Outcome) NotesLine() string {
+	parts := []string{"Last run " + o.Ended}
+	if o.Duration > 0 {
+		parts[0] += " after " + o.Duration.Round(time.Second).String()
+	}
+	if o.DiffStat != "" {
+		lines := strings.Split(o.DiffStat, "\n")
+		parts = append(parts, strings.TrimSpace(lines[len(lines)-1]))
+	} else if o.GitError == "" {
+		parts = append(parts, "no uncommitted changes")
+	}
+	switch len(o.Commits) {
+	case 0:
+	case 1:
+		parts = append(parts, "1 commit")
+	default:
+		parts = append(parts, fmt.Sprintf("%d commits", len(o.Commits)))
+	}
+	if o.Shared && o.GitError == "" {
+		parts = append(parts, "counts cover the whole workdir, other agents ran too")
+	}
+	if o.Commit != nil {
+		parts = append(parts, "committed "+o.Commit.String())
+	} else if o.CommitSkipped != "" {
+		parts = append(parts, "not committed")
+	}
+	return strings.Join(parts, "; ") + "."
+}
+
+func writeBlock(b *strings.Builder, text string) {
+	b.WriteString("```\n")
+	b.WriteString(text)
+	b.WriteString("\n```\n")
+}
+
+// RecordOutcome posts the outcome of a run as a comment on the task and
+// keeps a one-line summary of it in the task's notes, so Flux has a record
+// of the run even if the agent never reported back itself.
+func (w *Workflow) RecordOutcome(taskID string, outcome Outcome) error {
+	w.printf("Recording outcome of task %s...\n", taskID)
+	_, commentErr := w.client.AddTaskComment(taskID, outcome.Comment("Run "+outcome.Ended+"."), CommentAuthor)
+	if commentErr != nil {
+		w.printf("  Failed to comment on task %s: %v\n", taskID, commentErr)
+	}
+	return errors.Join(commentErr, w.updateOutcomeNotes(taskID, outcome))
+}
+
+// updateOutcomeNotes replaces the outcome summary in the task's notes.
+func (w *Workflow) updateOutcomeNotes(taskID string, outcome Outcome) error {
+	task, err := w.client.GetTask(taskID)
+	if err == nil {
+		notes := withOutcome(task.Notes, outcome.NotesLine())
+		_, err = w.client.UpdateTask(taskID, client.TaskUpdate{Notes: &notes})
+	}
+	if err != nil {
+		w.printf("  Failed to update notes of task %s: %v\n", taskID, err)
+	}
+	return err
+}
+
+// StripOutcome returns notes without the outcome summary momentum keeps
+// in them, leaving what people wrote.
+func StripOutcome(notes string) string {
+	notes, _, _ = strings.Cut(notes, notesMarker)
+	return strings.TrimRight(notes, "\n ")
+}
+
+// withOutcome returns notes with line as its outcome summary, replacing
+// the summary of an earlier run.
+func withOutcome(notes, line string) string {
+	notes = StripOutcome(notes)
+	if notes != "" {
+		notes += "\n\n"
+	}
+	return notes + notesMarker + "\n" + line
+}
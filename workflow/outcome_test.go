@@ -0,0 +1,166 @@
+package workflow
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutcome_Comment(t *testing.T) {
+	outcome := Outcome{
+		Ended:     "succeeded",
+		Result:    "Added the endpoint",
+		GitStatus: " M api.go\n?? api_test.go",
+		DiffStat:  " api.go | 4 ++--\n 1 file changed, 2 insertions(+), 2 deletions(-)",
+		Commits:   []string{"abc1234 Add endpoint"},
+		Created:   []string{"task-9 Document the endpoint"},
+		Verification: &Verification{
+			Command: "go test ./...",
+			Output:  "FAIL api",
+			Failure: "exit status 1",
+		},
+	}
+	got := outcome.Comment("Run succeeded.")
+	for _, want := range []string{
+		"Run succeeded.\n\nAgent summary:\nAdded the endpoint\n\n",
+		"Changed files:\n```\n M api.go\n?? api_test.go\n```\n",
+		"Diff:\n```\n api.go | 4 ++--",
+		"Commits:\n- abc1234 Add endpoint\n",
+		"Created tasks:\n- task-9 Document the endpoint\n",
+		"Verification (`go test ./...`) failed: exit status 1:\n```\nFAIL api\n```\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Comment() missing %q:\n%s", want, got)
+		}
+	}
+
+	got = Outcome{Ended: "succeeded", GitStatus: " M api.go", Shared: true}.Comment("Run succeeded.")
+	if !strings.Contains(got, "Changed files in the whole workdir (other agents were running too):\n") {
+		t.Errorf("Comment() should label shared changes:\n%s", got)
+	}
+
+	got = Outcome{Ended: "failed (exit 1)", GitError: "not a git repository"}.Comment("Run failed (exit 1).")
+	for _, want := range []string{"(the agent did not leave a final message)", "(git unavailable: not a git repository)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Comment() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestOutcome_NotesLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome Outcome
+		want    string
+	}{
+		{
+			name:    "clean",
+			outcome: Outcome{Ended: "succeeded"},
+			want:    "Last run succeeded; no uncommitted changes.",
+		},
+		{
+			name: "changes and commits",
+			outcome: Outcome{
+				Ended:    "failed (exit 1)",
+				Duration: 90*time.Second + 200*time.Millisecond,
+				DiffStat: " a.go | 1 +\n 1 file changed, 1 insertion(+)",
+				Commits:  []string{"a", "b"},
+			},
+			want: "Last run failed (exit 1) after 1m30s; 1 file changed, 1 insertion(+); 2 commits.",
+		},
+		{
+			name: "committed by momentum",
+			outcome: Outcome{
+				Ended:  "succeeded",
+				Commit: &Commit{SHA: "abc123", Branch: "momentum/t1", Remote: "origin"},
+			},
+			want: "Last run succeeded; no uncommitted changes; committed abc123 on momentum/t1, pushed to origin.",
+		},
+		{
+			name: "shared workdir",
+			outcome: Outcome{
+				Ended:    "succeeded",
+				DiffStat: " a.go | 1 +\n 1 file changed, 1 insertion(+)",
+				Shared:   true,
+			},
+			want: "Last run succeeded; 1 file changed, 1 insertion(+); counts cover the whole workdir, other agents ran too.",
+		},
+		{
+			name:    "not committed",
+			outcome: Outcome{Ended: "succeeded", CommitSkipped: "other agents were running in the workdir"},
+			want:    "Last run succeeded; no uncommitted changes; not committed.",
+		},
+		{
+			name:    "no git",
+			outcome: Outcome{Ended: "timed out", GitError: "not a git repository"},
+			want:    "Last run timed out.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.outcome.NotesLine(); got != tt.want {
+				t.Errorf("NotesLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithOutcome(t *testing.T) {
+	tests := []struct {
+		notes string
+		want  string
+	}{
+		{"", notesMarker + "\nLast run."},
+		{"Do the thing.\n", "Do the thing.\n\n" + notesMarker + "\nLast run."},
+		{"Do the thing.\n\n" + notesMarker + "\nLast run failed.", "Do the thing.\n\n" + notesMarker + "\nLast run."},
+	}
+	for _, tt := range tests {
+		if got := withOutcome(tt.notes, "Last run."); got != tt.want {
+			t.Errorf("withOutcome(%q) = %q, want %q", tt.notes, got, tt.want)
+		}
+	}
+}
+
+func TestStripOutcome(t *testing.T) {
+	if got := StripOutcome("Do the thing.\n\n" + notesMarker + "\nLast run."); got != "Do the thing." {
+		t.Errorf("StripOutcome() = %q, want the notes alone", got)
+	}
+	if got := StripOutcome(notesMarker + "\nLast run."); got != "" {
+		t.Errorf("StripOutcome() = %q, want empty notes", got)
+	}
+}
+
+func TestWorkflow_RecordOutcome(t *testing.T) {
+	var comment, notes string
+	server, c := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/comments"):
+			comment = body["body"]
+			json.NewEncoder(w).Encode(map[string]any{"id": "comment-1"})
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "notes": "Fix the bug."})
+		default:
+			notes = body["notes"]
+			json.NewEncoder(w).Encode(map[string]any{"id": "task-1"})
+		}
+	})
+	defer server.Close()
+
+	wf := NewWorkflow(c)
+	wf.SetOutput(nil)
+
+	if err := wf.RecordOutcome("task-1", Outcome{Ended: "failed (exit 2)", Result: "Tests broke"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(comment, "Run failed (exit 2).\n\nAgent summary:\nTests broke") {
+		t.Errorf("comment = %q", comment)
+	}
+	if want := "Fix the bug.\n\n" + notesMarker + "\nLast run failed (exit 2); no uncommitted changes."; notes != want {
+		t.Errorf("notes = %q, want %q", notes, want)
+	}
+}
@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+// CommentAuthor is the author momentum posts its task comments as.
+const CommentAuthor = "momentum"
+
+// SubmitForReview posts the outcome of a run as a review summary on the
+// task, then moves it to the succeeded status (the review status in review
+// mode) for a human to approve. The task is left where it is if the
+// summary cannot be posted; a failure to update its notes is only
+// reported.
+func (w *Workflow) SubmitForReview(taskID string, outcome Outcome) error {
+	w.printf("Submitting task %s for review...\n", taskID)
+	if _, err := w.client.AddTaskComment(taskID, outcome.Comment(ReviewHeading), CommentAuthor); err != nil {
+		w.printf("  Failed to comment on task %s: %v\n", taskID, err)
+		return fmt.Errorf("failed to submit task %s for review: %w", taskID, err)
+	}
+	w.updateOutcomeNotes(taskID, outcome)
+	return w.MarkComplete([]string{taskID})
+}
+
+// ReviewFeedback returns the comments people left on a task since momentum
+// last submitted it for review, oldest first. A task sent back from review
+// carries the reviewer's reasons here. Comments from agents (author "mcp")
+// and from momentum itself, such as the outcomes of later failed runs, are
+// skipped.
+func ReviewFeedback(task client.Task) []client.Comment {
+	last := -1
+	for i, comment := range task.Comments {
+		if comment.Author == CommentAuthor && strings.HasPrefix(comment.Body, ReviewHeading) {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil
+	}
+
+	var feedback []client.Comment
+	for _, comment := range task.Comments[last+1:] {
+		if comment.Author != "mcp" && comment.Author != CommentAuthor {
+			feedback = append(feedback, comment)
+		}
+	}
+	return feedback
+}
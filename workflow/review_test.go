@@ -0,0 +1,125 @@
+package workflow
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stephenmfriend/momentum/client"
+)
+
+func TestWorkflow_SubmitForReview(t *testing.T) {
+	var requests []string
+	server, c := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/comments"):
+			if body["author"] != CommentAuthor || !strings.HasPrefix(body["body"], ReviewHeading) || !strings.Contains(body["body"], "All green") {
+				t.Errorf("unexpected comment %v", body)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "comment-1"})
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "status": "in_progress"})
+		case body["notes"] != "":
+			if !strings.HasSuffix(body["notes"], "Last run succeeded; no uncommitted changes.") {
+				t.Errorf("unexpected notes %q", body["notes"])
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "task-1"})
+		default:
+			if body["status"] != "review" {
+				t.Errorf("expected status review, got %q", body["status"])
+			}
+			json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "status": body["status"]})
+		}
+	})
+	defer server.Close()
+
+	wf := NewWorkflow(c)
+	wf.SetOutput(nil)
+	wf.SetStatuses(Statuses{Succeeded: "review"})
+
+	if err := wf.SubmitForReview("task-1", Outcome{Ended: "succeeded", Result: "All green"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "POST /api/tasks/task-1/comments, GET /api/tasks/task-1, PATCH /api/tasks/task-1, PATCH /api/tasks/task-1"
+	if got := strings.Join(requests, ", "); got != want {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+}
+
+func TestWorkflow_SubmitForReviewCommentFails(t *testing.T) {
+	var patched bool
+	server, c := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			patched = true
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	wf := NewWorkflow(c)
+	wf.SetOutput(nil)
+
+	if err := wf.SubmitForReview("task-1", Outcome{Ended: "succeeded"}); err == nil {
+		t.Error("expected error")
+	}
+	if patched {
+		t.Error("task was moved although the summary was not posted")
+	}
+}
+
+func TestReviewFeedback(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []client.Comment
+		want     []string
+	}{
+		{
+			name:     "never reviewed",
+			comments: []client.Comment{{Body: "note", Author: "user"}},
+		},
+		{
+			name: "reviewer comments after the summary",
+			comments: []client.Comment{
+				{Body: "old feedback", Author: "user"},
+				{Body: ReviewHeading + " summary", Author: CommentAuthor},
+				{Body: "agent note", Author: "mcp"},
+				{Body: "Missing tests", Author: "user"},
+				{Body: "Also the docs", Author: "user"},
+			},
+			want: []string{"Missing tests", "Also the docs"},
+		},
+		{
+			name: "only the latest round counts",
+			comments: []client.Comment{
+				{Body: ReviewHeading + " 1", Author: CommentAuthor},
+				{Body: "Missing tests", Author: "user"},
+				{Body: ReviewHeading + " 2", Author: CommentAuthor},
+			},
+		},
+		{
+			name: "failed runs since do not reset the round",
+			comments: []client.Comment{
+				{Body: ReviewHeading, Author: CommentAuthor},
+				{Body: "Missing tests", Author: "user"},
+				{Body: "Run failed (exit 1).", Author: CommentAuthor},
+			},
+			want: []string{"Missing tests"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, comment := range ReviewFeedback(client.Task{Comments: tt.comments}) {
+				got = append(got, comment.Body)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("ReviewFeedback() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Statuses maps the outcomes momentum reports to board statuses.
+type Statuses struct {
+	// Queued is where tasks wait to be picked up
+	Queued string
+	// Planning is where tasks wait for a human
+	Planning string
+	// Done is where finished tasks end up, after any review
+	Done string
+	// PickedUp is set when an agent starts on a task
+	PickedUp string
+	// Succeeded is set when the agent finishes successfully
+	Succeeded string
+	// Failed is set when the agent exits with an error. Empty leaves the
+	// task in its picked-up status for investigation.
+	Failed string
+	// Stopped is set when a user stops the agent
+	Stopped string
+	// TimedOut is set when the agent runs past its timeout
+	TimedOut string
+}
+
+// DefaultStatuses returns the statuses used by a stock Flux board.
+func DefaultStatuses() Statuses {
+	return Statuses{
+		Queued:    "todo",
+		Planning:  "planning",
+		Done:      "done",
+		PickedUp:  "in_progress",
+		Succeeded: "done",
+		Stopped:   "planning",
+		TimedOut:  "planning",
+	}
+}
+
+// WithDefaults fills empty fields from DefaultStatuses.
+func (s Statuses) WithDefaults() Statuses {
+	d := DefaultStatuses()
+	if s.Queued == "" {
+		s.Queued = d.Queued
+	}
+	if s.Planning == "" {
+		s.Planning = d.Planning
+	}
+	if s.Done == "" {
+		s.Done = d.Done
+	}
+	if s.PickedUp == "" {
+		s.PickedUp = d.PickedUp
+	}
+	if s.Succeeded == "" {
+		s.Succeeded = d.Succeeded
+	}
+	if s.Stopped == "" {
+		s.Stopped = d.Stopped
+	}
+	if s.TimedOut == "" {
+		s.TimedOut = d.TimedOut
+	}
+	return s
+}
+
+// ErrIllegalTransition is returned when a status change is not allowed by
+// the transition table.
+var ErrIllegalTransition = errors.New("illegal status transition")
+
+// Transitions maps a status to the statuses a task may move to from it.
+// Staying in the same status is always allowed. Statuses missing from the
+// table cannot be left.
+type Transitions map[string][]string
+
+// Check returns an error wrapping ErrIllegalTransition when from → to is
+// not in the table.
+func (t Transitions) Check(from, to string) error {
+	if t == nil || from == to || slices.Contains(t[from], to) {
+		return nil
+	}
+	allowed, ok := t[from]
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: %s → %s (no transitions out of %q are configured)", ErrIllegalTransition, from, to, from)
+	case len(allowed) == 0:
+		return fmt.Errorf("%w: %s → %s (%q is final)", ErrIllegalTransition, from, to, from)
+	}
+	return fmt.Errorf("%w: %s → %s (allowed from %s: %s)", ErrIllegalTransition, from, to, from, strings.Join(allowed, ", "))
+}
+
+// Validate checks that the table allows every move momentum makes with
+// the given statuses: from queued to picked up, from picked up to each
+// outcome, and from picked up back to queued or planning when a task is
+// blocked, reset or recovered after a crash.
+func (t Transitions) Validate(s Statuses) error {
+	if t == nil {
+		return nil
+	}
+	s = s.WithDefaults()
+	moves := []struct{ from, to, when string }{
+		{s.Queued, s.PickedUp, "an agent picks up a task"},
+		{s.PickedUp, s.Succeeded, "a task succeeds"},
+		{s.PickedUp, s.Failed, "a task fails"},
+		{s.PickedUp, s.Stopped, "an agent is stopped"},
+		{s.PickedUp, s.TimedOut, "an agent times out"},
+		{s.PickedUp, s.Queued, "an agent reports blockers, a task is reset or an orphaned task is recovered"},
+		{s.PickedUp, s.Planning, "a task is reset to planning or an orphaned task is recovered"},
+	}
+	for _, move := range moves {
+		if move.to == "" {
+			continue
+		}
+		if err := t.Check(move.from, move.to); err != nil {
+			return fmt.Errorf("transitions must allow %s → %s, used when %s", move.from, move.to, move.when)
+		}
+	}
+	return nil
+}
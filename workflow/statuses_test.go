@@ -0,0 +1,142 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTransitions_Check(t *testing.T) {
+	table := Transitions{
+		"todo":        {"in_progress"},
+		"in_progress": {"review", "blocked"},
+		"done":        {},
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		wantErr  string
+	}{
+		{name: "allowed", from: "todo", to: "in_progress"},
+		{name: "same status", from: "review", to: "review"},
+		{name: "not listed", from: "in_progress", to: "done", wantErr: "in_progress → done (allowed from in_progress: review, blocked)"},
+		{name: "final", from: "done", to: "todo", wantErr: `"done" is final`},
+		{name: "unknown from", from: "review", to: "done", wantErr: `no transitions out of "review"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := table.Check(tt.from, tt.to)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrIllegalTransition) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := Transitions(nil).Check("done", "todo"); err != nil {
+		t.Errorf("nil table should allow everything, got %v", err)
+	}
+}
+
+func TestTransitions_Validate(t *testing.T) {
+	table := Transitions{
+		"todo":        {"in_progress"},
+		"in_progress": {"review", "planning", "todo"},
+	}
+
+	if err := table.Validate(Statuses{Succeeded: "review"}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	err := table.Validate(Statuses{})
+	if err == nil || !strings.Contains(err.Error(), "in_progress → done, used when a task succeeds") {
+		t.Errorf("Validate() with default succeeded status error = %v", err)
+	}
+
+	err = table.Validate(Statuses{Succeeded: "review", Failed: "blocked"})
+	if err == nil || !strings.Contains(err.Error(), "a task fails") {
+		t.Errorf("Validate() with failed status error = %v", err)
+	}
+
+	// Blocked, reset and recovered tasks go back to todo
+	table["in_progress"] = []string{"review", "planning"}
+	err = table.Validate(Statuses{Succeeded: "review"})
+	if err == nil || !strings.Contains(err.Error(), "in_progress → todo, used when an agent reports blockers") {
+		t.Errorf("Validate() without in_progress → todo error = %v", err)
+	}
+}
+
+func TestWorkflow_RefusesIllegalTransition(t *testing.T) {
+	var patched []string
+	server, c := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "status": "review"})
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		patched = append(patched, body["status"])
+		json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "status": body["status"]})
+	})
+	defer server.Close()
+
+	wf := NewWorkflow(c)
+	wf.SetOutput(nil)
+	wf.SetTransitions(Transitions{"review": {"done", "todo"}})
+
+	err := wf.MarkStopped([]string{"task-1"})
+	if !errors.Is(err, ErrIllegalTransition) && (err == nil || !strings.Contains(err.Error(), "review → planning")) {
+		t.Errorf("MarkStopped() error = %v, want illegal review → planning", err)
+	}
+	if len(patched) != 0 {
+		t.Errorf("refused transition still sent PATCH %v", patched)
+	}
+
+	if err := wf.MarkComplete([]string{"task-1"}); err != nil {
+		t.Errorf("MarkComplete() error = %v", err)
+	}
+	if len(patched) != 1 || patched[0] != "done" {
+		t.Errorf("patched = %v, want [done]", patched)
+	}
+}
+
+func TestWorkflow_ConfiguredStatuses(t *testing.T) {
+	var patched []string
+	server, c := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		patched = append(patched, body["status"])
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"id": "task-1", "status": body["status"]})
+	})
+	defer server.Close()
+
+	wf := NewWorkflow(c)
+	wf.SetOutput(nil)
+
+	// No failed status by default: the task is left alone
+	if err := wf.MarkFailed([]string{"task-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	wf.SetStatuses(Statuses{Queued: "backlog", Planning: "triage", Succeeded: "review", Failed: "blocked", TimedOut: "todo"})
+	for _, mark := range []func([]string) error{wf.StartWorking, wf.MarkComplete, wf.MarkFailed, wf.MarkStopped, wf.MarkTimedOut, wf.ResetTask, wf.ResetToPlanning} {
+		if err := mark([]string{"task-1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "in_progress review blocked planning todo backlog triage"
+	if got := strings.Join(patched, " "); got != want {
+		t.Errorf("statuses = %q, want %q", got, want)
+	}
+}
@@ -8,70 +8,165 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/user"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/stephenmfriend/momentum/client"
 )
 
 // Workflow provides methods for managing task status transitions.
 type Workflow struct {
-	client    *client.Client
-	out       io.Writer
-	agentName string
+	client      *client.Client
+	out         io.Writer
+	agentName   string
+	statuses    Statuses
+	transitions Transitions
+	claimSettle time.Duration
+	audit       *AuditLog
+	actor       string
+
+	// known holds the current status of tasks whose status a caller has
+	// already seen, by ID. Each is used once, by the next status change.
+	knownMu sync.Mutex
+	known   map[string]string
 }
 
 // NewWorkflow creates a new Workflow instance with the provided client.
 func NewWorkflow(client *client.Client) *Workflow {
 	return &Workflow{
-		client:    client,
-		out:       os.Stdout,
-		agentName: "claude",
+		client:      client,
+		out:         os.Stdout,
+		agentName:   "claude",
+		statuses:    DefaultStatuses(),
+		claimSettle: defaultClaimSettle,
+		actor:       currentUser(),
+		known:       make(map[string]string),
 	}
 }
 
+// SetStatuses configures which board statuses the workflow moves tasks to.
+// Empty fields keep their defaults.
+func (w *Workflow) SetStatuses(statuses Statuses) {
+	w.statuses = statuses.WithDefaults()
+}
+
+// Statuses returns the board statuses the workflow moves tasks to.
+func (w *Workflow) Statuses() Statuses {
+	return w.statuses
+}
+
+// SetTransitions restricts status changes to the given table. With a nil
+// table every transition is allowed.
+func (w *Workflow) SetTransitions(transitions Transitions) {
+	w.transitions = transitions
+}
+
+// SetAuditLog records every status change the workflow makes in log. Nil
+// disables recording.
+func (w *Workflow) SetAuditLog(log *AuditLog) {
+	w.audit = log
+}
+
+// Known tells the workflow a task's current status, such as one seen in an
+// event, so the next status change for the task can check and record the
+// move without looking the task up. Claim does this for the tasks it
+// claims. An empty status forgets the task's status.
+func (w *Workflow) Known(taskID, status string) {
+	w.knownMu.Lock()
+	defer w.knownMu.Unlock()
+	if status == "" {
+		delete(w.known, taskID)
+		return
+	}
+	w.known[taskID] = status
+}
+
+// takeKnown returns and forgets the known status of a task, or "".
+func (w *Workflow) takeKnown(taskID string) string {
+	w.knownMu.Lock()
+	defer w.knownMu.Unlock()
+	status := w.known[taskID]
+	delete(w.known, taskID)
+	return status
+}
+
 // SetOutput configures where workflow status messages are written.
 // Use io.Discard to silence output (e.g., when a TUI is active).
 func (w *Workflow) SetOutput(out io.Writer) {
 	w.out = out
 }
 
-// StartWorking transitions the specified tasks to "in_progress" status.
+// StartWorking transitions the specified tasks to the picked-up status
+// ("in_progress" by default).
 // It iterates through all provided task IDs, attempting to update each one.
 // If any task fails to update, it continues with the remaining tasks and
 // returns an aggregate error describing all failures.
 func (w *Workflow) StartWorking(taskIDs []string) error {
-	return w.updateTasksStatus(taskIDs, "in_progress", "Starting work on")
+	return w.updateTasksStatus(taskIDs, w.statuses.PickedUp, "Starting work on", ReasonStarted)
 }
 
-// MarkComplete transitions the specified tasks to "done" status.
+// MarkComplete transitions the specified tasks to the succeeded status
+// ("done" by default).
 // It iterates through all provided task IDs, attempting to update each one.
 // If any task fails to update, it continues with the remaining tasks and
 // returns an aggregate error describing all failures.
 func (w *Workflow) MarkComplete(taskIDs []string) error {
-	return w.updateTasksStatus(taskIDs, "done", "Marking complete")
+	return w.updateTasksStatus(taskIDs, w.statuses.Succeeded, "Marking complete", ReasonSucceeded)
+}
+
+// MarkFailed transitions the specified tasks to the failed status. By
+// default no failed status is set and tasks are left where they are for
+// investigation.
+func (w *Workflow) MarkFailed(taskIDs []string) error {
+	if w.statuses.Failed == "" {
+		return nil
+	}
+	return w.updateTasksStatus(taskIDs, w.statuses.Failed, "Marking failed", ReasonFailed)
+}
+
+// MarkStopped transitions the specified tasks to the stopped status
+// ("planning" by default). It is used when a user stops an agent.
+func (w *Workflow) MarkStopped(taskIDs []string) error {
+	return w.updateTasksStatus(taskIDs, w.statuses.Stopped, "Stopping", ReasonStopped)
+}
+
+// MarkTimedOut transitions the specified tasks to the timed-out status
+// ("planning" by default). It is used when an agent hits its timeout.
+func (w *Workflow) MarkTimedOut(taskIDs []string) error {
+	return w.updateTasksStatus(taskIDs, w.statuses.TimedOut, "Timing out", ReasonTimedOut)
 }
 
-// ResetTask transitions the specified tasks back to "todo" status.
+// ResetTask transitions the specified tasks back to the queued status
+// ("todo" by default).
 // It iterates through all provided task IDs, attempting to update each one.
 // If any task fails to update, it continues with the remaining tasks and
 // returns an aggregate error describing all failures.
 func (w *Workflow) ResetTask(taskIDs []string) error {
-	return w.updateTasksStatus(taskIDs, "todo", "Resetting")
+	return w.updateTasksStatus(taskIDs, w.statuses.Queued, "Resetting", ReasonReset)
 }
 
-// ResetToPlanning transitions the specified tasks back to "planning" status.
+// ResetToPlanning transitions the specified tasks back to the planning
+// status ("planning" by default).
 // This is typically used when a user stops an agent mid-execution.
 // It iterates through all provided task IDs, attempting to update each one.
 // If any task fails to update, it continues with the remaining tasks and
 // returns an aggregate error describing all failures.
 func (w *Workflow) ResetToPlanning(taskIDs []string) error {
-	return w.updateTasksStatus(taskIDs, "planning", "Resetting to planning")
+	return w.updateTasksStatus(taskIDs, w.statuses.Planning, "Resetting to planning", ReasonReset)
+}
+
+// RecoverOrphan moves a task left behind by a momentum process that died
+// to status. It is used by crash recovery on startup.
+func (w *Workflow) RecoverOrphan(taskID, status string) error {
+	return w.updateTasksStatus([]string{taskID}, status, "Recovering", ReasonReconcile)
 }
 
 // updateTasksStatus is the internal method that handles status updates for all tasks.
 // It processes each task ID, prints status messages, handles errors gracefully,
 // and returns an aggregate error if any updates failed.
-func (w *Workflow) updateTasksStatus(taskIDs []string, status, actionVerb string) error {
+func (w *Workflow) updateTasksStatus(taskIDs []string, status, actionVerb string, reason Reason) error {
 	if len(taskIDs) == 0 {
 		return nil
 	}
@@ -82,6 +177,14 @@ func (w *Workflow) updateTasksStatus(taskIDs []string, status, actionVerb string
 	for _, taskID := range taskIDs {
 		w.printf("%s task %s...\n", actionVerb, taskID)
 
+		from, err := w.checkTransition(taskID, w.takeKnown(taskID), status)
+		if err != nil {
+			w.printf("  Refusing to update task %s: %v\n", taskID, err)
+			failedTasks = append(failedTasks, taskID)
+			errorMessages = append(errorMessages, fmt.Sprintf("task %s: %v", taskID, err))
+			continue
+		}
+
 		task, err := w.client.MoveTaskStatus(taskID, status, w.agentName)
 		if err != nil {
 			w.printf("  Failed to update task %s: %v\n", taskID, err)
@@ -91,6 +194,7 @@ func (w *Workflow) updateTasksStatus(taskIDs []string, status, actionVerb string
 		}
 
 		w.printf("  Task %s (%s) -> %s\n", taskID, task.Title, status)
+		w.record(taskID, from, status, reason)
 	}
 
 	if len(failedTasks) > 0 {
@@ -100,6 +204,53 @@ func (w *Workflow) updateTasksStatus(taskIDs []string, status, actionVerb string
 	return nil
 }
 
+// checkTransition checks the move of a task from its current status to
+// status against the transition table and returns the current status. from
+// is the current status when the caller knows it; otherwise the task is
+// looked up, but only when there is a table to check or an audit log to
+// record the previous status in, and "" is returned when there is neither.
+func (w *Workflow) checkTransition(taskID, from, status string) (string, error) {
+	if from == "" {
+		if w.transitions == nil && w.audit == nil {
+			return "", nil
+		}
+		task, err := w.client.GetTask(taskID)
+		if err != nil {
+			return "", err
+		}
+		from = task.Status
+	}
+	return from, w.transitions.Check(from, status)
+}
+
+// record appends a transition to the audit log, if one is set. A failure
+// to record does not undo the status change, so it is only reported.
+func (w *Workflow) record(taskID, from, to string, reason Reason) {
+	if w.audit == nil {
+		return
+	}
+	err := w.audit.Append(Transition{
+		Time:     time.Now().UTC(),
+		TaskID:   taskID,
+		From:     from,
+		To:       to,
+		Reason:   reason,
+		Actor:    w.actor,
+		Instance: w.agentName,
+	})
+	if err != nil {
+		w.printf("  Failed to record transition of task %s: %v\n", taskID, err)
+	}
+}
+
+// currentUser returns the name of the user momentum runs as, or "".
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
 func (w *Workflow) printf(format string, args ...any) {
 	if w.out == nil {
 		return